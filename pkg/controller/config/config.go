@@ -213,11 +213,14 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 		configLog.Info("watching for Gateway API resources - --watch-gateway is true")
 	}
 
-	var hasGatewayV1, hasGatewayB1, hasGatewayA2, hasTCPRouteA2, hasTLSRouteA2 bool
+	var hasGatewayV1, hasGatewayB1, hasGatewayA2, hasGRPCRouteA2, hasTCPRouteA2, hasTLSRouteA2, hasReferenceGrantB1, hasBackendTLSPolicyA2 bool
 	if opt.WatchGateway {
 		gwapis := []string{"gatewayclass", "gateway", "httproute"}
+		grpcapis := []string{"grpcroute"}
 		tcpapis := []string{"tcproute"}
 		tlsapis := []string{"tlsroute"}
+		refgrantapis := []string{"referencegrant"}
+		backtlsapis := []string{"backendtlspolicy"}
 
 		gwV1 := configHasAPI(clientGateway.Discovery(), gatewayv1.GroupVersion, gwapis...)
 		if gwV1 {
@@ -239,6 +242,11 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 		hasGatewayB1 = gwB1 && !hasGatewayV1
 		hasGatewayA2 = gwA2 && !hasGatewayB1
 
+		grpcA2 := configHasAPI(clientGateway.Discovery(), gatewayv1alpha2.GroupVersion, grpcapis...)
+		if grpcA2 {
+			configLog.Info("found custom resource definition for GRPCRoute API v1alpha2")
+		}
+
 		tcpA2 := configHasAPI(clientGateway.Discovery(), gatewayv1alpha2.GroupVersion, tcpapis...)
 		if tcpA2 {
 			configLog.Info("found custom resource definition for TCPRoute API v1alpha2")
@@ -249,11 +257,24 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 			configLog.Info("found custom resource definition for TLSRoute API v1alpha2")
 		}
 
-		// TODO: cannot enable TCPRoute or TLSRoute without Gateway and GatewayClass, but currently
+		refgrantB1 := configHasAPI(clientGateway.Discovery(), gatewayv1beta1.GroupVersion, refgrantapis...)
+		if refgrantB1 {
+			configLog.Info("found custom resource definition for ReferenceGrant API v1beta1")
+		}
+
+		backtlsA2 := configHasAPI(clientGateway.Discovery(), gatewayv1alpha2.GroupVersion, backtlsapis...)
+		if backtlsA2 {
+			configLog.Info("found custom resource definition for BackendTLSPolicy API v1alpha2")
+		}
+
+		// TODO: cannot enable GRPCRoute, TCPRoute or TLSRoute without Gateway and GatewayClass, but currently
 		// HTTPRoute discovery is coupled and its CRD should be installed as well, even if not used.
 		// We should use a distinct flag for HTTPRoute.
+		hasGRPCRouteA2 = grpcA2 && gw
 		hasTCPRouteA2 = tcpA2 && gw
 		hasTLSRouteA2 = tlsA2 && gw
+		hasReferenceGrantB1 = refgrantB1 && gw
+		hasBackendTLSPolicyA2 = backtlsA2 && gw
 	}
 
 	if opt.EnableEndpointSlicesAPI {
@@ -351,6 +372,18 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 		configLog.Info("watching for global config options - --configmap was defined", "configmap", opt.ConfigMap)
 	}
 
+	if opt.AnnPolicyConfigMap != "" {
+		ns, name, err := cache.SplitMetaNamespaceKey(opt.AnnPolicyConfigMap)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format for annotations policy ConfigMap '%s': %w", opt.AnnPolicyConfigMap, err)
+		}
+		_, err = client.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error reading annotations policy ConfigMap '%s': %w", opt.AnnPolicyConfigMap, err)
+		}
+		configLog.Info("watching for annotations policy - --annotations-policy-configmap was defined", "configmap", opt.AnnPolicyConfigMap)
+	}
+
 	if opt.DefaultSvc != "" {
 		ns, name, err := cache.SplitMetaNamespaceKey(opt.DefaultSvc)
 		if err != nil {
@@ -451,6 +484,7 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 	defaultDirCACerts := "/var/lib/haproxy/cacerts"
 	defaultDirCrl := "/var/lib/haproxy/crl"
 	defaultDirDHParam := "/var/lib/haproxy/dhparam"
+	defaultDirJWTKeys := "/var/lib/haproxy/jwtkeys"
 	defaultDirVarRun := "/var/run/haproxy"
 	defaultDirMaps := "/etc/haproxy/maps"
 	defaultDirErrorfiles := "/etc/haproxy/errorfiles"
@@ -461,6 +495,7 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 		&defaultDirCACerts,
 		&defaultDirCrl,
 		&defaultDirDHParam,
+		&defaultDirJWTKeys,
 		&defaultDirVarRun,
 		&defaultDirMaps,
 		&defaultDirErrorfiles,
@@ -489,6 +524,7 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 		AcmeTokenConfigMapName:   acmeTokenConfigMapNamespaceName,
 		AcmeTrackTLSAnn:          opt.AcmeTrackTLSAnn,
 		AllowCrossNamespace:      opt.AllowCrossNamespace,
+		AnnPolicyConfigMapName:   opt.AnnPolicyConfigMap,
 		AnnPrefix:                annPrefixList,
 		BackendShards:            opt.BackendShards,
 		BucketsResponseTime:      opt.BucketsResponseTime,
@@ -500,6 +536,7 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 		DefaultDirCerts:          defaultDirCerts,
 		DefaultDirCrl:            defaultDirCrl,
 		DefaultDirDHParam:        defaultDirDHParam,
+		DefaultDirJWTKeys:        defaultDirJWTKeys,
 		DefaultDirMaps:           defaultDirMaps,
 		DefaultDirVarRun:         defaultDirVarRun,
 		DefaultService:           opt.DefaultSvc,
@@ -514,8 +551,11 @@ func CreateWithConfig(ctx context.Context, restConfig *rest.Config, opt *Options
 		HasGatewayA2:             hasGatewayA2,
 		HasGatewayB1:             hasGatewayB1,
 		HasGatewayV1:             hasGatewayV1,
+		HasGRPCRouteA2:           hasGRPCRouteA2,
 		HasTCPRouteA2:            hasTCPRouteA2,
 		HasTLSRouteA2:            hasTLSRouteA2,
+		HasReferenceGrantB1:      hasReferenceGrantB1,
+		HasBackendTLSPolicyA2:    hasBackendTLSPolicyA2,
 		HealthzAddr:              healthz,
 		HealthzURL:               opt.HealthzURL,
 		IngressClass:             opt.IngressClass,
@@ -746,6 +786,7 @@ type Config struct {
 	AcmeTokenConfigMapName   string
 	AcmeTrackTLSAnn          bool
 	AllowCrossNamespace      bool
+	AnnPolicyConfigMapName   string
 	AnnPrefix                []string
 	BackendShards            int
 	BucketsResponseTime      []float64
@@ -757,6 +798,7 @@ type Config struct {
 	DefaultDirCACerts        string
 	DefaultDirCrl            string
 	DefaultDirDHParam        string
+	DefaultDirJWTKeys        string
 	DefaultDirMaps           string
 	DefaultDirVarRun         string
 	DefaultService           string
@@ -771,8 +813,11 @@ type Config struct {
 	HasGatewayA2             bool
 	HasGatewayB1             bool
 	HasGatewayV1             bool
+	HasGRPCRouteA2           bool
 	HasTCPRouteA2            bool
 	HasTLSRouteA2            bool
+	HasReferenceGrantB1      bool
+	HasBackendTLSPolicyA2    bool
 	HealthzAddr              string
 	HealthzURL               string
 	IngressClass             string
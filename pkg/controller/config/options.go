@@ -73,6 +73,7 @@ type Options struct {
 	PublishAddress           string
 	TCPConfigMapName         string
 	AnnPrefix                string
+	AnnPolicyConfigMap       string
 	RateLimitUpdate          float64
 	ReloadInterval           time.Duration
 	ReloadRetry              time.Duration
@@ -287,6 +288,12 @@ func (o *Options) AddFlags(fs *flag.FlagSet) {
 		"Defines a comma-separated list of annotation prefix for ingress and service",
 	)
 
+	fs.StringVar(&o.AnnPolicyConfigMap, "annotations-policy-configmap", o.AnnPolicyConfigMap, ""+
+		"Name of the ConfigMap, in the format namespace/name, that contains the policy of "+
+		"the configuration keys that ingress and service resources of each namespace are "+
+		"allowed to use. All configuration keys are allowed if not declared.",
+	)
+
 	fs.Float64Var(&o.RateLimitUpdate, "rate-limit-update", o.RateLimitUpdate, ""+
 		"Maximum of updates per second this controller should perform. Default is 0.5, "+
 		"which means wait 2 seconds between Ingress updates in order to add more changes "+
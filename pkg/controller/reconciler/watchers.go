@@ -74,9 +74,18 @@ func (w *watchers) getHandlers() []*hdlr {
 	if w.cfg.HasGatewayV1 {
 		handlers = append(handlers, w.handlersGatewayv1()...)
 	}
+	if w.cfg.HasGRPCRouteA2 {
+		handlers = append(handlers, w.handlersGRPCRoutev1alpha2()...)
+	}
 	if w.cfg.HasTCPRouteA2 {
 		handlers = append(handlers, w.handlersTCPRoutev1alpha2()...)
 	}
+	if w.cfg.HasReferenceGrantB1 {
+		handlers = append(handlers, w.handlersReferenceGrantv1beta1()...)
+	}
+	if w.cfg.HasBackendTLSPolicyA2 {
+		handlers = append(handlers, w.handlersBackendTLSPolicyv1alpha2()...)
+	}
 	for _, h := range handlers {
 		h.w = w
 	}
@@ -105,6 +114,11 @@ func (w *watchers) initCh() {
 		} else {
 			newch.TCPConfigMapDataCur = w.ch.TCPConfigMapDataCur
 		}
+		if w.ch.AnnPolicyConfigMapDataNew != nil {
+			newch.AnnPolicyConfigMapDataCur = w.ch.AnnPolicyConfigMapDataNew
+		} else {
+			newch.AnnPolicyConfigMapDataCur = w.ch.AnnPolicyConfigMapDataCur
+		}
 	}
 	w.ch = newch
 	w.ch.Links = types.TrackingLinks{}
@@ -125,6 +139,8 @@ func (w *watchers) handlersCore() []*hdlr {
 			w.ch.GlobalConfigMapDataNew = cm.Data
 		case w.cfg.TCPConfigMapName:
 			w.ch.TCPConfigMapDataNew = cm.Data
+		case w.cfg.AnnPolicyConfigMapName:
+			w.ch.AnnPolicyConfigMapDataNew = cm.Data
 		}
 	}
 	return []*hdlr{
@@ -139,7 +155,12 @@ func (w *watchers) handlersCore() []*hdlr {
 				predicate.NewPredicateFuncs(func(o client.Object) bool {
 					cm := o.(*api.ConfigMap)
 					key := cm.Namespace + "/" + cm.Name
-					return key == w.cfg.ConfigMapName || key == w.cfg.TCPConfigMapName
+					if key == w.cfg.ConfigMapName || key == w.cfg.TCPConfigMapName || key == w.cfg.AnnPolicyConfigMapName {
+						return true
+					}
+					// ConfigMaps referenced by the configuration: JWT keys, and CA bundles of
+					// Gateway API's BackendTLSPolicy, only tracked if its API is enabled
+					return w.val.IsValidConfigMap(cm)
 				}),
 			},
 		},
@@ -400,6 +421,19 @@ func (w *watchers) handlersGatewayv1() []*hdlr {
 	}
 }
 
+func (w *watchers) handlersGRPCRoutev1alpha2() []*hdlr {
+	return []*hdlr{
+		{
+			typ:  &gatewayv1alpha2.GRPCRoute{},
+			res:  types.ResourceGRPCRoute,
+			full: true,
+			pr: []predicate.Predicate{
+				predicate.GenerationChangedPredicate{},
+			},
+		},
+	}
+}
+
 func (w *watchers) handlersTCPRoutev1alpha2() []*hdlr {
 	return []*hdlr{
 		{
@@ -413,6 +447,32 @@ func (w *watchers) handlersTCPRoutev1alpha2() []*hdlr {
 	}
 }
 
+func (w *watchers) handlersReferenceGrantv1beta1() []*hdlr {
+	return []*hdlr{
+		{
+			typ:  &gatewayv1beta1.ReferenceGrant{},
+			res:  types.ResourceReferenceGrant,
+			full: true,
+			pr: []predicate.Predicate{
+				predicate.GenerationChangedPredicate{},
+			},
+		},
+	}
+}
+
+func (w *watchers) handlersBackendTLSPolicyv1alpha2() []*hdlr {
+	return []*hdlr{
+		{
+			typ:  &gatewayv1alpha2.BackendTLSPolicy{},
+			res:  types.ResourceBackendTLSPolicy,
+			full: true,
+			pr: []predicate.Predicate{
+				predicate.GenerationChangedPredicate{},
+			},
+		},
+	}
+}
+
 type hdlr struct {
 	w   *watchers
 	typ client.Object
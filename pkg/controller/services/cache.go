@@ -35,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
@@ -67,13 +68,17 @@ type c struct {
 	sslCerts  *SSL
 	dynconfig *convtypes.DynamicConfig
 	status    svcStatusUpdateFnc
+	recorder  record.EventRecorder
 }
 
 var errGatewayA2Disabled = fmt.Errorf("gateway API v1alpha2 wasn't initialized")
 var errGatewayB1Disabled = fmt.Errorf("gateway API v1beta1 wasn't initialized")
 var errGatewayV1Disabled = fmt.Errorf("gateway API v1 wasn't initialized")
+var errGRPCRouteA2Disabled = fmt.Errorf("GRPCRoute API v1alpha2 wasn't initialized")
 var errTCPRouteA2Disabled = fmt.Errorf("TCPRoute API v1alpha2 wasn't initialized")
 var errTLSRouteA2Disabled = fmt.Errorf("TLSRoute API v1alpha2 wasn't initialized")
+var errRefGrantB1Disabled = fmt.Errorf("ReferenceGrant API v1beta1 wasn't initialized")
+var errBackendTLSA2Disabled = fmt.Errorf("BackendTLSPolicy API v1alpha2 wasn't initialized")
 
 func (c *c) get(key string, obj client.Object) error {
 	ns, n, err := cache.SplitMetaNamespaceKey(key)
@@ -124,6 +129,12 @@ func (c *c) getCertificate(namespace, secretName string) (*sslCert, error) {
 	return c.sslCerts.getCertificate(&secret)
 }
 
+// IsValidConfigMap returns true if the ConfigMap is referenced by the current
+// configuration, e.g. as the CA bundle of a BackendTLSPolicy.
+func (c *c) IsValidConfigMap(cm *api.ConfigMap) bool {
+	return c.tracker.IsTracked(convtypes.ResourceConfigMap, cm.Namespace+"/"+cm.Name)
+}
+
 func (c *c) IsValidIngress(ing *networking.Ingress) bool {
 	// check if ingress `hasAnn` and, if so, if it's valid `fromAnn` perspective
 	var hasAnn, fromAnn bool
@@ -339,6 +350,60 @@ func (c *c) GetGateway(namespace, name string) (*gatewayv1.Gateway, error) {
 	return &gw, err
 }
 
+func (c *c) GetGatewayA2List() ([]*gatewayv1alpha2.Gateway, error) {
+	if !c.config.HasGatewayA2 {
+		return nil, errGatewayA2Disabled
+	}
+	list := gatewayv1alpha2.GatewayList{}
+	err := c.client.List(c.ctx, &list)
+	if err != nil {
+		return nil, err
+	}
+	var refList []*gatewayv1alpha2.Gateway
+	for i := range list.Items {
+		if gw := &list.Items[i]; c.IsValidGatewayA2(gw) {
+			refList = append(refList, gw)
+		}
+	}
+	return refList, nil
+}
+
+func (c *c) GetGatewayB1List() ([]*gatewayv1beta1.Gateway, error) {
+	if !c.config.HasGatewayB1 {
+		return nil, errGatewayB1Disabled
+	}
+	list := gatewayv1beta1.GatewayList{}
+	err := c.client.List(c.ctx, &list)
+	if err != nil {
+		return nil, err
+	}
+	var refList []*gatewayv1beta1.Gateway
+	for i := range list.Items {
+		if gw := &list.Items[i]; c.IsValidGatewayB1(gw) {
+			refList = append(refList, gw)
+		}
+	}
+	return refList, nil
+}
+
+func (c *c) GetGatewayList() ([]*gatewayv1.Gateway, error) {
+	if !c.config.HasGatewayV1 {
+		return nil, errGatewayV1Disabled
+	}
+	list := gatewayv1.GatewayList{}
+	err := c.client.List(c.ctx, &list)
+	if err != nil {
+		return nil, err
+	}
+	var rlist []*gatewayv1.Gateway
+	for i := range list.Items {
+		if gw := &list.Items[i]; c.IsValidGateway(gw) {
+			rlist = append(rlist, gw)
+		}
+	}
+	return rlist, nil
+}
+
 func (c *c) GetHTTPRouteA2List() ([]*gatewayv1alpha2.HTTPRoute, error) {
 	if !c.config.HasGatewayA2 {
 		return nil, errGatewayA2Disabled
@@ -387,6 +452,22 @@ func (c *c) GetHTTPRouteList() ([]*gatewayv1.HTTPRoute, error) {
 	return rlist, nil
 }
 
+func (c *c) GetGRPCRouteList() ([]*gatewayv1alpha2.GRPCRoute, error) {
+	if !c.config.HasGRPCRouteA2 {
+		return nil, errGRPCRouteA2Disabled
+	}
+	list := gatewayv1alpha2.GRPCRouteList{}
+	err := c.client.List(c.ctx, &list)
+	if err != nil {
+		return nil, err
+	}
+	rlist := make([]*gatewayv1alpha2.GRPCRoute, len(list.Items))
+	for i := range list.Items {
+		rlist[i] = &list.Items[i]
+	}
+	return rlist, nil
+}
+
 func (c *c) GetTCPRouteList() ([]*gatewayv1alpha2.TCPRoute, error) {
 	if !c.config.HasTCPRouteA2 {
 		return nil, errTCPRouteA2Disabled
@@ -419,6 +500,38 @@ func (c *c) GetTLSRouteList() ([]*gatewayv1alpha2.TLSRoute, error) {
 	return rlist, nil
 }
 
+func (c *c) GetReferenceGrantList(namespace string) ([]*gatewayv1beta1.ReferenceGrant, error) {
+	if !c.config.HasReferenceGrantB1 {
+		return nil, errRefGrantB1Disabled
+	}
+	list := gatewayv1beta1.ReferenceGrantList{}
+	err := c.client.List(c.ctx, &list, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	rlist := make([]*gatewayv1beta1.ReferenceGrant, len(list.Items))
+	for i := range list.Items {
+		rlist[i] = &list.Items[i]
+	}
+	return rlist, nil
+}
+
+func (c *c) GetBackendTLSPolicyList() ([]*gatewayv1alpha2.BackendTLSPolicy, error) {
+	if !c.config.HasBackendTLSPolicyA2 {
+		return nil, errBackendTLSA2Disabled
+	}
+	list := gatewayv1alpha2.BackendTLSPolicyList{}
+	err := c.client.List(c.ctx, &list)
+	if err != nil {
+		return nil, err
+	}
+	rlist := make([]*gatewayv1alpha2.BackendTLSPolicy, len(list.Items))
+	for i := range list.Items {
+		rlist[i] = &list.Items[i]
+	}
+	return rlist, nil
+}
+
 func (c *c) GetService(defaultNamespace, serviceName string) (*api.Service, error) {
 	namespace, name, err := buildResourceName(defaultNamespace, "service", serviceName, c.dynconfig.CrossNamespaceServices)
 	if err != nil {
@@ -624,6 +737,23 @@ func (c *c) GetCASecretPath(defaultNamespace, secretName string, track []convtyp
 	return ca, crl, nil
 }
 
+func (c *c) GetCAConfigMapPath(namespace, configMapName string, track []convtypes.TrackingRef) (file convtypes.File, err error) {
+	c.tracker.TrackRefName(track, convtypes.ResourceConfigMap, namespace+"/"+configMapName)
+	cm := api.ConfigMap{}
+	err = c.client.Get(c.ctx, types.NamespacedName{Namespace: namespace, Name: configMapName}, &cm)
+	if err != nil {
+		return file, err
+	}
+	sslCert, err := c.sslCerts.getCAFromConfigMap(&cm)
+	if err != nil {
+		return file, err
+	}
+	return convtypes.File{
+		Filename: sslCert.CAFileName,
+		SHA1Hash: sslCert.PemSHA,
+	}, nil
+}
+
 func (c *c) GetDHSecretPath(defaultNamespace, secretName string) (file convtypes.File, err error) {
 	proto, content := getContentProtocol(secretName)
 	if proto == "file" {
@@ -682,6 +812,51 @@ func (c *c) GetPasswdSecretContent(defaultNamespace, secretName string, track []
 	return data, nil
 }
 
+func (c *c) GetJWTKeyPath(defaultNamespace, keyName string, track []convtypes.TrackingRef) ([]convtypes.JWTKeyFile, error) {
+	const jwksKey, pemKey = "jwks.json", "jwt.pem"
+	proto, content := getContentProtocol(keyName)
+	var resourceType convtypes.ResourceType
+	var data map[string][]byte
+	switch proto {
+	case "secret":
+		resourceType = convtypes.ResourceSecret
+	case "configmap":
+		resourceType = convtypes.ResourceConfigMap
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", proto)
+	}
+	namespace, name, err := buildResourceName(defaultNamespace, proto, content, c.dynconfig.CrossNamespaceSecretCA)
+	if err != nil {
+		return nil, err
+	}
+	c.tracker.TrackRefName(track, resourceType, namespace+"/"+name)
+	fileNamePrefix := fmt.Sprintf("%s_%s", namespace, name)
+	if resourceType == convtypes.ResourceSecret {
+		secret := api.Secret{}
+		err = c.client.Get(c.ctx, types.NamespacedName{Namespace: namespace, Name: name}, &secret)
+		if err != nil {
+			return nil, err
+		}
+		data = secret.Data
+	} else {
+		cm := api.ConfigMap{}
+		err = c.client.Get(c.ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cm)
+		if err != nil {
+			return nil, err
+		}
+		data = make(map[string][]byte, len(cm.Data))
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+		fileNamePrefix = "cm_" + fileNamePrefix
+	}
+	jwks, pem := data[jwksKey], data[pemKey]
+	if len(jwks) == 0 && len(pem) == 0 {
+		return nil, fmt.Errorf("%s '%s/%s' has neither '%s' nor '%s' key", proto, namespace, name, jwksKey, pemKey)
+	}
+	return c.sslCerts.getJWTKeys(fileNamePrefix, jwks, pem)
+}
+
 func (c *c) SwapChangedObjects() *convtypes.ChangedObjects {
 	// deprecated func
 	// converter is adapted to not call this facade
@@ -693,6 +868,12 @@ func (c *c) UpdateStatus(obj client.Object) {
 	c.status(obj)
 }
 
+func (c *c) RecordEvent(obj client.Object, eventtype, reason, message string) {
+	if c.recorder != nil {
+		c.recorder.Event(obj, eventtype, reason, message)
+	}
+}
+
 //
 // Starting acme.Cache implementation
 //
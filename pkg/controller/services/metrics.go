@@ -88,7 +88,7 @@ func createMetrics(bucketsResponseTime []float64) *metrics {
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "updates_total",
-				Help:      "Cumulative number of Ingress controller updates. Status can be noop, dynamic, full.",
+				Help:      "Cumulative number of Ingress controller updates. Status can be noop, dynamic, partial, full, rollback.",
 			},
 			[]string{"status"},
 		),
@@ -132,6 +132,10 @@ func (m *metrics) HAProxySetSSLCertResponseTime(duration time.Duration) {
 	m.responseTime.WithLabelValues("set_ssl_cert").Observe(duration.Seconds())
 }
 
+func (m *metrics) HAProxySetMapResponseTime(duration time.Duration) {
+	m.responseTime.WithLabelValues("set_map").Observe(duration.Seconds())
+}
+
 func (m *metrics) ControllerProcTime(task string, duration time.Duration) {
 	m.ctlProcTimeSum.WithLabelValues(task).Add(duration.Seconds())
 	m.ctlProcCount.WithLabelValues(task).Inc()
@@ -156,10 +160,18 @@ func (m *metrics) IncUpdateDynamic() {
 	m.updatesCounter.WithLabelValues("dynamic").Inc()
 }
 
+func (m *metrics) IncUpdatePartial() {
+	m.updatesCounter.WithLabelValues("partial").Inc()
+}
+
 func (m *metrics) IncUpdateFull() {
 	m.updatesCounter.WithLabelValues("full").Inc()
 }
 
+func (m *metrics) IncUpdateRollback() {
+	m.updatesCounter.WithLabelValues("rollback").Inc()
+}
+
 func (m *metrics) UpdateSuccessful(success bool) {
 	value := map[bool]float64{false: 0, true: 1}
 	m.updateSuccessGauge.WithLabelValues().Set(value[success])
@@ -19,9 +19,11 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/go-logr/logr"
+	api "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,6 +34,7 @@ import (
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/tracker"
 	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy"
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/utils"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/utils/workqueue"
 )
@@ -106,7 +109,7 @@ func (s *Services) setup(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	svchealthz, err := initSvcHealthz(ctx, cfg, metrics, s.acmeExternalCallCheck)
+	svchealthz, err := initSvcHealthz(ctx, cfg, metrics, s.acmeExternalCallCheck, s.haproxyVersion)
 	if err != nil {
 		return err
 	}
@@ -145,17 +148,21 @@ func (s *Services) setup(ctx context.Context) error {
 		ValidateConfig:    cfg.ValidateConfig,
 		AcmeSigner:        acmeSigner,
 		AcmeQueue:         acmeQueue,
+		SnippetRejected:   s.snippetRejected,
+		ConfigRolledBack:  s.configRolledBack,
 	}
 	converterOptions := &convtypes.ConverterOptions{
 		Logger:           s.legacylogger.new("converter"),
 		Cache:            cache,
 		Tracker:          tracker,
+		ControllerName:   cfg.ControllerName,
 		DynamicConfig:    dynConfig,
 		LocalFSPrefix:    cfg.LocalFSPrefix,
 		IsExternal:       instanceOptions.IsExternal,
 		MasterSocket:     instanceOptions.MasterSocket,
 		AdminSocket:      instanceOptions.AdminSocket,
 		AcmeSocket:       instanceOptions.AcmeSocket,
+		HAProxyVersion:   s.haproxyVersion,
 		AnnotationPrefix: cfg.AnnPrefix,
 		DefaultBackend:   cfg.DefaultService,
 		DefaultCrtSecret: cfg.DefaultSSLCertificate,
@@ -167,13 +174,19 @@ func (s *Services) setup(ctx context.Context) error {
 		HasGatewayA2:     cfg.HasGatewayA2,
 		HasGatewayB1:     cfg.HasGatewayB1,
 		HasGatewayV1:     cfg.HasGatewayV1,
+		HasGRPCRouteA2:   cfg.HasGRPCRouteA2,
 		HasTCPRouteA2:    cfg.HasTCPRouteA2,
 		HasTLSRouteA2:    cfg.HasTLSRouteA2,
+		HasRefGrantB1:    cfg.HasReferenceGrantB1,
+		HasBackendTLSA2:  cfg.HasBackendTLSPolicyA2,
+		AnnPolicyReports: map[string]string{},
 	}
 	instance := haproxy.CreateInstance(s.legacylogger.new("haproxy"), instanceOptions)
 	if err := instance.ParseTemplates(); err != nil {
 		return fmt.Errorf("error creating HAProxy instance: %w", err)
 	}
+	// an external haproxy might not be running yet, its version is read on a later attempt
+	_ = instance.HAProxyVersion()
 	s.acmeClient = acmeClient
 	s.acmeServer = acmeServer
 	s.cache = cache
@@ -190,6 +203,7 @@ func (s *Services) setup(ctx context.Context) error {
 }
 
 func (s *Services) withManager(mgr ctrl.Manager) error {
+	s.cache.recorder = mgr.GetEventRecorderFor("haproxy-ingress")
 	if s.Config.Election {
 		if err := mgr.Add(s.svcleader); err != nil {
 			return err
@@ -301,6 +315,65 @@ func (s *Services) acmeCheck(source string) (count int, err error) {
 	return count, err
 }
 
+func (s *Services) haproxyVersion() hatypes.HAProxyVersion {
+	if s.instance == nil {
+		return hatypes.HAProxyVersion{}
+	}
+	return s.instance.HAProxyVersion()
+}
+
+func (s *Services) snippetRejected(source *hatypes.SnippetSource, reason string) {
+	var obj client.Object
+	var err error
+	fullname := source.Namespace + "/" + source.Name
+	switch convtypes.ResourceType(source.Kind) {
+	case convtypes.ResourceIngress:
+		obj, err = s.cache.GetIngress(fullname)
+	case convtypes.ResourceService:
+		obj, err = s.cache.GetService("", fullname)
+	default:
+		return
+	}
+	if err != nil {
+		s.log.Error(err, "cannot record event", "source", source.String())
+		return
+	}
+	s.cache.RecordEvent(obj, api.EventTypeWarning, "SnippetRejected",
+		fmt.Sprintf("configuration key '%s' was ignored, rejected by haproxy: %s", source.Key, reason))
+}
+
+func (s *Services) configRolledBack(hosts, backends []string) {
+	changes := map[convtypes.TrackingRef][]string{}
+	for _, host := range hosts {
+		for _, ref := range s.converterOpt.Tracker.Links(convtypes.ResourceHAHostname, host) {
+			changes[ref] = append(changes[ref], "host "+host)
+		}
+	}
+	for _, backend := range backends {
+		for _, ref := range s.converterOpt.Tracker.Links(convtypes.ResourceHABackend, backend) {
+			changes[ref] = append(changes[ref], "backend "+backend)
+		}
+	}
+	for ref, objChanges := range changes {
+		var obj client.Object
+		var err error
+		switch ref.Context {
+		case convtypes.ResourceIngress:
+			obj, err = s.cache.GetIngress(ref.UniqueName)
+		case convtypes.ResourceService:
+			obj, err = s.cache.GetService("", ref.UniqueName)
+		default:
+			continue
+		}
+		if err != nil {
+			s.log.Error(err, "cannot record event", "context", ref.Context, "name", ref.UniqueName)
+			continue
+		}
+		s.cache.RecordEvent(obj, api.EventTypeWarning, "ConfigRolledBack",
+			fmt.Sprintf("configuration rolled back, haproxy rejected the changes of %s", strings.Join(objChanges, ", ")))
+	}
+}
+
 func (s *Services) reloadHAProxy(context.Context, any) error {
 	s.log.Info("acquiring haproxy reload lock")
 	s.modelMutex.Lock()
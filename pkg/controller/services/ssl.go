@@ -17,6 +17,7 @@ limitations under the License.
 package services
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -26,9 +27,12 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"math"
 	"math/big"
 	"os"
 	"strings"
@@ -297,6 +301,15 @@ func (s *SSL) getCertificate(secret *api.Secret) (*sslCert, error) {
 	return nil, fmt.Errorf("secret '%s/%s' have neither ca.crt nor tls.crt/tls.key pair", ns, name)
 }
 
+func (s *SSL) getCAFromConfigMap(cm *api.ConfigMap) (*sslCert, error) {
+	ca := cm.Data["ca.crt"]
+	if ca == "" {
+		return nil, fmt.Errorf("configmap '%s/%s' does not have key 'ca.crt'", cm.Namespace, cm.Name)
+	}
+	caFileName := fmt.Sprintf("%s/ca_cm_%s_%s.pem", s.c.DefaultDirCACerts, cm.Namespace, cm.Name)
+	return s.buildCertFromCAAndCRL(caFileName, "", []byte(ca), nil)
+}
+
 func (s *SSL) getDHParam(secret *api.Secret) (*sslCert, error) {
 	ns := secret.Namespace
 	name := secret.Name
@@ -319,3 +332,175 @@ func (s *SSL) getDHParam(secret *api.Secret) (*sslCert, error) {
 		PemSHA:      hex.EncodeToString(pemSHA1[:]),
 	}, nil
 }
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwtKey struct {
+	pubKey    any
+	keyID     string
+	algorithm string
+}
+
+// getJWTKeys reads a JWKS document or a list of PEM encoded public keys or certificates,
+// and writes every public key, PEM encoded, into its own file, so haproxy can load them.
+func (s *SSL) getJWTKeys(fileNamePrefix string, jwks, pemKeys []byte) ([]convtypes.JWTKeyFile, error) {
+	var keys []jwtKey
+	var err error
+	if len(jwks) > 0 {
+		keys, err = s.readJWKS(jwks)
+	} else {
+		keys, err = s.readPEMPublicKeys(pemKeys)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public key found")
+	}
+	files := make([]convtypes.JWTKeyFile, len(keys))
+	for i, key := range keys {
+		var keyType string
+		switch key.pubKey.(type) {
+		case *rsa.PublicKey:
+			keyType = "RSA"
+		case *ecdsa.PublicKey:
+			keyType = "EC"
+		default:
+			return nil, fmt.Errorf("unsupported public key type: %T", key.pubKey)
+		}
+		der, err := x509.MarshalPKIXPublicKey(key.pubKey)
+		if err != nil {
+			return nil, err
+		}
+		output := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		fileName := fmt.Sprintf("%s/%s_%d.pem", s.c.DefaultDirJWTKeys, fileNamePrefix, i)
+		if err := os.WriteFile(fileName, output, 0600); err != nil {
+			return nil, err
+		}
+		pemSHA1 := sha1.Sum(output)
+		files[i] = convtypes.JWTKeyFile{
+			Filename:  fileName,
+			SHA1Hash:  hex.EncodeToString(pemSHA1[:]),
+			KeyID:     key.keyID,
+			Algorithm: key.algorithm,
+			KeyType:   keyType,
+		}
+	}
+	return files, nil
+}
+
+func (s *SSL) readJWKS(raw []byte) ([]jwtKey, error) {
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &jwks); err != nil {
+		return nil, fmt.Errorf("error reading JWKS: %w", err)
+	}
+	var keys []jwtKey
+	for _, key := range jwks.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		pubKey, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("error reading JWK '%s': %w", key.Kid, err)
+		}
+		keys = append(keys, jwtKey{
+			pubKey:    pubKey,
+			keyID:     key.Kid,
+			algorithm: key.Alg,
+		})
+	}
+	return keys, nil
+}
+
+func (k *jwk) publicKey() (any, error) {
+	decode := func(field, value string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("invalid or missing '%s' field", field)
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := decode("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > math.MaxInt32 {
+			return nil, fmt.Errorf("invalid 'e' field")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: '%s'", k.Crv)
+		}
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pubKey := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := pubKey.ECDH(); err != nil {
+			return nil, err
+		}
+		return pubKey, nil
+	}
+	return nil, fmt.Errorf("unsupported key type: '%s'", k.Kty)
+}
+
+func (s *SSL) readPEMPublicKeys(raw []byte) ([]jwtKey, error) {
+	var keys []jwtKey
+	for len(bytes.TrimSpace(raw)) > 0 {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			return nil, fmt.Errorf("no valid PEM formatted block found")
+		}
+		var pubKey any
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			pubKey = key
+		case "CERTIFICATE":
+			crt, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			pubKey = crt.PublicKey
+		default:
+			return nil, fmt.Errorf("expected PEM type(s) 'PUBLIC KEY,CERTIFICATE', found '%s'", block.Type)
+		}
+		keys = append(keys, jwtKey{pubKey: pubKey})
+	}
+	return keys, nil
+}
@@ -16,9 +16,11 @@ import (
 	"k8s.io/apiserver/pkg/server/healthz"
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/controller/config"
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/version"
 )
 
-func initSvcHealthz(ctx context.Context, cfg *config.Config, metrics *metrics, acmeCheck svcAcmeCheckFnc) (*svcHealthz, error) {
+func initSvcHealthz(ctx context.Context, cfg *config.Config, metrics *metrics, acmeCheck svcAcmeCheckFnc, haproxyVersion func() hatypes.HAProxyVersion) (*svcHealthz, error) {
 	if cfg.HealthzAddr == "" {
 		return nil, nil
 	}
@@ -31,7 +33,7 @@ func initSvcHealthz(ctx context.Context, cfg *config.Config, metrics *metrics, a
 	healthz.InstallPathHandler(mux, cfg.ReadyzURL)
 	mux.Handle("/", s.createRootHealthzHandler())
 	mux.Handle("/acme/check", s.createAcmeHandler(acmeCheck))
-	mux.Handle("/build", s.createBuildHandler(cfg))
+	mux.Handle("/build", s.createBuildHandler(cfg, haproxyVersion))
 	if cfg.Profiling {
 		mux.HandleFunc("/debug/pprof/", pprof.Index)
 		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
@@ -71,7 +73,7 @@ func (s *svcHealthz) createRootHealthzHandler() http.HandlerFunc {
 	// TODO build a html index
 	contentType := "text/plain"
 	page := `/acme/check (only POST): starts a new check for certificates that need to be issued
-/build : build info
+/build : build info, along with the haproxy version and capabilities
 /debug/pprof/ : pprof index` + pprofDisabled + `
 /metrics : HAProxy Ingress metrics in Prometheus format
 /stop : stops the controller process` + stopDisabled + `
@@ -111,9 +113,27 @@ func (s *svcHealthz) createAcmeHandler(acmeCheck func() (int, error)) http.Handl
 	}
 }
 
-func (s *svcHealthz) createBuildHandler(cfg *config.Config) http.HandlerFunc {
-	build, _ := json.Marshal(cfg.VersionInfo)
+type buildInfo struct {
+	version.Info
+	HAProxy *haproxyBuildInfo `json:",omitempty"`
+}
+
+type haproxyBuildInfo struct {
+	hatypes.HAProxyVersion
+	Capabilities map[hatypes.Feature]bool
+}
+
+func (s *svcHealthz) createBuildHandler(cfg *config.Config, haproxyVersion func() hatypes.HAProxyVersion) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		info := buildInfo{Info: cfg.VersionInfo}
+		// haproxy version is omitted while it is not known, eg an external haproxy that is not running yet
+		if v := haproxyVersion(); v.Known() {
+			info.HAProxy = &haproxyBuildInfo{
+				HAProxyVersion: v,
+				Capabilities:   v.Capabilities(),
+			}
+		}
+		build, _ := json.Marshal(info)
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = w.Write(build)
 	}
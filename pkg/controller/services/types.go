@@ -17,6 +17,7 @@ limitations under the License.
 package services
 
 import (
+	api "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
@@ -33,4 +34,5 @@ type IsValidResource interface {
 	IsValidGatewayClass(gwcls *gatewayv1.GatewayClass) bool
 	IsValidIngress(ing *networking.Ingress) bool
 	IsValidIngressClass(ing *networking.IngressClass) bool
+	IsValidConfigMap(cm *api.ConfigMap) bool
 }
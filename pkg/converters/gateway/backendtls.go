@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"fmt"
+	"sort"
+
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+// backendTLS is the backend side TLS configuration of a Service, built from a BackendTLSPolicy.
+type backendTLS struct {
+	policy   *policyStatus
+	hostname string
+	caFile   convtypes.File
+}
+
+// syncBackendTLSPolicies reads all the BackendTLSPolicy resources that target a Service,
+// so they can be applied on the backends that use these services.
+func (c *converter) syncBackendTLSPolicies() {
+	c.backendTLSServices = make(map[string]*backendTLS)
+	c.backendTLS = make(map[string]*backendTLS)
+	if !c.options.HasBackendTLSA2 {
+		return
+	}
+	policies, err := c.cache.GetBackendTLSPolicyList()
+	if err != nil {
+		c.logger.Warn("error reading backendTLSPolicy list: %v", err)
+		return
+	}
+	// older policy wins on conflicts
+	sort.Slice(policies, func(i, j int) bool {
+		p1 := policies[i]
+		p2 := policies[j]
+		if p1.CreationTimestamp != p2.CreationTimestamp {
+			return p1.CreationTimestamp.Time.Before(p2.CreationTimestamp.Time)
+		}
+		return p1.Namespace+"/"+p1.Name < p2.Namespace+"/"+p2.Name
+	})
+	for _, policy := range policies {
+		targetRef := policy.Spec.TargetRef
+		if targetRef.Group != "" || targetRef.Kind != "Service" {
+			// not a Service, policy is not for us
+			continue
+		}
+		policySource := newSource(policy)
+		svcName := policy.Namespace + "/" + string(targetRef.Name)
+		if other := c.backendTLSServices[svcName]; other != nil {
+			c.logger.Warn("skipping %s: service '%s' is already targeted by %s", &policySource, svcName, other.policy.source)
+			continue
+		}
+		tls := &backendTLS{
+			policy:   c.status.policy(policy),
+			hostname: string(policy.Spec.TLS.Hostname),
+		}
+		c.backendTLSServices[svcName] = tls
+		if targetRef.Namespace != nil && string(*targetRef.Namespace) != policy.Namespace {
+			tls.policy.err = fmt.Errorf("target reference to a Service in another namespace is not supported")
+		} else if targetRef.SectionName != nil && *targetRef.SectionName != "" {
+			tls.policy.err = fmt.Errorf("target reference to a Service port is not supported")
+		} else if len(validation.IsDNS1123Subdomain(tls.hostname)) > 0 {
+			tls.policy.err = fmt.Errorf("invalid hostname '%s': a lowercase DNS name is expected", tls.hostname)
+		} else if version := c.haproxy.Global().HAProxy; usesSystemCA(policy) && !version.Supports(hatypes.FeatureSystemCA) {
+			tls.policy.err = fmt.Errorf("wellKnownCACerts '%s' is not supported on haproxy %s, it needs haproxy 2.6 or newer",
+				gatewayv1alpha2.WellKnownCACertSystem, version)
+		} else if tls.caFile, tls.policy.err = c.readBackendTLSCA(policy); tls.policy.err != nil {
+			tls.policy.unresolvedRefs = true
+		}
+		if tls.policy.err != nil {
+			c.logger.Warn("skipping %s: %v", &policySource, tls.policy.err)
+		}
+	}
+}
+
+// usesSystemCA returns true if the policy validates the server certificate
+// with the CA certificates of the system, which haproxy reads from @system-ca.
+func usesSystemCA(policy *gatewayv1alpha2.BackendTLSPolicy) bool {
+	tlsConfig := policy.Spec.TLS
+	return len(tlsConfig.CACertRefs) == 0 && tlsConfig.WellKnownCACerts != nil &&
+		*tlsConfig.WellKnownCACerts == gatewayv1alpha2.WellKnownCACertSystem
+}
+
+func (c *converter) readBackendTLSCA(policy *gatewayv1alpha2.BackendTLSPolicy) (convtypes.File, error) {
+	tlsConfig := policy.Spec.TLS
+	if len(tlsConfig.CACertRefs) == 0 {
+		if usesSystemCA(policy) {
+			return convtypes.File{Filename: "@system-ca"}, nil
+		}
+		return convtypes.File{}, fmt.Errorf("missing CA certificate reference")
+	}
+	if len(tlsConfig.CACertRefs) > 1 {
+		return convtypes.File{}, fmt.Errorf("only one CA certificate reference is supported")
+	}
+	caRef := tlsConfig.CACertRefs[0]
+	track := []convtypes.TrackingRef{{Context: convtypes.ResourceGateway, UniqueName: "gw"}}
+	if caRef.Group != "" && caRef.Group != "core" {
+		return convtypes.File{}, fmt.Errorf("unsupported CA certificate reference Group '%s'", caRef.Group)
+	}
+	switch caRef.Kind {
+	case "ConfigMap":
+		return c.cache.GetCAConfigMapPath(policy.Namespace, string(caRef.Name), track)
+	case "Secret":
+		caFile, _, err := c.cache.GetCASecretPath("", policy.Namespace+"/"+string(caRef.Name), track)
+		return caFile, err
+	}
+	return convtypes.File{}, fmt.Errorf("unsupported CA certificate reference Kind '%s'", caRef.Kind)
+}
+
+// registerBackendTLS links a newly created backend with the BackendTLSPolicy of its services.
+func (c *converter) registerBackendTLS(routeSource *source, backend *hatypes.Backend, services []*api.Service) {
+	var tls *backendTLS
+	for _, svc := range services {
+		svcTLS := c.backendTLSServices[svc.Namespace+"/"+svc.Name]
+		if tls == nil {
+			tls = svcTLS
+		} else if svcTLS != tls {
+			c.logger.Warn("ignoring backend TLS configuration of service '%s' on %s: all services of a rule should share the same BackendTLSPolicy",
+				svc.Name, routeSource)
+		}
+	}
+	if tls != nil {
+		c.backendTLS[backend.ID] = tls
+	}
+}
+
+// applyBackendTLS configures TLS on a backend based on its BackendTLSPolicy. It should be
+// called after reading the Service annotations, so the policy has precedence.
+func (c *converter) applyBackendTLS(gatewaySource *gatewaySource, backend *hatypes.Backend) {
+	tls := c.backendTLS[backend.ID]
+	if tls == nil {
+		return
+	}
+	tls.policy.addAncestor(c.status.gateway(gatewaySource))
+	if tls.policy.err != nil {
+		// fail closed: the backend should not fall back to plain text if its
+		// policy is invalid, so requests are answered with 503 instead.
+		backend.Endpoints = nil
+		return
+	}
+	backend.Server.Secure = true
+	backend.Server.CAFilename = tls.caFile.Filename
+	backend.Server.CAHash = tls.caFile.SHA1Hash
+	backend.Server.SNI = fmt.Sprintf("str(%s)", tls.hostname)
+	backend.Server.VerifyHost = tls.hostname
+}
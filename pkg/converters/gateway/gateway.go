@@ -19,9 +19,12 @@ package gateway
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	api "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +34,7 @@ import (
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	ingtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
 	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
 	convutils "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/utils"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy"
@@ -65,6 +69,10 @@ type converter struct {
 	cache   convtypes.Cache
 	tracker convtypes.Tracker
 	ann     convtypes.AnnotationReader
+	status  *statusCollector
+	//
+	backendTLS         map[string]*backendTLS
+	backendTLSServices map[string]*backendTLS
 }
 
 func (c *converter) NeedFullSync() bool {
@@ -82,12 +90,59 @@ func (c *converter) Sync(full bool, gwtyp client.Object) {
 		return
 	}
 
+	c.status = newStatusCollector()
+	c.syncGateways(gwtyp)
+	c.syncBackendTLSPolicies()
+
 	// we're not testing TLSRoute hostname declaration collision on HTTPRoute,
 	// so a validation should be added in case the order changes and
 	// syncTLSRoutes() come first.
 	c.syncHTTPRoutes(gwtyp)
+	c.syncGRPCRoutes(gwtyp)
 	c.syncTLSRoutes(gwtyp)
 	c.syncTCPRoutes(gwtyp)
+
+	c.publishStatus()
+}
+
+// syncGateways registers all the gateways managed by this controller,
+// so their status is published even if no route is attached to them.
+func (c *converter) syncGateways(gwtyp client.Object) {
+	var gateways []client.Object
+	switch gwtyp.(type) {
+	case *gatewayv1alpha2.Gateway:
+		gwList, err := c.cache.GetGatewayA2List()
+		if err != nil {
+			c.logger.Error("error reading gateway list: %v", err)
+			return
+		}
+		for _, gw := range gwList {
+			gateways = append(gateways, gw)
+		}
+	case *gatewayv1beta1.Gateway:
+		gwList, err := c.cache.GetGatewayB1List()
+		if err != nil {
+			c.logger.Error("error reading gateway list: %v", err)
+			return
+		}
+		for _, gw := range gwList {
+			gateways = append(gateways, gw)
+		}
+	case *gatewayv1.Gateway:
+		gwList, err := c.cache.GetGatewayList()
+		if err != nil {
+			c.logger.Error("error reading gateway list: %v", err)
+			return
+		}
+		for _, gw := range gwList {
+			gateways = append(gateways, gw)
+		}
+	default:
+		panic(fmt.Errorf("unsupported Gateway type: %T", gwtyp))
+	}
+	for _, gw := range gateways {
+		c.status.gateway(newGatewaySourceFromObj(gw))
+	}
 }
 
 func (c *converter) syncHTTPRoutes(gwtyp client.Object) {
@@ -110,8 +165,8 @@ func (c *converter) syncHTTPRoutes(gwtyp client.Object) {
 
 	sortHTTPRoutes(httpRoutesSource)
 	for _, httpRoute := range httpRoutesSource {
-		c.syncRoute(&httpRoute.source, httpRoute.spec.ParentRefs, gwtyp, func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName) {
-			c.syncHTTPRouteGateway(httpRoute, gatewaySource, sectionName)
+		c.syncRoute(&httpRoute.source, httpRoute.spec.ParentRefs, gwtyp, func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
+			c.syncHTTPRouteGateway(httpRoute, gatewaySource, sectionName, parent)
 		})
 	}
 }
@@ -152,6 +207,27 @@ func (c *converter) getHTTPRoutesSource() ([]*httpRouteSource, error) {
 	return httpRoutesSource, nil
 }
 
+func (c *converter) syncGRPCRoutes(gwtyp client.Object) {
+	if !c.options.HasGRPCRouteA2 {
+		return
+	}
+	grpcRoutes, err := c.cache.GetGRPCRouteList()
+	if err != nil {
+		c.logger.Warn("error reading grpcRoute list: %v", err)
+		return
+	}
+	grpcRoutesSource := make([]*grpcRouteSource, len(grpcRoutes))
+	for i := range grpcRoutes {
+		grpcRoutesSource[i] = newGRPCRouteSource(grpcRoutes[i], &grpcRoutes[i].Spec)
+	}
+	sortGRPCRoutes(grpcRoutesSource)
+	for _, grpcRoute := range grpcRoutesSource {
+		c.syncRoute(&grpcRoute.source, grpcRoute.spec.ParentRefs, gwtyp, func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
+			c.syncGRPCRouteGateway(grpcRoute, gatewaySource, sectionName, parent)
+		})
+	}
+}
+
 func (c *converter) syncTLSRoutes(gwtyp client.Object) {
 	if !c.options.HasTLSRouteA2 {
 		return
@@ -167,8 +243,8 @@ func (c *converter) syncTLSRoutes(gwtyp client.Object) {
 	}
 	sortTLSRoutes(tlsRoutesSource)
 	for _, tlsRoute := range tlsRoutesSource {
-		c.syncRoute(&tlsRoute.source, tlsRoute.spec.ParentRefs, gwtyp, func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName) {
-			c.syncTLSRouteGateway(tlsRoute, gatewaySource, sectionName)
+		c.syncRoute(&tlsRoute.source, tlsRoute.spec.ParentRefs, gwtyp, func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
+			c.syncTLSRouteGateway(tlsRoute, gatewaySource, sectionName, parent)
 		})
 	}
 }
@@ -188,8 +264,8 @@ func (c *converter) syncTCPRoutes(gwtyp client.Object) {
 	}
 	sortTCPRoutes(tcpRoutesSource)
 	for _, tcpRoute := range tcpRoutesSource {
-		c.syncRoute(&tcpRoute.source, tcpRoute.spec.ParentRefs, gwtyp, func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName) {
-			c.syncTCPRouteGateway(tcpRoute, gatewaySource, sectionName)
+		c.syncRoute(&tcpRoute.source, tcpRoute.spec.ParentRefs, gwtyp, func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
+			c.syncTCPRouteGateway(tcpRoute, gatewaySource, sectionName, parent)
 		})
 	}
 }
@@ -205,6 +281,17 @@ func sortHTTPRoutes(httpRoutesSource []*httpRouteSource) {
 	})
 }
 
+func sortGRPCRoutes(grpcRoutesSource []*grpcRouteSource) {
+	sort.Slice(grpcRoutesSource, func(i, j int) bool {
+		r1 := grpcRoutesSource[i].obj
+		r2 := grpcRoutesSource[j].obj
+		if r1.GetCreationTimestamp() != r2.GetCreationTimestamp() {
+			return r1.GetCreationTimestamp().Time.Before(r2.GetCreationTimestamp().Time)
+		}
+		return r1.GetNamespace()+"/"+r1.GetName() < r2.GetNamespace()+"/"+r2.GetName()
+	})
+}
+
 func sortTLSRoutes(tlsRoutesSource []*tlsRouteSource) {
 	sort.Slice(tlsRoutesSource, func(i, j int) bool {
 		r1 := tlsRoutesSource[i].obj
@@ -238,6 +325,11 @@ type httpRouteSource struct {
 	spec *gatewayv1.HTTPRouteSpec
 }
 
+type grpcRouteSource struct {
+	source
+	spec *gatewayv1alpha2.GRPCRouteSpec
+}
+
 type tlsRouteSource struct {
 	source
 	spec *gatewayv1alpha2.TLSRouteSpec
@@ -273,6 +365,13 @@ func newHTTPRouteSource(obj client.Object, spec *gatewayv1.HTTPRouteSpec) *httpR
 	}
 }
 
+func newGRPCRouteSource(obj client.Object, spec *gatewayv1alpha2.GRPCRouteSpec) *grpcRouteSource {
+	return &grpcRouteSource{
+		spec:   spec,
+		source: newSource(obj),
+	}
+}
+
 func newTLSRouteSource(obj client.Object, spec *gatewayv1alpha2.TLSRouteSpec) *tlsRouteSource {
 	return &tlsRouteSource{
 		spec:   spec,
@@ -306,15 +405,18 @@ func (c *converter) newGatewaySource(namespace, name string, gwtyp client.Object
 		c.logger.Error("error reading gateway: %v", err)
 		return nil
 	}
-	vgw := reflect.ValueOf(gw)
-	if vgw.IsNil() {
+	if reflect.ValueOf(gw).IsNil() {
 		// Checking via reflection, since `gw` will always be `!= nil`
 		// because all cache methods return a pointer to the underlying struct.
 		// https://go.dev/doc/faq#nil_error
 		return nil
 	}
+	return newGatewaySourceFromObj(gw)
+}
+
+func newGatewaySourceFromObj(gw client.Object) *gatewaySource {
 	return &gatewaySource{
-		spec:   vgw.Elem().FieldByName("Spec").Addr().Interface().(*gatewayv1.GatewaySpec),
+		spec:   reflect.ValueOf(gw).Elem().FieldByName("Spec").Addr().Interface().(*gatewayv1.GatewaySpec),
 		source: newSource(gw),
 	}
 }
@@ -324,7 +426,8 @@ var (
 	gatewayKind  = gatewayv1.Kind("Gateway")
 )
 
-func (c *converter) syncRoute(routeSource *source, parentRefs []gatewayv1.ParentReference, gwtyp client.Object, syncGateway func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName)) {
+func (c *converter) syncRoute(routeSource *source, parentRefs []gatewayv1.ParentReference, gwtyp client.Object, syncGateway func(gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus)) {
+	route := c.status.route(routeSource)
 	for _, parentRef := range parentRefs {
 		parentGroup := gatewayGroup
 		parentKind := gatewayKind
@@ -348,11 +451,12 @@ func (c *converter) syncRoute(routeSource *source, parentRefs []gatewayv1.Parent
 			continue
 		}
 		// TODO implement gateway.Spec.Addresses
-		syncGateway(gatewaySource, parentRef.SectionName)
+		parent := route.addParent(parentRef, c.status.gateway(gatewaySource))
+		syncGateway(gatewaySource, parentRef.SectionName, parent)
 	}
 }
 
-func (c *converter) syncHTTPRouteGateway(httpRouteSource *httpRouteSource, gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName) {
+func (c *converter) syncHTTPRouteGateway(httpRouteSource *httpRouteSource, gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
 	for _, listener := range gatewaySource.spec.Listeners {
 		if sectionName != nil && *sectionName != listener.Name {
 			continue
@@ -363,28 +467,100 @@ func (c *converter) syncHTTPRouteGateway(httpRouteSource *httpRouteSource, gatew
 		if err := c.checkListenerAllowed(gatewaySource, &httpRouteSource.source, &listener); err != nil {
 			c.logger.Warn("skipping attachment of %s to %s listener '%s': %s",
 				httpRouteSource, gatewaySource, listener.Name, err)
+			parent.reject(err)
+			continue
+		}
+		hostnames := c.filterHostnames(listener.Hostname, httpRouteSource.spec.Hostnames)
+		if len(hostnames) == 0 {
+			c.logger.Warn("skipping attachment of %s to %s listener '%s': no intersecting hostname",
+				httpRouteSource, gatewaySource, listener.Name)
+			parent.rejectHostname()
 			continue
 		}
+		parent.attach(&listener)
 		for index, rule := range httpRouteSource.spec.Rules {
-			// TODO implement rule.Filters
 			backendRefs := make([]gatewayv1.BackendRef, len(rule.BackendRefs))
 			for i := range rule.BackendRefs {
 				// TODO implement HTTPBackendRef.Filters
 				backendRefs[i] = rule.BackendRefs[i].BackendRef
 			}
+			filters := c.readHTTPFilters(&httpRouteSource.source, rule.Filters)
+			if filters.mirror != nil && !c.hasMirrorAgent() {
+				err := fmt.Errorf("mirror agent endpoints are not configured")
+				c.logger.Warn("ignoring filter '%s' on %s: %v", gatewayv1.HTTPRouteFilterRequestMirror, &httpRouteSource.source, err)
+				c.status.route(&httpRouteSource.source).unsupportedValue(err)
+				filters.mirror = nil
+			}
 			backend, services := c.createBackend(&httpRouteSource.source, fmt.Sprintf("_rule%d", index), false, backendRefs)
+			if filters.mirror != nil {
+				mirrorRefs := []gatewayv1.BackendRef{{BackendObjectReference: filters.mirror.BackendRef}}
+				filters.mirrorBackend, _ = c.createBackend(&httpRouteSource.source, fmt.Sprintf("_rule%d_mirror", index), false, mirrorRefs)
+			}
+			if backend != nil || filters.redirect != nil {
+				// a redirect doesn't need a backend, its paths are configured in the redirect-to map
+				pathLinks := c.createHTTPHosts(gatewaySource, &httpRouteSource.source, &listener, hostnames, rule.Matches, filters, backend)
+				if backend != nil {
+					if c.ann != nil {
+						c.ann.ReadAnnotations(backend, services, pathLinks)
+					}
+					c.applyBackendTLS(gatewaySource, backend)
+					c.applyHTTPTimeouts(&httpRouteSource.source, backend, rule.Timeouts)
+				}
+			}
+		}
+	}
+}
+
+func (c *converter) syncGRPCRouteGateway(grpcRouteSource *grpcRouteSource, gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
+	for _, listener := range gatewaySource.spec.Listeners {
+		if sectionName != nil && *sectionName != listener.Name {
+			continue
+		}
+		if !c.checkProtocol(gatewaySource, &grpcRouteSource.source, listener, gatewayv1.HTTPProtocolType, gatewayv1.HTTPSProtocolType) {
+			continue
+		}
+		if err := c.checkListenerAllowed(gatewaySource, &grpcRouteSource.source, &listener); err != nil {
+			c.logger.Warn("skipping attachment of %s to %s listener '%s': %s",
+				grpcRouteSource, gatewaySource, listener.Name, err)
+			parent.reject(err)
+			continue
+		}
+		hostnames := c.filterHostnames(listener.Hostname, grpcRouteSource.spec.Hostnames)
+		if len(hostnames) == 0 {
+			c.logger.Warn("skipping attachment of %s to %s listener '%s': no intersecting hostname",
+				grpcRouteSource, gatewaySource, listener.Name)
+			parent.rejectHostname()
+			continue
+		}
+		parent.attach(&listener)
+		for index, rule := range grpcRouteSource.spec.Rules {
+			backendRefs := make([]gatewayv1.BackendRef, len(rule.BackendRefs))
+			for i := range rule.BackendRefs {
+				// TODO implement GRPCBackendRef.Filters
+				backendRefs[i] = rule.BackendRefs[i].BackendRef
+			}
+			filters := c.readHTTPFilters(&grpcRouteSource.source, grpcFiltersToHTTP(rule.Filters))
+			if filters.mirror != nil {
+				c.logger.Warn("ignoring unsupported filter '%s' on %s", gatewayv1.HTTPRouteFilterRequestMirror, &grpcRouteSource.source)
+			}
+			backend, services := c.createBackend(&grpcRouteSource.source, fmt.Sprintf("_grpcrule%d", index), false, backendRefs)
 			if backend != nil {
-				hostnames := c.filterHostnames(listener.Hostname, httpRouteSource.spec.Hostnames)
-				pathLinks := c.createHTTPHosts(gatewaySource, &httpRouteSource.source, &listener, hostnames, rule.Matches, backend)
+				pathLinks := c.createHTTPHosts(gatewaySource, &grpcRouteSource.source, &listener, hostnames, grpcMatchesToHTTP(rule.Matches), filters, backend)
 				if c.ann != nil {
 					c.ann.ReadAnnotations(backend, services, pathLinks)
 				}
+				// gRPC needs HTTP/2 on the backend side, unless the service is annotated
+				// with another protocol, eg `backend-protocol: grpcs` in order to use TLS.
+				if !c.hasServiceAnnotation(services, ingtypes.BackBackendProtocol) {
+					backend.Server.Protocol = "h2"
+				}
+				c.applyBackendTLS(gatewaySource, backend)
 			}
 		}
 	}
 }
 
-func (c *converter) syncTLSRouteGateway(tlsRouteSource *tlsRouteSource, gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName) {
+func (c *converter) syncTLSRouteGateway(tlsRouteSource *tlsRouteSource, gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
 	for _, listener := range gatewaySource.spec.Listeners {
 		if sectionName != nil && *sectionName != listener.Name {
 			continue
@@ -395,13 +571,21 @@ func (c *converter) syncTLSRouteGateway(tlsRouteSource *tlsRouteSource, gatewayS
 		if err := c.checkListenerAllowed(gatewaySource, &tlsRouteSource.source, &listener); err != nil {
 			c.logger.Warn("skipping attachment of %s to %s listener '%s': %s",
 				tlsRouteSource, gatewaySource, listener.Name, err)
+			parent.reject(err)
+			continue
+		}
+		hostnames := c.filterHostnames(listener.Hostname, tlsRouteSource.spec.Hostnames)
+		if len(hostnames) == 0 {
+			c.logger.Warn("skipping attachment of %s to %s listener '%s': no intersecting hostname",
+				tlsRouteSource, gatewaySource, listener.Name)
+			parent.rejectHostname()
 			continue
 		}
+		parent.attach(&listener)
 		for index, rule := range tlsRouteSource.spec.Rules {
 			// TODO implement rule.Filters
 			backend, services := c.createBackend(&tlsRouteSource.source, fmt.Sprintf("_tlsrule%d", index), true, rule.BackendRefs)
 			if backend != nil {
-				hostnames := c.filterHostnames(listener.Hostname, tlsRouteSource.spec.Hostnames)
 				pathLinks := c.createTLSHosts(gatewaySource, tlsRouteSource, &listener, hostnames, backend)
 				if c.ann != nil {
 					c.ann.ReadAnnotations(backend, services, pathLinks)
@@ -411,7 +595,7 @@ func (c *converter) syncTLSRouteGateway(tlsRouteSource *tlsRouteSource, gatewayS
 	}
 }
 
-func (c *converter) syncTCPRouteGateway(tcpRouteSource *tcpRouteSource, gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName) {
+func (c *converter) syncTCPRouteGateway(tcpRouteSource *tcpRouteSource, gatewaySource *gatewaySource, sectionName *gatewayv1.SectionName, parent *routeParentStatus) {
 	for _, listener := range gatewaySource.spec.Listeners {
 		if sectionName != nil && *sectionName != listener.Name {
 			continue
@@ -422,8 +606,10 @@ func (c *converter) syncTCPRouteGateway(tcpRouteSource *tcpRouteSource, gatewayS
 		if err := c.checkListenerAllowed(gatewaySource, &tcpRouteSource.source, &listener); err != nil {
 			c.logger.Warn("skipping attachment of %s to %s listener '%s': %s",
 				tcpRouteSource, gatewaySource, listener.Name, err)
+			parent.reject(err)
 			continue
 		}
+		parent.attach(&listener)
 		for index, rule := range tcpRouteSource.spec.Rules {
 			// TODO implement rule.Filters
 			backend, services := c.createBackend(&tcpRouteSource.source, fmt.Sprintf("_tcprule%d", index), true, rule.BackendRefs)
@@ -512,6 +698,8 @@ func (c *converter) checkListenerAllowedNamespace(gatewaySource *gatewaySource,
 	return errRouteNotAllowed
 }
 
+// filterHostnames returns the intersection between the listener hostname and the route hostnames.
+// An empty list means that no hostname matches, and the route should not be attached to the listener.
 func (c *converter) filterHostnames(listenerHostname *gatewayv1.Hostname, routeHostnames []gatewayv1.Hostname) []gatewayv1.Hostname {
 	if listenerHostname == nil || *listenerHostname == "" || *listenerHostname == "*" {
 		if len(routeHostnames) == 0 {
@@ -519,8 +707,46 @@ func (c *converter) filterHostnames(listenerHostname *gatewayv1.Hostname, routeH
 		}
 		return routeHostnames
 	}
-	// TODO implement proper filter to wildcard based listenerHostnames -- `*.domain.local`
-	return []gatewayv1.Hostname{*listenerHostname}
+	if len(routeHostnames) == 0 {
+		return []gatewayv1.Hostname{*listenerHostname}
+	}
+	var hostnames []gatewayv1.Hostname
+	for _, routeHostname := range routeHostnames {
+		hostname := intersectHostname(*listenerHostname, routeHostname)
+		if hostname != "" && !slices.Contains(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+// intersectHostname returns the most specific hostname matched by both h1 and h2,
+// or an empty string if they don't match. Both hostnames can be a wildcard.
+func intersectHostname(h1, h2 gatewayv1.Hostname) gatewayv1.Hostname {
+	switch {
+	case h1 == h2:
+		return h1
+	case h1 == "*":
+		return h2
+	case h2 == "*":
+		return h1
+	case wildcardMatches(h1, h2):
+		return h2
+	case wildcardMatches(h2, h1):
+		return h1
+	}
+	return ""
+}
+
+// wildcardMatches checks if a `*.domain` based wildcard matches hostname, which can
+// also be a more specific wildcard. The wildcard matches one or more DNS labels, hosts
+// created by the gateway converter configure haproxy to match them the same way.
+func wildcardMatches(wildcard, hostname gatewayv1.Hostname) bool {
+	if !strings.HasPrefix(string(wildcard), "*.") {
+		return false
+	}
+	suffix := string(wildcard[1:])
+	return len(hostname) > len(suffix) && strings.HasSuffix(string(hostname), suffix)
 }
 
 func (c *converter) createBackend(routeSource *source, index string, modeTCP bool, backendRefs []gatewayv1.BackendRef) (*hatypes.Backend, []*api.Service) {
@@ -536,6 +762,7 @@ func (c *converter) createBackend(routeSource *source, index string, modeTCP boo
 	}
 	var backends []backend
 	var svclist []*api.Service
+	route := c.status.route(routeSource)
 	for _, back := range backendRefs {
 		if back.Port == nil {
 			// TODO implement nil back.Port
@@ -543,8 +770,17 @@ func (c *converter) createBackend(routeSource *source, index string, modeTCP boo
 		}
 		// TODO implement back.Group
 		// TODO implement back.Kind
-		// TODO implement back.Namespace
-		svcName := routeSource.namespace + "/" + string(back.Name)
+		namespace := routeSource.namespace
+		if back.Namespace != nil && *back.Namespace != "" {
+			namespace = string(*back.Namespace)
+		}
+		if namespace != routeSource.namespace && !c.checkReferenceGrant(routeSource, "Service", namespace, back.Name) {
+			err := fmt.Errorf("%w to service '%s/%s'", errRefNotPermitted, namespace, back.Name)
+			c.logger.Warn("skipping service '%s' on %s: %v", back.Name, routeSource, err)
+			route.backendRefError(gatewayv1.RouteReasonRefNotPermitted, err)
+			continue
+		}
+		svcName := namespace + "/" + string(back.Name)
 		c.tracker.TrackRefName([]convtypes.TrackingRef{
 			{Context: convtypes.ResourceService, UniqueName: svcName},
 			{Context: convtypes.ResourceEndpoints, UniqueName: svcName},
@@ -552,6 +788,7 @@ func (c *converter) createBackend(routeSource *source, index string, modeTCP boo
 		svc, err := c.cache.GetService("", svcName)
 		if err != nil {
 			c.logger.Warn("skipping service '%s' on %s: %v", back.Name, routeSource, err)
+			route.backendRefError(gatewayv1.RouteReasonBackendNotFound, fmt.Errorf("service '%s' not found", back.Name))
 			continue
 		}
 		svclist = append(svclist, svc)
@@ -559,6 +796,7 @@ func (c *converter) createBackend(routeSource *source, index string, modeTCP boo
 		svcport := convutils.FindServicePort(svc, portStr)
 		if svcport == nil {
 			c.logger.Warn("skipping service '%s' on %s: port '%s' not found", back.Name, routeSource, portStr)
+			route.backendRefError(gatewayv1.RouteReasonBackendNotFound, fmt.Errorf("port '%s' not found on service '%s'", portStr, back.Name))
 			continue
 		}
 		epReady, _, err := convutils.CreateEndpoints(c.cache, svc, svcport)
@@ -598,10 +836,360 @@ func (c *converter) createBackend(routeSource *source, index string, modeTCP boo
 			ep.Weight = cl[i].Weight
 		}
 	}
+	c.registerBackendTLS(routeSource, habackend, svclist)
 	return habackend, svclist
 }
 
-func (c *converter) createHTTPHosts(gatewaySource *gatewaySource, routeSource *source, listener *gatewayv1.Listener, hostnames []gatewayv1.Hostname, matches []gatewayv1.HTTPRouteMatch, backend *hatypes.Backend) (pathLinks []*hatypes.PathLink) {
+// timeoutDisabled is used when a timeout is disabled with a zero duration,
+// it is the longest timeout supported by HAProxy.
+const timeoutDisabled = "24d"
+
+// applyHTTPTimeouts configures the backend timeouts based on HTTPRoute's rule timeouts. It should
+// be called after reading the Service annotations, so the rule timeouts have precedence.
+//
+// HAProxy does not have a timeout for the whole transaction: request bounds the time waiting in the
+// queue and, if backendRequest is missing, the inactivity of the backend as well, so the transaction
+// can take longer than request. Every rule has its own backend, so the timeouts are not shared.
+func (c *converter) applyHTTPTimeouts(routeSource *source, backend *hatypes.Backend, timeouts *gatewayv1.HTTPRouteTimeouts) {
+	if timeouts == nil {
+		return
+	}
+	request, requestDuration, err := readDuration(timeouts.Request)
+	if err != nil {
+		c.logger.Warn("ignoring request timeout on %s: %v", routeSource, err)
+	}
+	backendRequest, backendRequestDuration, err := readDuration(timeouts.BackendRequest)
+	if err != nil {
+		c.logger.Warn("ignoring backendRequest timeout on %s: %v", routeSource, err)
+	}
+	if request == "" && backendRequest == "" {
+		return
+	}
+	// a zero duration disables the timeout, so it is longer than any other one
+	if request != "" && backendRequest != "" && requestDuration > 0 &&
+		(backendRequestDuration == 0 || backendRequestDuration > requestDuration) {
+		err := fmt.Errorf("backendRequest timeout cannot be longer than request timeout")
+		c.logger.Warn("ignoring timeouts on %s: %v", routeSource, err)
+		c.status.route(routeSource).unsupportedValue(err)
+		return
+	}
+	if request != "" {
+		backend.Timeout.Queue = request
+	}
+	if backendRequest != "" {
+		backend.Timeout.Server = backendRequest
+	} else {
+		// the whole transaction has a timeout, so the backend request does as well
+		backend.Timeout.Server = request
+	}
+}
+
+// readDuration converts a Gateway API duration, like `1m30s`, to the
+// HAProxy time format, which does not support composite durations.
+func readDuration(duration *gatewayv1.Duration) (string, time.Duration, error) {
+	if duration == nil {
+		return "", 0, nil
+	}
+	d, err := time.ParseDuration(string(*duration))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid duration '%s'", *duration)
+	}
+	if d < 0 {
+		return "", 0, fmt.Errorf("negative duration '%s'", *duration)
+	}
+	if d == 0 {
+		return timeoutDisabled, 0, nil
+	}
+	ms := d.Milliseconds()
+	if ms%1000 == 0 {
+		return fmt.Sprintf("%ds", ms/1000), d, nil
+	}
+	return fmt.Sprintf("%dms", ms), d, nil
+}
+
+// hasMirrorAgent returns true if the endpoints of the mirror agent, used by the
+// RequestMirror filter, are configured. The global config is read directly
+// because the haproxy model is only updated by the ingress converter.
+func (c *converter) hasMirrorAgent() bool {
+	globalConfig := c.changed.GlobalConfigMapDataNew
+	if globalConfig == nil {
+		globalConfig = c.changed.GlobalConfigMapDataCur
+	}
+	return strings.TrimSpace(globalConfig[ingtypes.GlobalMirrorAgentEndpoints]) != ""
+}
+
+// httpFilters holds the per path configuration built from HTTPRoute's rule filters.
+type httpFilters struct {
+	reqHeaders hatypes.HTTPHeaderModifier
+	resHeaders hatypes.HTTPHeaderModifier
+	redirect   *gatewayv1.HTTPRequestRedirectFilter
+	rewrite    *gatewayv1.HTTPURLRewriteFilter
+	mirror     *gatewayv1.HTTPRequestMirrorFilter
+	// mirrorBackend is the haproxy backend of the mirror filter, created by the route sync
+	mirrorBackend *hatypes.Backend
+}
+
+func (c *converter) readHTTPFilters(routeSource *source, filters []gatewayv1.HTTPRouteFilter) *httpFilters {
+	haFilters := &httpFilters{}
+	var hasReqHeaders, hasResHeaders bool
+	for _, filter := range filters {
+		switch filter.Type {
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+			if filter.RequestHeaderModifier == nil || hasReqHeaders {
+				c.logger.Warn("ignoring invalid or duplicated filter '%s' on %s", filter.Type, routeSource)
+				continue
+			}
+			hasReqHeaders = true
+			haFilters.reqHeaders = readHeaderFilter(filter.RequestHeaderModifier)
+		case gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+			if filter.ResponseHeaderModifier == nil || hasResHeaders {
+				c.logger.Warn("ignoring invalid or duplicated filter '%s' on %s", filter.Type, routeSource)
+				continue
+			}
+			hasResHeaders = true
+			haFilters.resHeaders = readHeaderFilter(filter.ResponseHeaderModifier)
+		case gatewayv1.HTTPRouteFilterRequestRedirect:
+			if filter.RequestRedirect == nil || haFilters.redirect != nil {
+				c.logger.Warn("ignoring invalid or duplicated filter '%s' on %s", filter.Type, routeSource)
+				continue
+			}
+			haFilters.redirect = filter.RequestRedirect
+		case gatewayv1.HTTPRouteFilterURLRewrite:
+			if filter.URLRewrite == nil || haFilters.rewrite != nil {
+				c.logger.Warn("ignoring invalid or duplicated filter '%s' on %s", filter.Type, routeSource)
+				continue
+			}
+			haFilters.rewrite = filter.URLRewrite
+		case gatewayv1.HTTPRouteFilterRequestMirror:
+			if filter.RequestMirror == nil || haFilters.mirror != nil {
+				c.logger.Warn("ignoring invalid or duplicated filter '%s' on %s", filter.Type, routeSource)
+				continue
+			}
+			haFilters.mirror = filter.RequestMirror
+		default:
+			// TODO implement ExtensionRef
+			c.logger.Warn("ignoring unsupported filter '%s' on %s", filter.Type, routeSource)
+		}
+	}
+	if haFilters.redirect != nil && haFilters.rewrite != nil {
+		c.logger.Warn("ignoring filter '%s' on %s: cannot be used along with '%s'",
+			gatewayv1.HTTPRouteFilterURLRewrite, routeSource, gatewayv1.HTTPRouteFilterRequestRedirect)
+		haFilters.rewrite = nil
+	}
+	if haFilters.rewrite != nil && haFilters.rewrite.Hostname != nil {
+		// hostname rewrite is a request header modifier whose header name is `Host`
+		reqHeaders := &haFilters.reqHeaders
+		reqHeaders.Set = slices.DeleteFunc(reqHeaders.Set, func(h hatypes.HTTPHeader) bool {
+			return strings.EqualFold(h.Name, "host")
+		})
+		reqHeaders.Set = append(reqHeaders.Set, hatypes.HTTPHeader{Name: "Host", Value: string(*haFilters.rewrite.Hostname)})
+	}
+	return haFilters
+}
+
+// grpcFiltersToHTTP converts GRPCRoute filters to their HTTPRoute counterpart,
+// both share the same filter types and configuration structs.
+func grpcFiltersToHTTP(filters []gatewayv1alpha2.GRPCRouteFilter) []gatewayv1.HTTPRouteFilter {
+	httpFilters := make([]gatewayv1.HTTPRouteFilter, len(filters))
+	for i, filter := range filters {
+		httpFilters[i] = gatewayv1.HTTPRouteFilter{
+			Type:                   gatewayv1.HTTPRouteFilterType(filter.Type),
+			RequestHeaderModifier:  filter.RequestHeaderModifier,
+			ResponseHeaderModifier: filter.ResponseHeaderModifier,
+			RequestMirror:          filter.RequestMirror,
+			ExtensionRef:           filter.ExtensionRef,
+		}
+	}
+	return httpFilters
+}
+
+// grpcMatchesToHTTP converts GRPCRoute matches to their HTTPRoute counterpart.
+// gRPC calls are HTTP/2 requests whose path is `/<service>/<method>`.
+func grpcMatchesToHTTP(matches []gatewayv1alpha2.GRPCRouteMatch) []gatewayv1.HTTPRouteMatch {
+	httpMatches := make([]gatewayv1.HTTPRouteMatch, len(matches))
+	for i, match := range matches {
+		httpMatch := &httpMatches[i]
+		if match.Method != nil {
+			httpMatch.Path = grpcMethodToPathMatch(match.Method)
+		}
+		for _, header := range match.Headers {
+			httpMatch.Headers = append(httpMatch.Headers, gatewayv1.HTTPHeaderMatch{
+				Type:  header.Type,
+				Name:  gatewayv1.HTTPHeaderName(header.Name),
+				Value: header.Value,
+			})
+		}
+	}
+	return httpMatches
+}
+
+func grpcMethodToPathMatch(method *gatewayv1alpha2.GRPCMethodMatch) *gatewayv1.HTTPPathMatch {
+	pathMatch := func(match gatewayv1.PathMatchType, path string) *gatewayv1.HTTPPathMatch {
+		return &gatewayv1.HTTPPathMatch{Type: &match, Value: &path}
+	}
+	service, methodName := method.Service, method.Method
+	if method.Type != nil && *method.Type == gatewayv1alpha2.GRPCMethodMatchRegularExpression {
+		serviceRegex, methodRegex := "[^/]+", "[^/]+"
+		if service != nil {
+			serviceRegex = *service
+		}
+		if methodName != nil {
+			methodRegex = *methodName
+		}
+		return pathMatch(gatewayv1.PathMatchRegularExpression, "^/("+serviceRegex+")/("+methodRegex+")$")
+	}
+	switch {
+	case service != nil && methodName != nil:
+		return pathMatch(gatewayv1.PathMatchExact, "/"+*service+"/"+*methodName)
+	case service != nil:
+		return pathMatch(gatewayv1.PathMatchPathPrefix, "/"+*service)
+	case methodName != nil:
+		return pathMatch(gatewayv1.PathMatchRegularExpression, "^/[^/]+/"+regexp.QuoteMeta(*methodName)+"$")
+	}
+	// neither service nor method, spec doesn't allow it, match everything
+	return nil
+}
+
+// hasServiceAnnotation checks if any of the services declares the configuration key
+// as an annotation, using any of the configured annotation prefixes.
+func (c *converter) hasServiceAnnotation(services []*api.Service, key string) bool {
+	for _, svc := range services {
+		for _, prefix := range c.options.AnnotationPrefix {
+			if _, found := svc.Annotations[prefix+"/"+key]; found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func readHeaderFilter(filter *gatewayv1.HTTPHeaderFilter) hatypes.HTTPHeaderModifier {
+	var modifier hatypes.HTTPHeaderModifier
+	for _, header := range filter.Set {
+		modifier.Set = append(modifier.Set, hatypes.HTTPHeader{Name: string(header.Name), Value: header.Value})
+	}
+	for _, header := range filter.Add {
+		modifier.Add = append(modifier.Add, hatypes.HTTPHeader{Name: string(header.Name), Value: header.Value})
+	}
+	modifier.Remove = append(modifier.Remove, filter.Remove...)
+	return modifier
+}
+
+func (c *converter) applyHTTPFilters(routeSource *source, listener *gatewayv1.Listener, path *hatypes.Path, filters *httpFilters) {
+	path.ReqHeaders = filters.reqHeaders
+	path.ResHeaders = filters.resHeaders
+	if filters.mirrorBackend != nil {
+		// RequestMirror filter doesn't have a percentage field in the current API version
+		path.Mirror.BackendID = filters.mirrorBackend.ID
+		path.Mirror.Percent = 100
+	}
+	if rewrite := filters.rewrite; rewrite != nil && rewrite.Path != nil {
+		switch rewrite.Path.Type {
+		case gatewayv1.FullPathHTTPPathModifier:
+			if rewrite.Path.ReplaceFullPath != nil {
+				path.RewriteURL = *rewrite.Path.ReplaceFullPath
+				path.RewriteFull = true
+			}
+		case gatewayv1.PrefixMatchHTTPPathModifier:
+			if path.Match() != hatypes.MatchPrefix {
+				c.logger.Warn("ignoring path rewrite of filter '%s' on %s: prefix replacement needs a PathPrefix match",
+					gatewayv1.HTTPRouteFilterURLRewrite, routeSource)
+			} else if rewrite.Path.ReplacePrefixMatch != nil {
+				path.RewriteURL = *rewrite.Path.ReplacePrefixMatch
+			}
+		}
+	}
+}
+
+// addRedirectLink adds a path whose requests are redirected by a RequestRedirect filter.
+// A nil path is returned if the filter cannot be applied.
+func (c *converter) addRedirectLink(routeSource *source, listener *gatewayv1.Listener, h *hatypes.Host, pathlink *hatypes.PathLink, redirect *gatewayv1.HTTPRequestRedirectFilter) *hatypes.Path {
+	location, err := buildRedirectLocation(listener, pathlink, redirect)
+	if err != nil {
+		c.logger.Warn("ignoring filter '%s' on %s: %v", gatewayv1.HTTPRouteFilterRequestRedirect, routeSource, err)
+		return nil
+	}
+	path := h.AddLinkRedirect(pathlink, location)
+	path.RedirToFormat = true
+	path.RedirToCode = 302
+	if redirect.StatusCode != nil {
+		path.RedirToCode = *redirect.StatusCode
+	}
+	return path
+}
+
+// buildRedirectLocation builds the haproxy's log-format based location of a RequestRedirect filter.
+// Spec says that missing fields should be filled with the values of the incoming request.
+func buildRedirectLocation(listener *gatewayv1.Listener, pathlink *hatypes.PathLink, redirect *gatewayv1.HTTPRequestRedirectFilter) (string, error) {
+	scheme := "http"
+	if listener.Protocol == gatewayv1.HTTPSProtocolType {
+		scheme = "https"
+	}
+	port := int(listener.Port)
+	if redirect.Scheme != nil {
+		scheme = *redirect.Scheme
+		// spec says that the well-known port of the scheme should be used
+		port = 0
+	}
+	if redirect.Port != nil {
+		port = int(*redirect.Port)
+	}
+	if (scheme == "http" && port == 80) || (scheme == "https" && port == 443) {
+		port = 0
+	}
+	var hostname string
+	if redirect.Hostname != nil {
+		hostname = escapeLogFormat(string(*redirect.Hostname))
+	} else if pathlink.IsDefaultHost() || strings.HasPrefix(pathlink.Hostname(), "*.") {
+		hostname = "%[req.hdr(host),field(1,:)]"
+	} else {
+		hostname = escapeLogFormat(pathlink.Hostname())
+	}
+	location := scheme + "://" + hostname
+	if port > 0 {
+		location += ":" + strconv.Itoa(port)
+	}
+	if redirect.Path == nil {
+		return location + "%[pathq]", nil
+	}
+	switch redirect.Path.Type {
+	case gatewayv1.FullPathHTTPPathModifier:
+		if redirect.Path.ReplaceFullPath == nil {
+			return "", fmt.Errorf("missing full path replacement")
+		}
+		return location + escapeLogFormat(*redirect.Path.ReplaceFullPath), nil
+	case gatewayv1.PrefixMatchHTTPPathModifier:
+		if redirect.Path.ReplacePrefixMatch == nil {
+			return "", fmt.Errorf("missing prefix replacement")
+		}
+		if pathlink.Match() != hatypes.MatchPrefix {
+			return "", fmt.Errorf("prefix replacement needs a PathPrefix match")
+		}
+		prefix := strings.TrimSuffix(pathlink.Path(), "/")
+		replace := strings.TrimSuffix(*redirect.Path.ReplacePrefixMatch, "/")
+		if prefix == replace {
+			return location + "%[pathq]", nil
+		}
+		// the matching prefix is removed by its length, the remaining
+		// path is empty, or starts with a slash or a query string.
+		remaining := "pathq"
+		if prefix != "" {
+			remaining += ",bytes(" + strconv.Itoa(len(prefix)) + ")"
+		}
+		if replace == "" {
+			// replacing with "/", which should be the only leading slash of the new path
+			return location + "%[" + remaining + ",regsub(^/*,/)]", nil
+		}
+		return location + escapeLogFormat(replace) + "%[" + remaining + "]", nil
+	}
+	return "", fmt.Errorf("unsupported path modifier type '%s'", redirect.Path.Type)
+}
+
+func escapeLogFormat(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+func (c *converter) createHTTPHosts(gatewaySource *gatewaySource, routeSource *source, listener *gatewayv1.Listener, hostnames []gatewayv1.Hostname, matches []gatewayv1.HTTPRouteMatch, filters *httpFilters, backend *hatypes.Backend) (pathLinks []*hatypes.PathLink) {
 	if len(matches) == 0 {
 		matches = []gatewayv1.HTTPRouteMatch{{}}
 	}
@@ -640,6 +1228,7 @@ func (c *converter) createHTTPHosts(gatewaySource *gatewaySource, routeSource *s
 				hstr = hatypes.DefaultHost
 			}
 			h := frontend.AcquireHost(hstr)
+			h.WildcardSuffix = true
 			pathlink := hatypes.CreatePathLink(path, haMatch).WithHTTPHost(h)
 			var haheaders hatypes.HTTPHeaderMatch
 			for _, header := range match.Headers {
@@ -650,6 +1239,18 @@ func (c *converter) createHTTPHosts(gatewaySource *gatewaySource, routeSource *s
 				})
 			}
 			pathlink.WithHeadersMatch(haheaders)
+			if match.Method != nil {
+				pathlink.WithMethodMatch(string(*match.Method))
+			}
+			var haquery hatypes.HTTPQueryMatch
+			for _, query := range match.QueryParams {
+				haquery = append(haquery, hatypes.HTTPMatch{
+					Name:  string(query.Name),
+					Value: query.Value,
+					Regex: query.Type != nil && *query.Type == gatewayv1.QueryParamMatchRegularExpression,
+				})
+			}
+			pathlink.WithQueryMatch(haquery)
 			if h.FindPathWithLink(pathlink) != nil {
 				c.logger.Warn("skipping redeclared path '%s' type '%s' on %s", path, haMatch, routeSource)
 				continue
@@ -657,7 +1258,20 @@ func (c *converter) createHTTPHosts(gatewaySource *gatewaySource, routeSource *s
 			c.tracker.TrackRefName([]convtypes.TrackingRef{
 				{Context: convtypes.ResourceHAHostname, UniqueName: h.Hostname},
 			}, convtypes.ResourceGateway, "gw")
-			h.AddLink(backend, pathlink)
+			var hapath *hatypes.Path
+			if filters.redirect != nil {
+				hapath = c.addRedirectLink(routeSource, listener, h, pathlink, filters.redirect)
+			}
+			if hapath == nil {
+				if backend == nil {
+					if len(h.Paths) == 0 {
+						frontend.RemoveAllHosts([]string{h.Hostname})
+					}
+					continue
+				}
+				hapath = h.AddLink(backend, pathlink)
+			}
+			c.applyHTTPFilters(routeSource, listener, hapath, filters)
 			pathLinks = append(pathLinks, pathlink)
 			if hostsTLS != nil {
 				hostsTLS[h.Hostname] = &h.TLS.TLSConfig
@@ -671,6 +1285,7 @@ func (c *converter) createHTTPHosts(gatewaySource *gatewaySource, routeSource *s
 			// avoid partial (i.e. broken) configuration by reverting all the added paths in the case of an error
 			frontend.RemoveAllLinks(pathLinks...)
 			c.logger.Warn("skipping certificate reference on %s listener '%s': %v", gatewaySource, listener.Name, err)
+			c.status.gateway(gatewaySource).certRefError(listener, err)
 			return nil
 		}
 	}
@@ -693,6 +1308,7 @@ func (c *converter) createTLSHosts(gatewaySource *gatewaySource, routeSource *tl
 		if h == nil {
 			h = f.AcquireHost(string(hostname))
 			h.SSLPassthrough = true
+			h.WildcardSuffix = true
 		}
 		link := hatypes.CreatePathLink("/", hatypes.MatchPrefix).WithHTTPHost(h)
 		if h.FindPathWithLink(link) != nil {
@@ -725,6 +1341,7 @@ func (c *converter) createTCPService(gatewaySource *gatewaySource, listener *gat
 			c.logger.Warn("skipping redeclared TCPService '%s'", hostname)
 			continue
 		}
+		tcphost.WildcardSuffix = true
 		c.tracker.TrackNames(convtypes.ResourceHAHostname, string(hostname), convtypes.ResourceGateway, "gw")
 		tcphost.Backend = backend.BackendID()
 		pathlinks = append(pathlinks, hatypes.CreatePathLink("/", hatypes.MatchExact).WithTCPHost(tcphost))
@@ -740,6 +1357,7 @@ func (c *converter) createTCPService(gatewaySource *gatewaySource, listener *gat
 			// avoid partial (i.e. broken) configuration by reverting all the added services in the case of an error
 			c.haproxy.TCPServices().RemoveAllLinks(pathlinks...)
 			c.logger.Warn("skipping certificate reference on %s listener '%s': %v", gatewaySource, listener.Name, err)
+			c.status.gateway(gatewaySource).certRefError(listener, err)
 			return nil
 		}
 	}
@@ -761,7 +1379,7 @@ func (c *converter) readCertRefs(source *gatewaySource, listener *gatewayv1.List
 	var defaultCrtFile *convtypes.CrtFile
 	for i := range certRefs {
 		certRef := certRefs[i]
-		crtFile, err := c.readCertRef(source.namespace, &certRef)
+		crtFile, err := c.readCertRef(source, &certRef)
 		if err != nil {
 			return err
 		}
@@ -792,14 +1410,51 @@ func (c *converter) readCertRefs(source *gatewaySource, listener *gatewayv1.List
 	return nil
 }
 
-func (c *converter) readCertRef(namespace string, certRef *gatewayv1.SecretObjectReference) (crtFile convtypes.CrtFile, err error) {
+func (c *converter) readCertRef(source *gatewaySource, certRef *gatewayv1.SecretObjectReference) (crtFile convtypes.CrtFile, err error) {
 	if certRef.Group != nil && *certRef.Group != "" && *certRef.Group != "core" {
 		return crtFile, fmt.Errorf("unsupported Group '%s', supported groups are 'core' and ''", *certRef.Group)
 	}
 	if certRef.Kind != nil && *certRef.Kind != "" && *certRef.Kind != "Secret" {
 		return crtFile, fmt.Errorf("unsupported Kind '%s', the only supported kind is 'Secret'", *certRef.Kind)
 	}
-	// TODO implement certRef.Namespace
-	return c.cache.GetTLSSecretPath(namespace, string(certRef.Name),
+	namespace := source.namespace
+	if certRef.Namespace != nil && *certRef.Namespace != "" {
+		namespace = string(*certRef.Namespace)
+	}
+	if namespace != source.namespace && !c.checkReferenceGrant(&source.source, "Secret", namespace, certRef.Name) {
+		return crtFile, fmt.Errorf("%w to secret '%s/%s'", errRefNotPermitted, namespace, certRef.Name)
+	}
+	// cross namespace permission was already checked, so the global cross namespace config doesn't apply
+	return c.cache.GetTLSSecretPath("", namespace+"/"+string(certRef.Name),
 		[]convtypes.TrackingRef{{Context: convtypes.ResourceGateway, UniqueName: "gw"}})
 }
+
+var errRefNotPermitted = fmt.Errorf("missing a ReferenceGrant allowing the cross namespace reference")
+
+// checkReferenceGrant checks if a ReferenceGrant in the target namespace allows
+// the source resource to reference a core resource of the provided kind and name.
+func (c *converter) checkReferenceGrant(from *source, toKind gatewayv1.Kind, toNamespace string, toName gatewayv1.ObjectName) bool {
+	if !c.options.HasRefGrantB1 {
+		return false
+	}
+	refGrants, err := c.cache.GetReferenceGrantList(toNamespace)
+	if err != nil {
+		c.logger.Warn("error reading referenceGrant list: %v", err)
+		return false
+	}
+	for _, refGrant := range refGrants {
+		c.tracker.TrackRefName([]convtypes.TrackingRef{
+			{Context: convtypes.ResourceReferenceGrant, UniqueName: refGrant.Namespace + "/" + refGrant.Name},
+		}, convtypes.ResourceGateway, "gw")
+		fromAllowed := slices.ContainsFunc(refGrant.Spec.From, func(f gatewayv1beta1.ReferenceGrantFrom) bool {
+			return f.Group == gatewayGroup && f.Kind == gatewayv1.Kind(from.kind) && string(f.Namespace) == from.namespace
+		})
+		toAllowed := slices.ContainsFunc(refGrant.Spec.To, func(t gatewayv1beta1.ReferenceGrantTo) bool {
+			return (t.Group == "" || t.Group == "core") && t.Kind == toKind && (t.Name == nil || *t.Name == "" || *t.Name == toName)
+		})
+		if fromAllowed && toAllowed {
+			return true
+		}
+	}
+	return false
+}
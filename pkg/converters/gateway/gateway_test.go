@@ -30,6 +30,7 @@ import (
 	"k8s.io/utils/ptr"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 	gwapischeme "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/gateway"
@@ -50,6 +51,9 @@ type testCaseSync struct {
 	expHosts       string
 	expTCPServices string
 	expBackends    string
+	expServerTLS   string
+	expTimeouts    string
+	expStatus      string
 	expLogging     string
 }
 
@@ -349,6 +353,147 @@ paths:
 	})
 }
 
+func TestSyncHTTPRouteHostnames(t *testing.T) {
+	defaultBackend := `
+- id: default_web__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`
+	hostsFor := func(hostnames ...string) string {
+		var hosts string
+		for _, h := range hostnames {
+			hosts += `
+- hostname: ` + h + `
+  paths:
+  - path: /
+    match: prefix
+    backend: default_web__rule0`
+		}
+		return hosts
+	}
+	testCases := []struct {
+		id             string
+		listenerHost   string
+		routeHosts     []gatewayv1.Hostname
+		expDefaultHost string
+		expHosts       string
+		expLogging     string
+	}{
+		{
+			id:           "listener-exact-route-missing",
+			listenerHost: "app.example.com",
+			expHosts:     hostsFor("app.example.com"),
+		},
+		{
+			id:           "listener-exact-route-exact",
+			listenerHost: "app.example.com",
+			routeHosts:   []gatewayv1.Hostname{"app.example.com", "other.example.com"},
+			expHosts:     hostsFor("app.example.com"),
+		},
+		{
+			id:           "listener-exact-route-wildcard",
+			listenerHost: "app.example.com",
+			routeHosts:   []gatewayv1.Hostname{"*.example.com"},
+			expHosts:     hostsFor("app.example.com"),
+		},
+		{
+			id:           "listener-wildcard-route-exact",
+			listenerHost: "*.example.com",
+			routeHosts:   []gatewayv1.Hostname{"app1.example.com", "app2.sub.example.com", "example.com", "app.example.org"},
+			expHosts:     hostsFor("app1.example.com", "app2.sub.example.com"),
+		},
+		{
+			id:           "listener-wildcard-route-exact-deep",
+			listenerHost: "*.example.com",
+			routeHosts:   []gatewayv1.Hostname{"app.a.b.c.example.com", "app.a.b.c.example.org"},
+			expHosts:     hostsFor("app.a.b.c.example.com"),
+		},
+		{
+			id:           "listener-wildcard-route-wildcard-1",
+			listenerHost: "*.example.com",
+			routeHosts:   []gatewayv1.Hostname{"*.example.com"},
+			expHosts:     hostsFor("'*.example.com'"),
+		},
+		{
+			id:           "listener-wildcard-route-wildcard-2",
+			listenerHost: "*.example.com",
+			routeHosts:   []gatewayv1.Hostname{"*.sub.example.com"},
+			expHosts:     hostsFor("'*.sub.example.com'"),
+		},
+		{
+			id:           "listener-wildcard-route-wildcard-3",
+			listenerHost: "*.sub.example.com",
+			routeHosts:   []gatewayv1.Hostname{"*.example.com"},
+			expHosts:     hostsFor("'*.sub.example.com'"),
+		},
+		{
+			id:           "listener-wildcard-route-wildcard-deep",
+			listenerHost: "*.example.com",
+			routeHosts:   []gatewayv1.Hostname{"*.a.b.example.com", "*.a.b.example.org"},
+			expHosts:     hostsFor("'*.a.b.example.com'"),
+		},
+		{
+			id:           "listener-wildcard-deep-route-wildcard",
+			listenerHost: "*.a.b.example.com",
+			routeHosts:   []gatewayv1.Hostname{"*.example.com", "*.b.example.com", "*.c.example.com"},
+			expHosts:     hostsFor("'*.a.b.example.com'"),
+		},
+		{
+			id:           "no-intersection-1",
+			listenerHost: "app.example.com",
+			routeHosts:   []gatewayv1.Hostname{"app.example.org", "*.sub.example.com"},
+			expLogging:   `WARN skipping attachment of HTTPRoute 'default/web' to Gateway 'default/web' listener 'l1': no intersecting hostname`,
+		},
+		{
+			id:           "no-intersection-2",
+			listenerHost: "*.example.com",
+			routeHosts:   []gatewayv1.Hostname{"example.com", "*.example.org"},
+			expLogging:   `WARN skipping attachment of HTTPRoute 'default/web' to Gateway 'default/web' listener 'l1': no intersecting hostname`,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.id, func(t *testing.T) {
+			c := setup(t)
+			g := c.createGateway1("default/web", "l1")
+			g.Spec.Listeners[0].Hostname = ptr.To(gatewayv1.Hostname(test.listenerHost))
+			r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+			r.Spec.Hostnames = test.routeHosts
+			c.createService1("default/echoserver", "8080", "172.17.0.11")
+			c.createConverter().Sync(true, &gatewayv1.Gateway{})
+
+			expAttached := "1"
+			expAccepted := "True(Accepted)"
+			expHosts := test.expHosts
+			expBackends := defaultBackend
+			if expHosts == "" {
+				expAttached = "0"
+				expAccepted = "False(NoMatchingListenerHostname)"
+				expHosts = "[]"
+				expBackends = "[]"
+			}
+			c.compareConfigDefaultHost(test.id, "[]")
+			c.compareConfigHosts(test.id, expHosts)
+			c.compareConfigBacks(test.id, expBackends)
+			for _, f := range c.hconfig.Frontends().Items() {
+				for _, h := range f.Hosts() {
+					if !h.WildcardSuffix {
+						t.Errorf("%s: host '%s' should match wildcards as a suffix", test.id, h.Hostname)
+					}
+				}
+			}
+			c.compareStatus(test.id, `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=`+expAttached+` kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=`+expAccepted+` ResolvedRefs=True(ResolvedRefs)
+`)
+			c.logger.CompareLoggingID(test.id, test.expLogging)
+		})
+	}
+}
+
 func TestSyncHTTPRouteTracking(t *testing.T) {
 	runTestSync(t, []testCaseSync{
 		{
@@ -558,75 +703,1444 @@ paths:
 	})
 }
 
-func TestSyncTCPRouteCore(t *testing.T) {
-	defaultBackend := `
-- id: default_pg__tcprule0
+func TestSyncHTTPRouteMatches(t *testing.T) {
+	runTestSync(t, []testCaseSync{
+		{
+			id: "method-and-query-match",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - matches:
+    - path:
+        value: /search
+      method: GET
+      queryParams:
+      - name: version
+        value: "2"
+      - name: lang
+        type: RegularExpression
+        value: ^(en|pt)$
+    backendRefs:
+    - name: echoserver
+      port: 8080
+  - matches:
+    - path:
+        value: /search
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /search
+  match: prefix
+  method: GET
+  query:
+  - name: version
+    value: "2"
+    regex: false
+  - name: lang
+    value: ^(en|pt)$
+    regex: true
+  backend: default_web__rule0
+- path: /search
+  match: prefix
+  backend: default_web__rule1
+`,
+			expBackends: `
+- id: default_web__rule0
   endpoints:
   - ip: 172.17.0.11
-    port: 15432
+    port: 8080
+    weight: 128
+- id: default_web__rule1
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`,
+		},
+	})
+}
+
+func TestSyncHTTPRouteTimeouts(t *testing.T) {
+	defaultHTTPHostConfig := `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+`
+	defaultBackendConfig := `
+- id: default_web__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
     weight: 128
-  modetcp: true
 `
 	runTestSync(t, []testCaseSync{
 		{
-			id: "minimum",
+			id: "no-timeouts",
 			config: func(c *testConfig) {
-				c.createGateway1("default/pg", "l1:5432")
-				c.createTCPRoute1("default/pg", "pg", "postgres:15432")
-				c.createService1("default/postgres", "15432", "172.17.0.11")
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
 			},
-			expTCPServices: `
-- backends: []
-  defaultbackend: default_pg__tcprule0
-  port: 5432
-  proxyprot: false
-  tls: []
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue= server=
 `,
-			expBackends: defaultBackend,
 		},
 		{
-			id: "missing-proto",
+			id: "request-timeout",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Timeouts = &gatewayv1.HTTPRouteTimeouts{Request: ptr.To(gatewayv1.Duration("1m30s"))}
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue=90s server=90s
+`,
+		},
+		{
+			id: "request-and-backend-timeout",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Timeouts = &gatewayv1.HTTPRouteTimeouts{
+					Request:        ptr.To(gatewayv1.Duration("10s")),
+					BackendRequest: ptr.To(gatewayv1.Duration("2500ms")),
+				}
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue=10s server=2500ms
+`,
+		},
+		{
+			id: "backend-timeout",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Timeouts = &gatewayv1.HTTPRouteTimeouts{BackendRequest: ptr.To(gatewayv1.Duration("1h"))}
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue= server=3600s
+`,
+		},
+		{
+			id: "disabled-timeout",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Timeouts = &gatewayv1.HTTPRouteTimeouts{
+					Request:        ptr.To(gatewayv1.Duration("0s")),
+					BackendRequest: ptr.To(gatewayv1.Duration("5s")),
+				}
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue=24d server=5s
+`,
+		},
+		{
+			id: "disabled-timeout-request-only",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Timeouts = &gatewayv1.HTTPRouteTimeouts{Request: ptr.To(gatewayv1.Duration("0s"))}
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue=24d server=24d
+`,
+		},
+		{
+			id: "disabled-backend-timeout",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Timeouts = &gatewayv1.HTTPRouteTimeouts{
+					Request:        ptr.To(gatewayv1.Duration("5s")),
+					BackendRequest: ptr.To(gatewayv1.Duration("0s")),
+				}
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue= server=
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=False(UnsupportedValue) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN ignoring timeouts on HTTPRoute 'default/web': backendRequest timeout cannot be longer than request timeout
+`,
+		},
+		{
+			id: "distinct-rules",
 			resConfig: []string{`
 apiVersion: gateway.networking.k8s.io/v1
-kind: Gateway
+kind: HTTPRoute
 metadata:
   name: web
   namespace: default
 spec:
-  gatewayClassName: haproxy
-  listeners:
-  - name: h1
+  parentRefs:
+  - name: web
+  rules:
+  - matches:
+    - path:
+        value: /app1
+    backendRefs:
+    - name: echoserver
+      port: 8080
+    timeouts:
+      request: 10s
+  - matches:
+    - path:
+        value: /app2
+    backendRefs:
+    - name: echoserver
+      port: 8080
+    timeouts:
+      request: 20s
+      backendRequest: 5s
 `},
 			config: func(c *testConfig) {
-				c.createTCPRoute1("default/web", "default/web", "echoserver:8080")
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
 			},
-			expLogging: `WARN missing protocol on Gateway 'default/web' listener 'h1' for TCPRoute 'default/web'`,
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /app2
+  match: prefix
+  backend: default_web__rule1
+- path: /app1
+  match: prefix
+  backend: default_web__rule0
+`,
+			expBackends: `
+- id: default_web__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+- id: default_web__rule1
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`,
+			expTimeouts: `
+default_web__rule0: http-request= queue=10s server=10s
+default_web__rule1: http-request= queue=20s server=5s
+`,
+		},
+		{
+			id: "backend-timeout-longer",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Timeouts = &gatewayv1.HTTPRouteTimeouts{
+					Request:        ptr.To(gatewayv1.Duration("5s")),
+					BackendRequest: ptr.To(gatewayv1.Duration("10s")),
+				}
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expTimeouts: `
+default_web__rule0: http-request= queue= server=
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=False(UnsupportedValue) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN ignoring timeouts on HTTPRoute 'default/web': backendRequest timeout cannot be longer than request timeout
+`,
+		},
+	})
+}
+
+func TestSyncHTTPRouteFilters(t *testing.T) {
+	defaultBackend := `
+- id: default_web__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`
+	runTestSync(t, []testCaseSync{
+		{
+			id: "header-modifier-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - filters:
+    - type: RequestHeaderModifier
+      requestHeaderModifier:
+        set:
+        - name: X-Set
+          value: set1
+        add:
+        - name: X-Add
+          value: add1
+        remove:
+        - X-Remove
+    - type: ResponseHeaderModifier
+      responseHeaderModifier:
+        set:
+        - name: X-Res-Set
+          value: set2
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+  reqheaders:
+    add:
+    - 'X-Add: add1'
+    set:
+    - 'X-Set: set1'
+    remove:
+    - X-Remove
+  resheaders:
+    set:
+    - 'X-Res-Set: set2'
+`,
+			expBackends: defaultBackend,
+		},
+		{
+			id: "header-modifier-duplicated-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - filters:
+    - type: ResponseHeaderModifier
+      responseHeaderModifier:
+        remove:
+        - Server
+    - type: ResponseHeaderModifier
+      responseHeaderModifier:
+        remove:
+        - X-Powered-By
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+  resheaders:
+    remove:
+    - Server
+`,
+			expBackends: defaultBackend,
+			expLogging: `
+WARN ignoring invalid or duplicated filter 'ResponseHeaderModifier' on HTTPRoute 'default/web'
+`,
+		},
+		{
+			id: "header-modifier-per-rule-1",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				r := c.createHTTPRoute2("default/web", "web", "echoserver:8080", "/app1")
+				r.Spec.Rules = append(r.Spec.Rules, *r.Spec.Rules[0].DeepCopy())
+				r.Spec.Rules[1].Matches[0].Path.Value = ptr.To("/app2")
+				r.Spec.Rules[1].Filters = []gatewayv1.HTTPRouteFilter{{
+					Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+					RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+						Add: []gatewayv1.HTTPHeader{{Name: "X-App", Value: "app2"}},
+					},
+				}}
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /app2
+  match: prefix
+  backend: default_web__rule1
+  reqheaders:
+    add:
+    - 'X-App: app2'
+- path: /app1
+  match: prefix
+  backend: default_web__rule0
+`,
+			expBackends: defaultBackend + `- id: default_web__rule1
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`,
+		},
+		{
+			id: "redirect-scheme-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  hostnames:
+  - domain.local
+  rules:
+  - filters:
+    - type: RequestRedirect
+      requestRedirect:
+        scheme: https
+        statusCode: 301
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1:80")
+			},
+			expHosts: `
+- hostname: domain.local
+  paths:
+  - path: /
+    match: prefix
+    redirect: 301 https://domain.local%[pathq]
+`,
+		},
+		{
+			id: "redirect-full-path-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - filters:
+    - type: RequestRedirect
+      requestRedirect:
+        path:
+          type: ReplaceFullPath
+          replaceFullPath: /new%20path
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  redirect: 302 http://%[req.hdr(host),field(1,:)]:8080/new%%20path
+`,
+			expBackends: defaultBackend,
+		},
+		{
+			id: "redirect-prefix-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  hostnames:
+  - domain.local
+  rules:
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /app
+    filters:
+    - type: RequestRedirect
+      requestRedirect:
+        hostname: other.local
+        scheme: https
+        port: 8443
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /other
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1:80")
+			},
+			expHosts: `
+- hostname: domain.local
+  paths:
+  - path: /app
+    match: prefix
+    redirect: 302 https://other.local:8443/other%[pathq,bytes(4)]
+`,
+		},
+		{
+			id: "redirect-prefix-exact-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - matches:
+    - path:
+        type: Exact
+        value: /app
+    filters:
+    - type: RequestRedirect
+      requestRedirect:
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /other
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1:80")
+			},
+			expLogging: `
+WARN ignoring filter 'RequestRedirect' on HTTPRoute 'default/web': prefix replacement needs a PathPrefix match
+`,
+		},
+		{
+			id: "redirect-prefix-escape-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /app.v1+
+    filters:
+    - type: RequestRedirect
+      requestRedirect:
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /new
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /old(1
+    filters:
+    - type: RequestRedirect
+      requestRedirect:
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1:80")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /old(1
+  match: prefix
+  redirect: 302 http://%[req.hdr(host),field(1,:)]%[pathq,bytes(6),regsub(^/*,/)]
+- path: /app.v1+
+  match: prefix
+  redirect: 302 http://%[req.hdr(host),field(1,:)]/new%[pathq,bytes(8)]
+`,
+		},
+		{
+			id: "redirect-prefix-escape-2",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /a,b
+    filters:
+    - type: RequestRedirect
+      requestRedirect:
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /new
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /app
+    filters:
+    - type: RequestRedirect
+      requestRedirect:
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /new)
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1:80")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /app
+  match: prefix
+  redirect: 302 http://%[req.hdr(host),field(1,:)]/new)%[pathq,bytes(4)]
+- path: /a,b
+  match: prefix
+  redirect: 302 http://%[req.hdr(host),field(1,:)]/new%[pathq,bytes(4)]
+`,
+		},
+		{
+			id: "rewrite-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /v1
+    filters:
+    - type: URLRewrite
+      urlRewrite:
+        hostname: other.local
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /v2
+    backendRefs:
+    - name: echoserver
+      port: 8080
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /old
+    filters:
+    - type: URLRewrite
+      urlRewrite:
+        path:
+          type: ReplaceFullPath
+          replaceFullPath: /new
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /v1
+  match: prefix
+  backend: default_web__rule0
+  reqheaders:
+    set:
+    - 'Host: other.local'
+  rewrite: prefix /v2
+- path: /old
+  match: prefix
+  backend: default_web__rule1
+  rewrite: path /new
+`,
+			expBackends: defaultBackend + `- id: default_web__rule1
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`,
+		},
+		{
+			id: "rewrite-prefix-regex-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - matches:
+    - path:
+        type: RegularExpression
+        value: /v[0-9]+
+    filters:
+    - type: URLRewrite
+      urlRewrite:
+        path:
+          type: ReplacePrefixMatch
+          replacePrefixMatch: /v2
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /v[0-9]+
+  match: regex
+  backend: default_web__rule0
+`,
+			expBackends: defaultBackend,
+			expLogging: `
+WARN ignoring path rewrite of filter 'URLRewrite' on HTTPRoute 'default/web': prefix replacement needs a PathPrefix match
+`,
+		},
+		{
+			id: "redirect-and-rewrite-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  hostnames:
+  - domain.local
+  rules:
+  - filters:
+    - type: RequestRedirect
+      requestRedirect:
+        scheme: https
+    - type: URLRewrite
+      urlRewrite:
+        hostname: other.local
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1:80")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expHosts: `
+- hostname: domain.local
+  paths:
+  - path: /
+    match: prefix
+    redirect: 302 https://domain.local%[pathq]
+`,
+			expBackends: defaultBackend,
+			expLogging: `
+WARN ignoring filter 'URLRewrite' on HTTPRoute 'default/web': cannot be used along with 'RequestRedirect'
+`,
+		},
+		{
+			id: "mirror-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - filters:
+    - type: RequestMirror
+      requestMirror:
+        backendRef:
+          name: shadow
+          port: 8080
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createService1("default/shadow", "8080", "172.17.0.12")
+				c.cache.Changed.GlobalConfigMapDataNew = map[string]string{"mirror-agent-endpoints": "127.0.0.1:12345"}
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+  mirror: default_web__rule0_mirror 100%
+`,
+			expBackends: defaultBackend + `- id: default_web__rule0_mirror
+  endpoints:
+  - ip: 172.17.0.12
+    port: 8080
+    weight: 128
+`,
+		},
+		{
+			id: "mirror-no-agent-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - filters:
+    - type: RequestMirror
+      requestMirror:
+        backendRef:
+          name: shadow
+          port: 8080
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createService1("default/shadow", "8080", "172.17.0.12")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+`,
+			expBackends: defaultBackend,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=False(UnsupportedValue) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN ignoring filter 'RequestMirror' on HTTPRoute 'default/web': mirror agent endpoints are not configured
+`,
+		},
+		{
+			id: "mirror-not-found-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - filters:
+    - type: RequestMirror
+      requestMirror:
+        backendRef:
+          name: shadow
+          port: 8080
+    backendRefs:
+    - name: echoserver
+      port: 8080
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.cache.Changed.GlobalConfigMapDataNew = map[string]string{"mirror-agent-endpoints": "127.0.0.1:12345"}
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+`,
+			expBackends: defaultBackend,
+			expLogging: `
+WARN skipping service 'shadow' on HTTPRoute 'default/web': service not found: 'default/shadow'
+`,
+		},
+		{
+			id: "unsupported-filter-1",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				r := c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].Filters = []gatewayv1.HTTPRouteFilter{{
+					Type:         gatewayv1.HTTPRouteFilterExtensionRef,
+					ExtensionRef: &gatewayv1.LocalObjectReference{Kind: "Other", Name: "other"},
+				}}
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+`,
+			expBackends: defaultBackend,
+			expLogging: `
+WARN ignoring unsupported filter 'ExtensionRef' on HTTPRoute 'default/web'
+`,
+		},
+	})
+}
+
+func TestSyncGRPCRouteCore(t *testing.T) {
+	defaultBackend := `
+- id: default_grpc__grpcrule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 50051
+    weight: 128
+  protocol: h2
+`
+	runTestSync(t, []testCaseSync{
+		{
+			id: "minimum",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createGRPCRoute1("default/grpc", "web", "grpcsvc:50051")
+				c.createService1("default/grpcsvc", "50051", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_grpc__grpcrule0
+`,
+			expBackends: defaultBackend,
+		},
+		{
+			id: "protocol-default-1",
+			config: func(c *testConfig) {
+				c.ann = annReaderMock{}
+				c.createGateway1("default/web", "l1")
+				c.createGRPCRoute1("default/grpc", "web", "grpcsvc:50051")
+				c.createService1("default/grpcsvc", "50051", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_grpc__grpcrule0
+`,
+			expBackends: defaultBackend,
+		},
+		{
+			id: "protocol-annotated-1",
+			config: func(c *testConfig) {
+				c.ann = annReaderMock{}
+				c.createGateway1("default/web", "l1")
+				c.createGRPCRoute1("default/grpc", "web", "grpcsvc:50051")
+				svc, _ := c.createService1("default/grpcsvc", "50051", "172.17.0.11")
+				svc.Annotations = map[string]string{"haproxy-ingress.github.io/backend-protocol": "h1"}
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_grpc__grpcrule0
+`,
+			expBackends: `
+- id: default_grpc__grpcrule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 50051
+    weight: 128
+  protocol: h1
+`,
+		},
+		{
+			id: "weight-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: GRPCRoute
+metadata:
+  name: grpc
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - backendRefs:
+    - name: grpcsvc1
+      port: 50051
+      weight: 3
+    - name: grpcsvc2
+      port: 50051
+      weight: 1
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/grpcsvc1", "50051", "172.17.0.11")
+				c.createService1("default/grpcsvc2", "50051", "172.17.0.12")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_grpc__grpcrule0
+`,
+			expBackends: `
+- id: default_grpc__grpcrule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 50051
+    weight: 256
+  - ip: 172.17.0.12
+    port: 50051
+    weight: 85
+  protocol: h2
+`,
+		},
+		{
+			id: "method-match-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: GRPCRoute
+metadata:
+  name: grpc
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  hostnames:
+  - grpc.local
+  rules:
+  - matches:
+    - method:
+        service: pkg.Service1
+        method: Get
+    - method:
+        service: pkg.Service2
+    - method:
+        method: List
+    - method:
+        type: RegularExpression
+        service: pkg\.Service[0-9]+
+        method: (Get|List)
+    - method:
+        type: RegularExpression
+        method: Watch.*
+      headers:
+      - name: x-version
+        value: v2
+    backendRefs:
+    - name: grpcsvc
+      port: 50051
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/grpcsvc", "50051", "172.17.0.11")
+			},
+			expHosts: `
+- hostname: grpc.local
+  paths:
+  - path: ^/[^/]+/List$
+    match: regex
+    backend: default_grpc__grpcrule0
+  - path: ^/(pkg\.Service[0-9]+)/((Get|List))$
+    match: regex
+    backend: default_grpc__grpcrule0
+  - path: ^/([^/]+)/(Watch.*)$
+    match: regex
+    headers:
+    - name: x-version
+      value: v2
+      regex: false
+    backend: default_grpc__grpcrule0
+  - path: /pkg.Service2
+    match: prefix
+    backend: default_grpc__grpcrule0
+  - path: /pkg.Service1/Get
+    match: exact
+    backend: default_grpc__grpcrule0
+`,
+			expBackends: defaultBackend,
+		},
+		{
+			id: "filters-1",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: GRPCRoute
+metadata:
+  name: grpc
+  namespace: default
+spec:
+  parentRefs:
+  - name: web
+  rules:
+  - filters:
+    - type: RequestHeaderModifier
+      requestHeaderModifier:
+        set:
+        - name: X-Source
+          value: gateway
+    - type: RequestMirror
+      requestMirror:
+        backendRef:
+          name: grpcmirror
+          port: 50051
+    backendRefs:
+    - name: grpcsvc
+      port: 50051
+`},
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createService1("default/grpcsvc", "50051", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_grpc__grpcrule0
+  reqheaders:
+    set:
+    - 'X-Source: gateway'
+`,
+			expBackends: defaultBackend,
+			expLogging:  `WARN ignoring unsupported filter 'RequestMirror' on GRPCRoute 'default/grpc'`,
+		},
+		{
+			id: "invalid-proto-1",
+			config: func(c *testConfig) {
+				c.createGateway1("default/pg", "l1:5432")
+				c.createGRPCRoute1("default/grpc", "pg", "grpcsvc:50051")
+				c.createService1("default/grpcsvc", "50051", "172.17.0.11")
+			},
+			expLogging: `WARN invalid protocol on Gateway 'default/pg' listener 'l1' for GRPCRoute 'default/grpc': 'TCP'`,
+		},
+	})
+}
+
+func TestSyncTCPRouteCore(t *testing.T) {
+	defaultBackend := `
+- id: default_pg__tcprule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 15432
+    weight: 128
+  modetcp: true
+`
+	runTestSync(t, []testCaseSync{
+		{
+			id: "minimum",
+			config: func(c *testConfig) {
+				c.createGateway1("default/pg", "l1:5432")
+				c.createTCPRoute1("default/pg", "pg", "postgres:15432")
+				c.createService1("default/postgres", "15432", "172.17.0.11")
+			},
+			expTCPServices: `
+- backends: []
+  defaultbackend: default_pg__tcprule0
+  port: 5432
+  proxyprot: false
+  tls: []
+`,
+			expBackends: defaultBackend,
+		},
+		{
+			id: "missing-proto",
+			resConfig: []string{`
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: web
+  namespace: default
+spec:
+  gatewayClassName: haproxy
+  listeners:
+  - name: h1
+`},
+			config: func(c *testConfig) {
+				c.createTCPRoute1("default/web", "default/web", "echoserver:8080")
+			},
+			expLogging: `WARN missing protocol on Gateway 'default/web' listener 'h1' for TCPRoute 'default/web'`,
+		},
+		{
+			id: "missing-secret-1",
+			config: func(c *testConfig) {
+				c.createGateway2("default/pg", "l1:5432", "crt")
+				c.createTCPRoute1("default/pg", "pg", "postgres:15432")
+				c.createService1("default/postgres", "15432", "172.17.0.11")
+			},
+			expBackends: defaultBackend,
+			expLogging:  `WARN skipping certificate reference on Gateway 'default/pg' listener 'l1': secret not found: 'default/crt'`,
+		},
+	})
+}
+
+func TestSyncGateway(t *testing.T) {
+	runTestSync(t, []testCaseSync{
+		{
+			id: "ignore-missing-gateway-class",
+			config: func(c *testConfig) {
+				c.createGateway0("default/web")
+				c.createHTTPRoute1("default/web", "web:l1", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expLogging:     "",
+			expDefaultHost: "",
+			expBackends:    "",
+		},
+	})
+}
+
+func TestSyncStatus(t *testing.T) {
+	runTestSync(t, []testCaseSync{
+		{
+			id: "gateway-without-routes",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1,l2:5432")
+			},
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=0 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+- listener l2: attachedRoutes=0 kinds=TCPRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+`,
+		},
+		{
+			id: "ignore-missing-gateway-class",
+			config: func(c *testConfig) {
+				c.createGateway0("default/web")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expStatus: `-`,
+		},
+		{
+			id: "route-accepted",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1,l2:5432")
+				c.createHTTPRoute1("default/web1", "web", "echoserver:8080")
+				c.createHTTPRoute1("default/web2", "web:l1", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web1__rule0
+`,
+			expBackends: `
+- id: default_web1__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+- id: default_web2__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=2 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+- listener l2: attachedRoutes=0 kinds=TCPRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web1:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+HTTPRoute default/web2:
+- parent web/l1: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN invalid protocol on Gateway 'default/web' listener 'l2' for HTTPRoute 'default/web1': 'TCP'
+WARN skipping redeclared path '/' type 'prefix' on HTTPRoute 'default/web2'
+`,
+		},
+		{
+			id: "route-not-allowed",
+			config: func(c *testConfig) {
+				c.createGateway1("ns1/web", "l1")
+				c.createHTTPRoute1("ns2/web", "ns1/web", "echoserver:8080")
+				c.createService1("ns2/echoserver", "8080", "172.17.0.11")
+			},
+			expStatus: `
+Gateway ns1/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=0 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute ns2/web:
+- parent ns1/web: Accepted=False(NotAllowedByListeners) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN skipping attachment of HTTPRoute 'ns2/web' to Gateway 'ns1/web' listener 'l1': listener does not allow the route
+`,
+		},
+		{
+			id: "route-no-matching-parent",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web:l2", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=0 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web/l2: Accepted=False(NoMatchingParent) ResolvedRefs=True(ResolvedRefs)
+`,
+		},
+		{
+			id: "route-backend-not-found",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+			},
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=False(BackendNotFound)
+`,
+			expLogging: `
+WARN skipping service 'echoserver' on HTTPRoute 'default/web': service not found: 'default/echoserver'
+`,
+		},
+		{
+			id: "listener-invalid-cert-ref",
+			config: func(c *testConfig) {
+				g := c.createGateway2("default/web", "l1", "crt")
+				g.Spec.Listeners[0].Protocol = gatewayv1.HTTPSProtocolType
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+			},
+			expBackends: `
+- id: default_web__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`,
+			expStatus: `
+Gateway default/web: Accepted=False(ListenersNotValid) Programmed=False(Invalid)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=False(InvalidCertificateRef) Programmed=False(Invalid)
+HTTPRoute default/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN skipping certificate reference on Gateway 'default/web' listener 'l1': secret not found: 'default/crt'
+`,
 		},
 		{
-			id: "missing-secret-1",
+			id: "listener-invalid-kind",
 			config: func(c *testConfig) {
-				c.createGateway2("default/pg", "l1:5432", "crt")
-				c.createTCPRoute1("default/pg", "pg", "postgres:15432")
-				c.createService1("default/postgres", "15432", "172.17.0.11")
+				g := c.createGateway1("default/web", "l1")
+				g.Spec.Listeners[0].AllowedRoutes.Kinds = []gatewayv1.RouteGroupKind{{Kind: "HTTPRoute"}, {Kind: "TCPRoute"}}
 			},
-			expBackends: defaultBackend,
-			expLogging:  `WARN skipping certificate reference on Gateway 'default/pg' listener 'l1': secret not found: 'default/crt'`,
+			expStatus: `
+Gateway default/web: Accepted=False(ListenersNotValid) Programmed=False(Invalid)
+- listener l1: attachedRoutes=0 kinds=HTTPRoute Accepted=True(Accepted) ResolvedRefs=False(InvalidRouteKinds) Programmed=False(Invalid)
+`,
 		},
-	})
-}
-
-func TestSyncGateway(t *testing.T) {
-	runTestSync(t, []testCaseSync{
 		{
-			id: "ignore-missing-gateway-class",
+			id: "status-unchanged",
 			config: func(c *testConfig) {
-				c.createGateway0("default/web")
-				c.createHTTPRoute1("default/web", "web:l1", "echoserver:8080")
-				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				g := c.createGateway1("default/web", "l1")
+				g.Status.Conditions = []v1.Condition{
+					{Type: "Accepted", Status: v1.ConditionTrue, Reason: "Accepted"},
+					{Type: "Programmed", Status: v1.ConditionTrue, Reason: "Programmed"},
+				}
+				g.Status.Listeners = []gatewayv1.ListenerStatus{{
+					Name:           "l1",
+					SupportedKinds: []gatewayv1.RouteGroupKind{{Group: ptr.To(gatewayv1.Group(gatewayv1.GroupName)), Kind: "HTTPRoute"}, {Group: ptr.To(gatewayv1.Group(gatewayv1.GroupName)), Kind: "GRPCRoute"}},
+					Conditions: []v1.Condition{
+						{Type: "Accepted", Status: v1.ConditionTrue, Reason: "Accepted"},
+						{Type: "ResolvedRefs", Status: v1.ConditionTrue, Reason: "ResolvedRefs"},
+						{Type: "Programmed", Status: v1.ConditionTrue, Reason: "Programmed"},
+					},
+				}}
 			},
-			expLogging:     "",
-			expDefaultHost: "",
-			expBackends:    "",
+			expStatus: `-`,
 		},
 	})
 }
@@ -851,6 +2365,336 @@ WARN skipping hostname 'domain.local' on TLSRoute 'default/web': hostname alread
 	})
 }
 
+func TestSyncReferenceGrant(t *testing.T) {
+	crossNamespaceBackend := `
+- id: ns1_web__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`
+	runTestSync(t, []testCaseSync{
+		{
+			id: "backend-missing-grant",
+			config: func(c *testConfig) {
+				c.createGateway1("ns1/web", "l1")
+				r := c.createHTTPRoute1("ns1/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace("ns2"))
+				c.createService1("ns2/echoserver", "8080", "172.17.0.11")
+			},
+			expStatus: `
+Gateway ns1/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute ns1/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=False(RefNotPermitted)
+`,
+			expLogging: `
+WARN skipping service 'echoserver' on HTTPRoute 'ns1/web': missing a ReferenceGrant allowing the cross namespace reference to service 'ns2/echoserver'
+`,
+		},
+		{
+			id: "backend-grant",
+			config: func(c *testConfig) {
+				c.createGateway1("ns1/web", "l1")
+				r := c.createHTTPRoute1("ns1/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace("ns2"))
+				c.createService1("ns2/echoserver", "8080", "172.17.0.11")
+				c.createReferenceGrant1("ns2/grant1", "HTTPRoute:ns1", "Service:")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: ns1_web__rule0
+`,
+			expBackends: crossNamespaceBackend,
+		},
+		{
+			id: "backend-grant-name",
+			config: func(c *testConfig) {
+				c.createGateway1("ns1/web", "l1")
+				r := c.createHTTPRoute1("ns1/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace("ns2"))
+				c.createService1("ns2/echoserver", "8080", "172.17.0.11")
+				c.createReferenceGrant1("ns2/grant1", "HTTPRoute:ns1", "Service:echoserver")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: ns1_web__rule0
+`,
+			expBackends: crossNamespaceBackend,
+		},
+		{
+			id: "backend-grant-mismatch",
+			config: func(c *testConfig) {
+				c.createGateway1("ns1/web", "l1")
+				r := c.createHTTPRoute1("ns1/web", "web", "echoserver:8080")
+				r.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace("ns2"))
+				c.createService1("ns2/echoserver", "8080", "172.17.0.11")
+				c.createReferenceGrant1("ns1/grant1", "HTTPRoute:ns1", "Service:")
+				c.createReferenceGrant1("ns2/grant2", "HTTPRoute:ns3", "Service:")
+				c.createReferenceGrant1("ns2/grant3", "GRPCRoute:ns1", "Service:")
+				c.createReferenceGrant1("ns2/grant4", "HTTPRoute:ns1", "Secret:")
+				c.createReferenceGrant1("ns2/grant5", "HTTPRoute:ns1", "Service:otherserver")
+			},
+			expLogging: `
+WARN skipping service 'echoserver' on HTTPRoute 'ns1/web': missing a ReferenceGrant allowing the cross namespace reference to service 'ns2/echoserver'
+`,
+		},
+		{
+			id: "certificate-missing-grant",
+			config: func(c *testConfig) {
+				c.createSecret1("ns2/crt")
+				g := c.createGateway2("ns1/web", "l1", "crt")
+				g.Spec.Listeners[0].TLS.CertificateRefs[0].Namespace = ptr.To(gatewayv1.Namespace("ns2"))
+				c.createHTTPRoute1("ns1/web", "web", "echoserver:8080")
+				c.createService1("ns1/echoserver", "8080", "172.17.0.11")
+			},
+			expBackends: crossNamespaceBackend,
+			expStatus: `
+Gateway ns1/web: Accepted=False(ListenersNotValid) Programmed=False(Invalid)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=False(RefNotPermitted) Programmed=False(Invalid)
+HTTPRoute ns1/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN skipping certificate reference on Gateway 'ns1/web' listener 'l1': missing a ReferenceGrant allowing the cross namespace reference to secret 'ns2/crt'
+`,
+		},
+		{
+			id: "certificate-grant",
+			config: func(c *testConfig) {
+				c.createSecret1("ns2/crt")
+				g := c.createGateway2("ns1/web", "l1", "crt")
+				g.Spec.Listeners[0].TLS.CertificateRefs[0].Namespace = ptr.To(gatewayv1.Namespace("ns2"))
+				c.createHTTPRoute1("ns1/web", "web", "echoserver:8080")
+				c.createService1("ns1/echoserver", "8080", "172.17.0.11")
+				c.createReferenceGrant1("ns2/grant1", "Gateway:ns1", "Secret:crt")
+			},
+			expDefaultHost: `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: ns1_web__rule0
+tls:
+  tlsfilename: /tls/ns2/crt.pem
+`,
+			expBackends: crossNamespaceBackend,
+		},
+	})
+}
+
+func TestSyncBackendTLSPolicy(t *testing.T) {
+	defaultHTTPHostConfig := `
+hostname: <default>
+paths:
+- path: /
+  match: prefix
+  backend: default_web__rule0
+`
+	defaultBackendConfig := `
+- id: default_web__rule0
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+    weight: 128
+`
+	// backends of invalid policies fail closed, without endpoints
+	deniedBackendConfig := `
+- id: default_web__rule0
+`
+	runTestSync(t, []testCaseSync{
+		{
+			id: "configmap-ca",
+			config: func(c *testConfig) {
+				c.cache.ConfigMapCA = map[string]string{"default/ca": "/ca/ca.pem"}
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echoserver.local", "ConfigMap:ca")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=true ca=/ca/ca.pem sni=str(echoserver.local) verifyhost=echoserver.local
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+BackendTLSPolicy default/tls1:
+- ancestor default/web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+`,
+		},
+		{
+			id: "secret-ca",
+			config: func(c *testConfig) {
+				c.cache.SecretCAPath = map[string]string{"default/ca": "/ca/ca-secret.pem"}
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echoserver.local", "Secret:ca")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=true ca=/ca/ca-secret.pem sni=str(echoserver.local) verifyhost=echoserver.local
+`,
+		},
+		{
+			id: "system-ca",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echoserver.local", "System")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=true ca=@system-ca sni=str(echoserver.local) verifyhost=echoserver.local
+`,
+		},
+		{
+			id: "system-ca-unsupported",
+			config: func(c *testConfig) {
+				c.hconfig.Global().HAProxy = hatypes.HAProxyVersion{Version: "2.4.28", Major: 2, Minor: 4}
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echoserver.local", "System")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    deniedBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=false ca= sni= verifyhost=
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+BackendTLSPolicy default/tls1:
+- ancestor default/web: Accepted=False(Invalid) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN skipping BackendTLSPolicy 'default/tls1': wellKnownCACerts 'System' is not supported on haproxy 2.4.28, it needs haproxy 2.6 or newer
+`,
+		},
+		{
+			id: "other-service",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "otherserver", "otherserver.local", "System")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=false ca= sni= verifyhost=
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+`,
+		},
+		{
+			id: "invalid-ca",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echoserver.local", "ConfigMap:ca")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    deniedBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=false ca= sni= verifyhost=
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+BackendTLSPolicy default/tls1:
+- ancestor default/web: Accepted=False(Invalid) ResolvedRefs=False(InvalidCACertificateRef)
+`,
+			expLogging: `
+WARN skipping BackendTLSPolicy 'default/tls1': configmap not found: 'default/ca'
+`,
+		},
+		{
+			id: "unsupported-ca-kind",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echoserver.local", "Other:ca")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    deniedBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=false ca= sni= verifyhost=
+`,
+			expLogging: `
+WARN skipping BackendTLSPolicy 'default/tls1': unsupported CA certificate reference Kind 'Other'
+`,
+		},
+		{
+			id: "invalid-hostname",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echo_server", "System")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    deniedBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=false ca= sni= verifyhost=
+`,
+			expStatus: `
+Gateway default/web: Accepted=True(Accepted) Programmed=True(Programmed)
+- listener l1: attachedRoutes=1 kinds=HTTPRoute,GRPCRoute Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs) Programmed=True(Programmed)
+HTTPRoute default/web:
+- parent web: Accepted=True(Accepted) ResolvedRefs=True(ResolvedRefs)
+BackendTLSPolicy default/tls1:
+- ancestor default/web: Accepted=False(Invalid) ResolvedRefs=True(ResolvedRefs)
+`,
+			expLogging: `
+WARN skipping BackendTLSPolicy 'default/tls1': invalid hostname 'echo_server': a lowercase DNS name is expected
+`,
+		},
+		{
+			id: "conflicting-policies",
+			config: func(c *testConfig) {
+				c.createGateway1("default/web", "l1")
+				c.createHTTPRoute1("default/web", "web", "echoserver:8080")
+				c.createService1("default/echoserver", "8080", "172.17.0.11")
+				c.createBackendTLSPolicy1("default/tls1", "echoserver", "echoserver.local", "System")
+				c.createBackendTLSPolicy1("default/tls2", "echoserver", "other.local", "System")
+			},
+			expDefaultHost: defaultHTTPHostConfig,
+			expBackends:    defaultBackendConfig,
+			expServerTLS: `
+default_web__rule0: secure=true ca=@system-ca sni=str(echoserver.local) verifyhost=echoserver.local
+`,
+			expLogging: `
+WARN skipping BackendTLSPolicy 'default/tls2': service 'default/echoserver' is already targeted by BackendTLSPolicy 'default/tls1'
+`,
+		},
+	})
+}
+
 func runTestSync(t *testing.T, testCases []testCaseSync) {
 	for _, test := range testCases {
 		t.Run(test.id, func(t *testing.T) {
@@ -889,6 +2733,15 @@ func runTestSync(t *testing.T, testCases []testCaseSync) {
 				c.compareConfigTCPServices(test.id, test.expTCPServices)
 				c.compareConfigBacks(test.id, test.expBackends)
 			}
+			if test.expServerTLS != "" {
+				c.compareServerTLS(test.id, test.expServerTLS)
+			}
+			if test.expTimeouts != "" {
+				c.compareTimeouts(test.id, test.expTimeouts)
+			}
+			if test.expStatus != "" {
+				c.compareStatus(test.id, test.expStatus)
+			}
 
 			c.logger.CompareLoggingID(test.id, test.expLogging)
 		})
@@ -901,6 +2754,7 @@ type testConfig struct {
 	logger  *types_helper.LoggerMock
 	tracker convtypes.Tracker
 	hconfig haproxy.Config
+	ann     convtypes.AnnotationReader
 }
 
 func setup(t *testing.T) *testConfig {
@@ -913,6 +2767,7 @@ func setup(t *testing.T) *testConfig {
 		logger:  logger,
 		tracker: tracker,
 	}
+	c.hconfig.Global().HAProxy = hatypes.HAProxyVersion{Version: "3.2.0", Major: 3, Minor: 2}
 	t.Cleanup(func() {
 		c.logger.CompareLogging("")
 	})
@@ -922,18 +2777,36 @@ func setup(t *testing.T) *testConfig {
 func (c *testConfig) createConverter() gateway.Config {
 	return gateway.NewGatewayConverter(
 		&convtypes.ConverterOptions{
-			Cache:         c.cache,
-			Logger:        c.logger,
-			Tracker:       c.tracker,
-			HasTLSRouteA2: true,
-			HasTCPRouteA2: true,
+			Cache:            c.cache,
+			Logger:           c.logger,
+			Tracker:          c.tracker,
+			ControllerName:   "haproxy-ingress.github.io/controller",
+			HasGRPCRouteA2:   true,
+			HasTLSRouteA2:    true,
+			HasTCPRouteA2:    true,
+			HasRefGrantB1:    true,
+			HasBackendTLSA2:  true,
+			AnnotationPrefix: []string{"haproxy-ingress.github.io"},
 		},
 		c.hconfig,
 		c.cache.SwapChangedObjects(),
-		nil,
+		c.ann,
 	)
 }
 
+// annReaderMock simulates the backend-protocol key of the ingress annotations
+// reader, which configures h1 if the key is not declared.
+type annReaderMock struct{}
+
+func (annReaderMock) ReadAnnotations(backend *hatypes.Backend, services []*api.Service, _ []*hatypes.PathLink) {
+	backend.Server.Protocol = "h1"
+	for _, svc := range services {
+		if proto := svc.Annotations["haproxy-ingress.github.io/backend-protocol"]; proto != "" {
+			backend.Server.Protocol = proto
+		}
+	}
+}
+
 func (c *testConfig) createNamespace(name, labels string) *api.Namespace {
 	ns := &api.Namespace{}
 	ns.Name = name
@@ -1049,6 +2922,61 @@ func (c *testConfig) createGateway2(name, listeners, secretName string) *gateway
 	return gw
 }
 
+// createReferenceGrant1 creates a ReferenceGrant with a single from and to items.
+// from syntax is `<Kind>:<namespace>`, to syntax is `<Kind>:<name>`, name is optional.
+func (c *testConfig) createReferenceGrant1(name, from, to string) *gatewayv1beta1.ReferenceGrant {
+	n := strings.Split(name, "/")
+	f := strings.Split(from, ":")
+	t := strings.Split(to, ":")
+	refGrant := CreateObject(`
+apiVersion: gateway.networking.k8s.io/v1beta1
+kind: ReferenceGrant
+metadata:
+  name: ` + n[1] + `
+  namespace: ` + n[0] + `
+spec:
+  from:
+  - group: gateway.networking.k8s.io
+    kind: ` + f[0] + `
+    namespace: ` + f[1] + `
+  to:
+  - group: ""
+    kind: ` + t[0]).(*gatewayv1beta1.ReferenceGrant)
+	if t[1] != "" {
+		refGrant.Spec.To[0].Name = ptr.To(gatewayv1.ObjectName(t[1]))
+	}
+	c.cache.RefGrantList = append(c.cache.RefGrantList, refGrant)
+	return refGrant
+}
+
+func (c *testConfig) createBackendTLSPolicy1(name, service, hostname, caRef string) *gatewayv1alpha2.BackendTLSPolicy {
+	n := strings.Split(name, "/")
+	policy := CreateObject(`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: BackendTLSPolicy
+metadata:
+  name: ` + n[1] + `
+  namespace: ` + n[0] + `
+spec:
+  targetRef:
+    group: ""
+    kind: Service
+    name: ` + service + `
+  tls:
+    hostname: ` + hostname).(*gatewayv1alpha2.BackendTLSPolicy)
+	if caRef == "System" {
+		policy.Spec.TLS.WellKnownCACerts = ptr.To(gatewayv1alpha2.WellKnownCACertSystem)
+	} else if caRef != "" {
+		r := strings.Split(caRef, ":")
+		policy.Spec.TLS.CACertRefs = []gatewayv1beta1.LocalObjectReference{{
+			Kind: gatewayv1.Kind(r[0]),
+			Name: gatewayv1.ObjectName(r[1]),
+		}}
+	}
+	c.cache.BackendTLSList = append(c.cache.BackendTLSList, policy)
+	return policy
+}
+
 func splitRouteInfo(name, parent, services string) (n []string, svcs [][]string, pns, pn, ps string) {
 	n = strings.Split(name, "/")
 	if i := strings.Index(parent, "/"); i >= 0 {
@@ -1114,6 +3042,12 @@ func (c *testConfig) createHTTPRoute2(name, parent, service, paths string) *gate
 	return r
 }
 
+func (c *testConfig) createGRPCRoute1(name, parent, services string) *gatewayv1alpha2.GRPCRoute {
+	r := CreateObject(c.createRoute("GRPCRoute", "v1alpha2", name, parent, services)).(*gatewayv1alpha2.GRPCRoute)
+	c.cache.GRPCRouteList = append(c.cache.GRPCRouteList, r)
+	return r
+}
+
 func (c *testConfig) createTLSRoute1(name, parent, services string) *gatewayv1alpha2.TLSRoute {
 	r := CreateObject(c.createRoute("TLSRoute", "v1alpha2", name, parent, services)).(*gatewayv1alpha2.TLSRoute)
 	c.cache.TLSRouteList = append(c.cache.TLSRouteList, r)
@@ -1134,6 +3068,8 @@ func (c *testConfig) createGatewayResources(res []string) {
 			c.cache.GatewayList = append(c.cache.GatewayList, obj)
 		case *gatewayv1.HTTPRoute:
 			c.cache.HTTPRouteList = append(c.cache.HTTPRouteList, obj)
+		case *gatewayv1alpha2.GRPCRoute:
+			c.cache.GRPCRouteList = append(c.cache.GRPCRouteList, obj)
 		case nil:
 			panic(fmt.Errorf("object is nil, cfg is %s", cfg))
 		default:
@@ -1151,6 +3087,86 @@ func CreateObject(cfg string) runtime.Object {
 	return obj
 }
 
+// compareStatus compares a compact representation of the status updates.
+// Use `-` as the expected value to ensure that no status was updated.
+func (c *testConfig) compareStatus(id string, expected string) {
+	conditions := func(conds []v1.Condition) string {
+		var out []string
+		for _, cond := range conds {
+			out = append(out, fmt.Sprintf("%s=%s(%s)", cond.Type, cond.Status, cond.Reason))
+		}
+		return strings.Join(out, " ")
+	}
+	var out []string
+	for _, obj := range c.cache.StatusList {
+		header := fmt.Sprintf("%s %s/%s:", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName())
+		var routeStatus *gatewayv1.RouteStatus
+		switch obj := obj.(type) {
+		case *gatewayv1.Gateway:
+			out = append(out, header+" "+conditions(obj.Status.Conditions))
+			for _, l := range obj.Status.Listeners {
+				var kinds []string
+				for _, kind := range l.SupportedKinds {
+					kinds = append(kinds, string(kind.Kind))
+				}
+				out = append(out, fmt.Sprintf("- listener %s: attachedRoutes=%d kinds=%s %s",
+					l.Name, l.AttachedRoutes, strings.Join(kinds, ","), conditions(l.Conditions)))
+			}
+		case *gatewayv1.HTTPRoute:
+			routeStatus = &obj.Status.RouteStatus
+		case *gatewayv1alpha2.GRPCRoute:
+			routeStatus = &obj.Status.RouteStatus
+		case *gatewayv1alpha2.TLSRoute:
+			routeStatus = &obj.Status.RouteStatus
+		case *gatewayv1alpha2.TCPRoute:
+			routeStatus = &obj.Status.RouteStatus
+		case *gatewayv1alpha2.BackendTLSPolicy:
+			out = append(out, header)
+			for _, a := range obj.Status.Ancestors {
+				out = append(out, fmt.Sprintf("- ancestor %s/%s: %s", *a.AncestorRef.Namespace, a.AncestorRef.Name, conditions(a.Conditions)))
+			}
+		}
+		if routeStatus != nil {
+			out = append(out, header)
+			for _, p := range routeStatus.Parents {
+				parent := string(p.ParentRef.Name)
+				if p.ParentRef.Namespace != nil && *p.ParentRef.Namespace != "" {
+					parent = string(*p.ParentRef.Namespace) + "/" + parent
+				}
+				if p.ParentRef.SectionName != nil && *p.ParentRef.SectionName != "" {
+					parent += "/" + string(*p.ParentRef.SectionName)
+				}
+				out = append(out, fmt.Sprintf("- parent %s: %s", parent, conditions(p.Conditions)))
+			}
+		}
+	}
+	actual := strings.Join(out, "\n")
+	if actual == "" {
+		actual = "-"
+	}
+	c.compareText(id, actual, expected)
+}
+
+func (c *testConfig) compareServerTLS(id string, expected string) {
+	var out []string
+	for _, b := range c.hconfig.Backends().BuildSortedItems() {
+		server := b.Server
+		out = append(out, fmt.Sprintf("%s: secure=%t ca=%s sni=%s verifyhost=%s",
+			b.ID, server.Secure, server.CAFilename, server.SNI, server.VerifyHost))
+	}
+	c.compareText(id, strings.Join(out, "\n"), expected)
+}
+
+func (c *testConfig) compareTimeouts(id string, expected string) {
+	var out []string
+	for _, b := range c.hconfig.Backends().BuildSortedItems() {
+		timeout := b.Timeout
+		out = append(out, fmt.Sprintf("%s: http-request=%s queue=%s server=%s",
+			b.ID, timeout.HTTPRequest, timeout.Queue, timeout.Server))
+	}
+	c.compareText(id, strings.Join(out, "\n"), expected)
+}
+
 func (c *testConfig) compareText(id string, actual, expected string) {
 	txt1 := "\n" + strings.Trim(expected, "\n")
 	txt2 := "\n" + strings.Trim(actual, "\n")
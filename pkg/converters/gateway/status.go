@@ -0,0 +1,462 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// statusCollector collects the decisions made on Gateways, Listeners and Routes
+// during a Sync(), so their status can be published when the sync finishes.
+type statusCollector struct {
+	gateways    map[string]*gatewayStatus
+	gatewayList []*gatewayStatus
+	routes      map[*source]*routeStatus
+	routeList   []*routeStatus
+	policyList  []*policyStatus
+}
+
+type gatewayStatus struct {
+	source    *gatewaySource
+	listeners map[gatewayv1.SectionName]*listenerStatus
+}
+
+type listenerStatus struct {
+	routes     map[*source]bool
+	certRefErr error
+}
+
+type routeStatus struct {
+	source         *source
+	parents        []*routeParentStatus
+	refsReason     gatewayv1.RouteConditionReason
+	refsErr        error
+	unsupportedErr error
+}
+
+type routeParentStatus struct {
+	route      *routeStatus
+	gateway    *gatewayStatus
+	parentRef  gatewayv1.ParentReference
+	attached   bool
+	notAllowed error
+	noHostname bool
+}
+
+type policyStatus struct {
+	source         *source
+	policy         *gatewayv1alpha2.BackendTLSPolicy
+	ancestors      []*gatewayStatus
+	err            error
+	unresolvedRefs bool
+}
+
+// BackendTLSPolicy condition and reasons that are not declared in the v1alpha2 API
+const (
+	policyConditionResolvedRefs         = "ResolvedRefs"
+	policyReasonResolvedRefs            = "ResolvedRefs"
+	policyReasonInvalidCACertificateRef = "InvalidCACertificateRef"
+)
+
+func newStatusCollector() *statusCollector {
+	return &statusCollector{
+		gateways: make(map[string]*gatewayStatus),
+		routes:   make(map[*source]*routeStatus),
+	}
+}
+
+func (s *statusCollector) gateway(gatewaySource *gatewaySource) *gatewayStatus {
+	key := gatewaySource.namespace + "/" + gatewaySource.name
+	gw := s.gateways[key]
+	if gw == nil {
+		gw = &gatewayStatus{
+			source:    gatewaySource,
+			listeners: make(map[gatewayv1.SectionName]*listenerStatus),
+		}
+		s.gateways[key] = gw
+		s.gatewayList = append(s.gatewayList, gw)
+	}
+	return gw
+}
+
+func (s *statusCollector) route(routeSource *source) *routeStatus {
+	route := s.routes[routeSource]
+	if route == nil {
+		route = &routeStatus{source: routeSource}
+		s.routes[routeSource] = route
+		s.routeList = append(s.routeList, route)
+	}
+	return route
+}
+
+func (s *statusCollector) policy(policy *gatewayv1alpha2.BackendTLSPolicy) *policyStatus {
+	policySource := newSource(policy)
+	p := &policyStatus{
+		source: &policySource,
+		policy: policy,
+	}
+	s.policyList = append(s.policyList, p)
+	return p
+}
+
+func (g *gatewayStatus) listener(name gatewayv1.SectionName) *listenerStatus {
+	l := g.listeners[name]
+	if l == nil {
+		l = &listenerStatus{routes: make(map[*source]bool)}
+		g.listeners[name] = l
+	}
+	return l
+}
+
+// certRefError registers a failure reading the certificates of a listener.
+func (g *gatewayStatus) certRefError(listener *gatewayv1.Listener, err error) {
+	l := g.listener(listener.Name)
+	if l.certRefErr == nil {
+		l.certRefErr = err
+	}
+}
+
+func (r *routeStatus) addParent(parentRef gatewayv1.ParentReference, gateway *gatewayStatus) *routeParentStatus {
+	parent := &routeParentStatus{
+		route:     r,
+		gateway:   gateway,
+		parentRef: parentRef,
+	}
+	r.parents = append(r.parents, parent)
+	return parent
+}
+
+// backendRefError registers a backend reference that could not be resolved,
+// only the first failure is reported.
+func (r *routeStatus) backendRefError(reason gatewayv1.RouteConditionReason, err error) {
+	if r.refsErr == nil {
+		r.refsReason = reason
+		r.refsErr = err
+	}
+}
+
+// unsupportedValue registers a route configuration that could not be applied,
+// only the first failure is reported.
+func (r *routeStatus) unsupportedValue(err error) {
+	if r.unsupportedErr == nil {
+		r.unsupportedErr = err
+	}
+}
+
+// attach registers the route as successfully attached to the listener.
+func (p *routeParentStatus) attach(listener *gatewayv1.Listener) {
+	p.attached = true
+	p.gateway.listener(listener.Name).routes[p.route.source] = true
+}
+
+// rejectHostname registers a listener whose hostname does not intersect with the route hostnames.
+func (p *routeParentStatus) rejectHostname() {
+	p.noHostname = true
+}
+
+// reject registers a listener that does not allow the route.
+func (p *routeParentStatus) reject(err error) {
+	if p.notAllowed == nil {
+		p.notAllowed = err
+	}
+}
+
+// addAncestor registers a Gateway whose backends are configured by the policy.
+func (p *policyStatus) addAncestor(gateway *gatewayStatus) {
+	if !slices.Contains(p.ancestors, gateway) {
+		p.ancestors = append(p.ancestors, gateway)
+	}
+}
+
+// publishStatus updates the status of all the Gateways and Routes visited during the sync,
+// skipping the ones whose status did not change. Status updates are only applied by the
+// leader, see svcStatusUpdater.
+func (c *converter) publishStatus() {
+	for _, gw := range c.status.gatewayList {
+		c.publishGatewayStatus(gw)
+	}
+	for _, route := range c.status.routeList {
+		c.publishRouteStatus(route)
+	}
+	for _, policy := range c.status.policyList {
+		c.publishPolicyStatus(policy)
+	}
+}
+
+func (c *converter) publishGatewayStatus(gw *gatewayStatus) {
+	obj := gw.source.obj.DeepCopyObject().(client.Object)
+	status := reflect.ValueOf(obj).Elem().FieldByName("Status").Addr().Interface().(*gatewayv1.GatewayStatus)
+	oldStatus := status.DeepCopy()
+	generation := obj.GetGeneration()
+
+	var validListeners int
+	listeners := make([]gatewayv1.ListenerStatus, 0, len(gw.source.spec.Listeners))
+	for i := range gw.source.spec.Listeners {
+		listener := &gw.source.spec.Listeners[i]
+		ls := gatewayv1.ListenerStatus{Name: listener.Name}
+		if j := slices.IndexFunc(oldStatus.Listeners, func(l gatewayv1.ListenerStatus) bool { return l.Name == listener.Name }); j >= 0 {
+			ls.Conditions = slices.Clone(oldStatus.Listeners[j].Conditions)
+		}
+		l := gw.listener(listener.Name)
+		ls.AttachedRoutes = int32(len(l.routes))
+		var kindsErr error
+		ls.SupportedKinds, kindsErr = c.listenerSupportedKinds(listener)
+
+		accepted := true
+		if listener.Protocol == "" || len(c.listenerProtocolKinds(listener.Protocol)) == 0 {
+			accepted = false
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionAccepted), false,
+				string(gatewayv1.ListenerReasonUnsupportedProtocol), fmt.Sprintf("unsupported protocol '%s'", listener.Protocol))
+		} else {
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionAccepted), true,
+				string(gatewayv1.ListenerReasonAccepted), "")
+		}
+
+		resolved := true
+		switch {
+		case kindsErr != nil:
+			resolved = false
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionResolvedRefs), false,
+				string(gatewayv1.ListenerReasonInvalidRouteKinds), kindsErr.Error())
+		case (listener.Protocol == gatewayv1.HTTPSProtocolType || listener.Protocol == gatewayv1.TLSProtocolType) && listener.TLS == nil:
+			resolved = false
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionResolvedRefs), false,
+				string(gatewayv1.ListenerReasonInvalidCertificateRef), "missing TLS configuration")
+		case errors.Is(l.certRefErr, errRefNotPermitted):
+			resolved = false
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionResolvedRefs), false,
+				string(gatewayv1.ListenerReasonRefNotPermitted), l.certRefErr.Error())
+		case l.certRefErr != nil:
+			resolved = false
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionResolvedRefs), false,
+				string(gatewayv1.ListenerReasonInvalidCertificateRef), l.certRefErr.Error())
+		default:
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionResolvedRefs), true,
+				string(gatewayv1.ListenerReasonResolvedRefs), "")
+		}
+
+		if accepted && resolved {
+			validListeners++
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionProgrammed), true,
+				string(gatewayv1.ListenerReasonProgrammed), "")
+		} else {
+			setCondition(&ls.Conditions, generation, string(gatewayv1.ListenerConditionProgrammed), false,
+				string(gatewayv1.ListenerReasonInvalid), "listener is not valid")
+		}
+		listeners = append(listeners, ls)
+	}
+	status.Listeners = listeners
+
+	if validListeners > 0 {
+		setCondition(&status.Conditions, generation, string(gatewayv1.GatewayConditionAccepted), true,
+			string(gatewayv1.GatewayReasonAccepted), "")
+		setCondition(&status.Conditions, generation, string(gatewayv1.GatewayConditionProgrammed), true,
+			string(gatewayv1.GatewayReasonProgrammed), "")
+	} else {
+		setCondition(&status.Conditions, generation, string(gatewayv1.GatewayConditionAccepted), false,
+			string(gatewayv1.GatewayReasonListenersNotValid), "gateway has no valid listener")
+		setCondition(&status.Conditions, generation, string(gatewayv1.GatewayConditionProgrammed), false,
+			string(gatewayv1.GatewayReasonInvalid), "gateway has no valid listener")
+	}
+
+	if !equality.Semantic.DeepEqual(oldStatus, status) {
+		c.cache.UpdateStatus(obj)
+	}
+}
+
+func (c *converter) publishRouteStatus(route *routeStatus) {
+	obj := route.source.obj.DeepCopyObject().(client.Object)
+	status := reflect.ValueOf(obj).Elem().FieldByName("Status").FieldByName("RouteStatus").Addr().Interface().(*gatewayv1.RouteStatus)
+	oldStatus := status.DeepCopy()
+	generation := obj.GetGeneration()
+	controllerName := gatewayv1.GatewayController(c.options.ControllerName)
+
+	// parents managed by other controllers are preserved,
+	// our own ones are rebuilt from the current sync.
+	parents := slices.DeleteFunc(slices.Clone(oldStatus.Parents), func(p gatewayv1.RouteParentStatus) bool {
+		return p.ControllerName == controllerName
+	})
+	for _, parent := range route.parents {
+		ps := gatewayv1.RouteParentStatus{
+			ParentRef:      parent.parentRef,
+			ControllerName: controllerName,
+		}
+		if slices.ContainsFunc(parents, func(p gatewayv1.RouteParentStatus) bool {
+			return p.ControllerName == controllerName && reflect.DeepEqual(p.ParentRef, parent.parentRef)
+		}) {
+			// duplicated parentRef
+			continue
+		}
+		if j := slices.IndexFunc(oldStatus.Parents, func(p gatewayv1.RouteParentStatus) bool {
+			return p.ControllerName == controllerName && reflect.DeepEqual(p.ParentRef, parent.parentRef)
+		}); j >= 0 {
+			ps.Conditions = slices.Clone(oldStatus.Parents[j].Conditions)
+		}
+
+		switch {
+		case parent.attached && route.unsupportedErr != nil:
+			setCondition(&ps.Conditions, generation, string(gatewayv1.RouteConditionAccepted), false,
+				string(gatewayv1.RouteReasonUnsupportedValue), route.unsupportedErr.Error())
+		case parent.attached:
+			setCondition(&ps.Conditions, generation, string(gatewayv1.RouteConditionAccepted), true,
+				string(gatewayv1.RouteReasonAccepted), "")
+		case parent.notAllowed != nil:
+			setCondition(&ps.Conditions, generation, string(gatewayv1.RouteConditionAccepted), false,
+				string(gatewayv1.RouteReasonNotAllowedByListeners), parent.notAllowed.Error())
+		case parent.noHostname:
+			setCondition(&ps.Conditions, generation, string(gatewayv1.RouteConditionAccepted), false,
+				string(gatewayv1.RouteReasonNoMatchingListenerHostname), "no hostname intersects with the listener hostname")
+		default:
+			setCondition(&ps.Conditions, generation, string(gatewayv1.RouteConditionAccepted), false,
+				string(gatewayv1.RouteReasonNoMatchingParent), "no listener matches the route parent reference")
+		}
+
+		if route.refsErr != nil {
+			setCondition(&ps.Conditions, generation, string(gatewayv1.RouteConditionResolvedRefs), false,
+				string(route.refsReason), route.refsErr.Error())
+		} else {
+			setCondition(&ps.Conditions, generation, string(gatewayv1.RouteConditionResolvedRefs), true,
+				string(gatewayv1.RouteReasonResolvedRefs), "")
+		}
+		parents = append(parents, ps)
+	}
+	status.Parents = parents
+
+	if !equality.Semantic.DeepEqual(oldStatus, status) {
+		c.cache.UpdateStatus(obj)
+	}
+}
+
+func (c *converter) publishPolicyStatus(policy *policyStatus) {
+	obj := policy.policy.DeepCopy()
+	status := &obj.Status
+	oldStatus := status.DeepCopy()
+	generation := obj.GetGeneration()
+	controllerName := gatewayv1.GatewayController(c.options.ControllerName)
+
+	// ancestors managed by other controllers are preserved,
+	// our own ones are rebuilt from the current sync.
+	ancestors := slices.DeleteFunc(slices.Clone(oldStatus.Ancestors), func(a gatewayv1alpha2.PolicyAncestorStatus) bool {
+		return a.ControllerName == controllerName
+	})
+	for _, gw := range policy.ancestors {
+		group := gatewayGroup
+		kind := gatewayKind
+		namespace := gatewayv1.Namespace(gw.source.namespace)
+		as := gatewayv1alpha2.PolicyAncestorStatus{
+			AncestorRef: gatewayv1.ParentReference{
+				Group:     &group,
+				Kind:      &kind,
+				Namespace: &namespace,
+				Name:      gatewayv1.ObjectName(gw.source.name),
+			},
+			ControllerName: controllerName,
+		}
+		if j := slices.IndexFunc(oldStatus.Ancestors, func(a gatewayv1alpha2.PolicyAncestorStatus) bool {
+			return a.ControllerName == controllerName && reflect.DeepEqual(a.AncestorRef, as.AncestorRef)
+		}); j >= 0 {
+			as.Conditions = slices.Clone(oldStatus.Ancestors[j].Conditions)
+		}
+		if policy.err != nil {
+			setCondition(&as.Conditions, generation, string(gatewayv1alpha2.PolicyConditionAccepted), false,
+				string(gatewayv1alpha2.PolicyReasonInvalid), policy.err.Error())
+		} else {
+			setCondition(&as.Conditions, generation, string(gatewayv1alpha2.PolicyConditionAccepted), true,
+				string(gatewayv1alpha2.PolicyReasonAccepted), "")
+		}
+		if policy.unresolvedRefs {
+			setCondition(&as.Conditions, generation, policyConditionResolvedRefs, false,
+				policyReasonInvalidCACertificateRef, policy.err.Error())
+		} else {
+			setCondition(&as.Conditions, generation, policyConditionResolvedRefs, true,
+				policyReasonResolvedRefs, "")
+		}
+		ancestors = append(ancestors, as)
+	}
+	status.Ancestors = ancestors
+
+	if !equality.Semantic.DeepEqual(oldStatus, status) {
+		c.cache.UpdateStatus(obj)
+	}
+}
+
+// listenerProtocolKinds lists the route kinds that can be attached to a listener of the provided protocol.
+func (c *converter) listenerProtocolKinds(protocol gatewayv1.ProtocolType) []gatewayv1.Kind {
+	switch protocol {
+	case gatewayv1.HTTPProtocolType, gatewayv1.HTTPSProtocolType:
+		if c.options.HasGRPCRouteA2 {
+			return []gatewayv1.Kind{"HTTPRoute", "GRPCRoute"}
+		}
+		return []gatewayv1.Kind{"HTTPRoute"}
+	case gatewayv1.TLSProtocolType:
+		return []gatewayv1.Kind{"TLSRoute"}
+	case gatewayv1.TCPProtocolType:
+		return []gatewayv1.Kind{"TCPRoute"}
+	}
+	return nil
+}
+
+// listenerSupportedKinds lists the route kinds allowed by the listener. An error is returned if
+// the listener allows a route kind that is not supported by its protocol.
+func (c *converter) listenerSupportedKinds(listener *gatewayv1.Listener) ([]gatewayv1.RouteGroupKind, error) {
+	protocolKinds := c.listenerProtocolKinds(listener.Protocol)
+	var allowedKinds []gatewayv1.RouteGroupKind
+	if listener.AllowedRoutes != nil {
+		allowedKinds = listener.AllowedRoutes.Kinds
+	}
+	supportedKinds := []gatewayv1.RouteGroupKind{}
+	if len(allowedKinds) == 0 {
+		for _, kind := range protocolKinds {
+			group := gatewayGroup
+			supportedKinds = append(supportedKinds, gatewayv1.RouteGroupKind{Group: &group, Kind: kind})
+		}
+		return supportedKinds, nil
+	}
+	var err error
+	for _, kind := range allowedKinds {
+		if (kind.Group == nil || *kind.Group == gatewayGroup) && slices.Contains(protocolKinds, kind.Kind) {
+			supportedKinds = append(supportedKinds, kind)
+		} else if err == nil {
+			err = fmt.Errorf("unsupported route kind '%s'", kind.Kind)
+		}
+	}
+	return supportedKinds, err
+}
+
+func setCondition(conditions *[]v1.Condition, generation int64, condType string, status bool, reason, message string) {
+	condStatus := v1.ConditionFalse
+	if status {
+		condStatus = v1.ConditionTrue
+	}
+	meta.SetStatusCondition(conditions, v1.Condition{
+		Type:               condType,
+		Status:             condStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -49,10 +49,15 @@ type CacheMock struct {
 	SvcList      []*api.Service
 	//
 	HTTPRouteList    []*gatewayv1.HTTPRoute
+	GRPCRouteList    []*gatewayv1alpha2.GRPCRoute
 	TLSRouteList     []*gatewayv1alpha2.TLSRoute
 	TCPRouteList     []*gatewayv1alpha2.TCPRoute
+	RefGrantList     []*gatewayv1beta1.ReferenceGrant
+	BackendTLSList   []*gatewayv1alpha2.BackendTLSPolicy
 	GatewayList      []*gatewayv1.Gateway
 	GatewayClassList []*gatewayv1.GatewayClass
+	StatusList       []client.Object
+	EventList        []string
 	//
 	NsList        map[string]*api.Namespace
 	LookupList    map[string][]net.IP
@@ -63,8 +68,10 @@ type CacheMock struct {
 	SecretTLSPath map[string]string
 	SecretCAPath  map[string]string
 	SecretCRLPath map[string]string
+	ConfigMapCA   map[string]string
 	SecretDHPath  map[string]string
 	SecretContent SecretContent
+	JWTKeyPath    map[string][]convtypes.JWTKeyFile
 }
 
 // NewCacheMock ...
@@ -139,6 +146,10 @@ func (c *CacheMock) GetHTTPRouteList() ([]*gatewayv1.HTTPRoute, error) {
 	return c.HTTPRouteList, nil
 }
 
+func (c *CacheMock) GetGRPCRouteList() ([]*gatewayv1alpha2.GRPCRoute, error) {
+	return c.GRPCRouteList, nil
+}
+
 func (c *CacheMock) GetTCPRouteList() ([]*gatewayv1alpha2.TCPRoute, error) {
 	return c.TCPRouteList, nil
 }
@@ -147,6 +158,20 @@ func (c *CacheMock) GetTLSRouteList() ([]*gatewayv1alpha2.TLSRoute, error) {
 	return c.TLSRouteList, nil
 }
 
+func (c *CacheMock) GetReferenceGrantList(namespace string) ([]*gatewayv1beta1.ReferenceGrant, error) {
+	var refGrantList []*gatewayv1beta1.ReferenceGrant
+	for _, refGrant := range c.RefGrantList {
+		if refGrant.Namespace == namespace {
+			refGrantList = append(refGrantList, refGrant)
+		}
+	}
+	return refGrantList, nil
+}
+
+func (c *CacheMock) GetBackendTLSPolicyList() ([]*gatewayv1alpha2.BackendTLSPolicy, error) {
+	return c.BackendTLSList, nil
+}
+
 // GetGatewayA2 ...
 func (c *CacheMock) GetGatewayA2(namespace, name string) (*gatewayv1alpha2.Gateway, error) {
 	return nil, fmt.Errorf("missing implementation")
@@ -170,6 +195,27 @@ func (c *CacheMock) GetGateway(namespace, name string) (*gatewayv1.Gateway, erro
 	return nil, fmt.Errorf("gateway not found: %s/%s", namespace, name)
 }
 
+// GetGatewayA2List ...
+func (c *CacheMock) GetGatewayA2List() ([]*gatewayv1alpha2.Gateway, error) {
+	return nil, fmt.Errorf("missing implementation")
+}
+
+// GetGatewayB1List ...
+func (c *CacheMock) GetGatewayB1List() ([]*gatewayv1beta1.Gateway, error) {
+	return nil, fmt.Errorf("missing implementation")
+}
+
+// GetGatewayList ...
+func (c *CacheMock) GetGatewayList() ([]*gatewayv1.Gateway, error) {
+	var gwList []*gatewayv1.Gateway
+	for _, gw := range c.GatewayList {
+		if gw.Spec.GatewayClassName == "haproxy" {
+			gwList = append(gwList, gw)
+		}
+	}
+	return gwList, nil
+}
+
 // GetService ...
 func (c *CacheMock) GetService(defaultNamespace, serviceName string) (*api.Service, error) {
 	fullname := c.buildResourceName(defaultNamespace, serviceName)
@@ -271,6 +317,19 @@ func (c *CacheMock) GetCASecretPath(defaultNamespace, secretName string, track [
 	return ca, crl, nil
 }
 
+// GetCAConfigMapPath ...
+func (c *CacheMock) GetCAConfigMapPath(namespace, configMapName string, track []convtypes.TrackingRef) (convtypes.File, error) {
+	fullname := namespace + "/" + configMapName
+	c.tracker.TrackRefName(track, convtypes.ResourceConfigMap, fullname)
+	if path, found := c.ConfigMapCA[fullname]; found {
+		return convtypes.File{
+			Filename: path,
+			SHA1Hash: fmt.Sprintf("%x", sha1.Sum([]byte(path))),
+		}, nil
+	}
+	return convtypes.File{}, fmt.Errorf("configmap not found: '%s'", fullname)
+}
+
 // GetDHSecretPath ...
 func (c *CacheMock) GetDHSecretPath(defaultNamespace, secretName string) (convtypes.File, error) {
 	fullname := c.buildResourceName(defaultNamespace, secretName)
@@ -297,16 +356,41 @@ func (c *CacheMock) GetPasswdSecretContent(defaultNamespace, secretName string,
 	return nil, fmt.Errorf("secret not found: '%s'", fullname)
 }
 
+// GetJWTKeyPath ...
+func (c *CacheMock) GetJWTKeyPath(defaultNamespace, keyName string, track []convtypes.TrackingRef) ([]convtypes.JWTKeyFile, error) {
+	context := convtypes.ResourceSecret
+	var proto string
+	if cmName, found := strings.CutPrefix(keyName, "configmap://"); found {
+		context = convtypes.ResourceConfigMap
+		proto = "configmap://"
+		keyName = cmName
+	}
+	fullname := c.buildResourceName(defaultNamespace, keyName)
+	c.tracker.TrackRefName(track, context, fullname)
+	if keys, found := c.JWTKeyPath[proto+fullname]; found {
+		return keys, nil
+	}
+	return nil, fmt.Errorf("%s not found: '%s'", strings.ToLower(string(context)), fullname)
+}
+
 // UpdateStatus ...
-func (c *CacheMock) UpdateStatus(client.Object) {}
+func (c *CacheMock) UpdateStatus(obj client.Object) {
+	c.StatusList = append(c.StatusList, obj)
+}
+
+// RecordEvent ...
+func (c *CacheMock) RecordEvent(obj client.Object, eventtype, reason, message string) {
+	c.EventList = append(c.EventList, fmt.Sprintf("%s %s/%s %s: %s", eventtype, obj.GetNamespace(), obj.GetName(), reason, message))
+}
 
 // SwapChangedObjects ...
 func (c *CacheMock) SwapChangedObjects() *convtypes.ChangedObjects {
 	changed := c.Changed
 	c.Changed = &convtypes.ChangedObjects{
-		GlobalConfigMapDataCur: changed.GlobalConfigMapDataNew,
-		TCPConfigMapDataCur:    changed.TCPConfigMapDataNew,
-		Links:                  make(convtypes.TrackingLinks),
+		GlobalConfigMapDataCur:    changed.GlobalConfigMapDataNew,
+		TCPConfigMapDataCur:       changed.TCPConfigMapDataNew,
+		AnnPolicyConfigMapDataCur: changed.AnnPolicyConfigMapDataNew,
+		Links:                     make(convtypes.TrackingLinks),
 	}
 	// update changed.Links based on notifications
 	addChanges := func(ctx convtypes.ResourceType, ns, n string) {
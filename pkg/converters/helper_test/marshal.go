@@ -17,6 +17,7 @@ limitations under the License.
 package helper_test
 
 import (
+	"fmt"
 	"sort"
 
 	yaml "gopkg.in/yaml.v2"
@@ -33,6 +34,7 @@ type (
 		BalanceAlgorithm string            `yaml:",omitempty"`
 		MaxConnServer    int               `yaml:",omitempty"`
 		ModeTCP          bool              `yaml:",omitempty"`
+		Protocol         string            `yaml:",omitempty"`
 	}
 	backendPathMock struct {
 		Path        string
@@ -55,16 +57,28 @@ type (
 		Passthrough  bool    `yaml:",omitempty"`
 	}
 	pathMock struct {
-		Path      string
-		Match     string        `yaml:",omitempty"`
-		Headers   []headersMock `yaml:",omitempty"`
-		BackendID string        `yaml:"backend"`
+		Path       string
+		Match      string              `yaml:",omitempty"`
+		Method     string              `yaml:",omitempty"`
+		Headers    []headersMock       `yaml:",omitempty"`
+		Query      []headersMock       `yaml:",omitempty"`
+		BackendID  string              `yaml:"backend,omitempty"`
+		ReqHeaders *headerModifierMock `yaml:",omitempty"`
+		ResHeaders *headerModifierMock `yaml:",omitempty"`
+		Redirect   string              `yaml:",omitempty"`
+		Rewrite    string              `yaml:",omitempty"`
+		Mirror     string              `yaml:",omitempty"`
 	}
 	headersMock struct {
 		Name  string
 		Value string
 		Regex bool
 	}
+	headerModifierMock struct {
+		Add    []string `yaml:",omitempty"`
+		Set    []string `yaml:",omitempty"`
+		Remove []string `yaml:",omitempty"`
+	}
 	tlsMock struct {
 		TLSFilename string `yaml:",omitempty"`
 		CAFilename  string `yaml:",omitempty"`
@@ -113,6 +127,7 @@ func marshalBackends(weight bool, habackends ...*hatypes.Backend) []backendMock
 			BalanceAlgorithm: b.BalanceAlgorithm,
 			MaxConnServer:    b.Server.MaxConn,
 			ModeTCP:          b.ModeTCP,
+			Protocol:         b.Server.Protocol,
 		})
 	}
 	return backends
@@ -146,7 +161,31 @@ func marshalHosts(hafronts ...*hatypes.Host) []hostMock {
 					Value: h.Value,
 				})
 			}
-			paths = append(paths, pathMock{Path: p.Path(), Match: match, Headers: hmock, BackendID: p.Backend.ID})
+			var qmock []headersMock
+			for _, q := range p.QueryParams() {
+				qmock = append(qmock, headersMock{
+					Regex: q.Regex,
+					Name:  q.Name,
+					Value: q.Value,
+				})
+			}
+			var backendID string
+			if p.Backend != nil {
+				backendID = p.Backend.ID
+			}
+			paths = append(paths, pathMock{
+				Path:       p.Path(),
+				Match:      match,
+				Method:     p.HTTPMethod(),
+				Headers:    hmock,
+				Query:      qmock,
+				BackendID:  backendID,
+				ReqHeaders: marshalHeaderModifier(p.ReqHeaders),
+				ResHeaders: marshalHeaderModifier(p.ResHeaders),
+				Redirect:   marshalRedirect(p),
+				Rewrite:    marshalRewrite(p),
+				Mirror:     marshalMirror(p.Mirror),
+			})
 		}
 		hosts = append(hosts, hostMock{
 			Hostname:     f.Hostname,
@@ -159,6 +198,44 @@ func marshalHosts(hafronts ...*hatypes.Host) []hostMock {
 	return hosts
 }
 
+func marshalHeaderModifier(modifier hatypes.HTTPHeaderModifier) *headerModifierMock {
+	if len(modifier.Add) == 0 && len(modifier.Set) == 0 && len(modifier.Remove) == 0 {
+		return nil
+	}
+	mock := &headerModifierMock{Remove: modifier.Remove}
+	for _, h := range modifier.Add {
+		mock.Add = append(mock.Add, h.Name+": "+h.Value)
+	}
+	for _, h := range modifier.Set {
+		mock.Set = append(mock.Set, h.Name+": "+h.Value)
+	}
+	return mock
+}
+
+func marshalRedirect(path *hatypes.Path) string {
+	if path.RedirTo == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d %s", path.RedirToCode, path.RedirTo)
+}
+
+func marshalRewrite(path *hatypes.Path) string {
+	if path.RewriteURL == "" {
+		return ""
+	}
+	if path.RewriteFull {
+		return "path " + path.RewriteURL
+	}
+	return "prefix " + path.RewriteURL
+}
+
+func marshalMirror(mirror hatypes.Mirror) string {
+	if mirror.BackendID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s %d%%", mirror.BackendID, mirror.Percent)
+}
+
 // MarshalTCPServices ...
 func MarshalTCPServices(hatcpserviceports ...*hatypes.TCPServicePort) string {
 	tcpServices := []tcpServiceMock{}
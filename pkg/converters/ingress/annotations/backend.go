@@ -26,6 +26,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	ingtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
 	ingutils "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/utils"
@@ -382,6 +383,111 @@ func extractUserlist(source, secret, users string) ([]hatypes.User, []error) {
 	return userlist, err
 }
 
+var (
+	// jwtAlgorithms maps the supported signature algorithms to the type of their public keys
+	jwtAlgorithms = map[string]string{
+		"RS256": "RSA", "RS384": "RSA", "RS512": "RSA",
+		"PS256": "RSA", "PS384": "RSA", "PS512": "RSA",
+		"ES256": "EC", "ES384": "EC", "ES512": "EC",
+	}
+	jwtKeyIDRegex      = regexp.MustCompile(`^[A-Za-z0-9_.+/=-]+$`)
+	jwtHeaderNameRegex = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+	jwtClaimRegex      = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	jwtClaimValueRegex = regexp.MustCompile(`^[^"'\\#\s]*$`)
+)
+
+func (c *updater) buildBackendAuthJWT(d *backData) {
+	if d.backend.ModeTCP {
+		return
+	}
+	for _, path := range d.backend.Paths {
+		config := d.mapper.GetConfig(path.Link)
+		key := config.Get(ingtypes.BackAuthJWTKey)
+		if key.Value == "" {
+			continue
+		}
+		// requests should be denied if the configuration fails,
+		// AlwaysDeny will be changed to false if the configuration succeed
+		jwt := &path.AuthJWT
+		jwt.AlwaysDeny = true
+
+		if !c.haproxy.Global().HAProxy.Supports(hatypes.FeatureJWT) {
+			c.logger.Warn("denying requests on %s: JWT validation needs haproxy 2.5 or newer", key.Source.String())
+			continue
+		}
+		var namespace string
+		if key.Source != nil {
+			namespace = key.Source.Namespace
+		}
+		keys, err := c.cache.GetJWTKeyPath(
+			namespace,
+			key.Value,
+			[]convtypes.TrackingRef{{Context: convtypes.ResourceHABackend, UniqueName: d.backend.ID}},
+		)
+		if err != nil {
+			c.logger.Error("denying requests on %s: error reading JWT keys: %v", key.Source.String(), err)
+			continue
+		}
+
+		algorithms := config.Get(ingtypes.BackAuthJWTAlgorithms)
+		var verifiers []hatypes.AuthJWTVerifier
+		for _, alg := range splitList(algorithms.Value) {
+			keyType, found := jwtAlgorithms[alg]
+			if !found {
+				c.logger.Warn("ignoring unsupported JWT algorithm on %s: %s", algorithms.Source.String(), alg)
+				continue
+			}
+			for _, k := range keys {
+				if k.KeyType != keyType || (k.Algorithm != "" && k.Algorithm != alg) {
+					continue
+				}
+				if k.KeyID != "" && !jwtKeyIDRegex.MatchString(k.KeyID) {
+					c.logger.Warn("ignoring JWT key with an invalid key ID on %s: %s", key.Source.String(), k.KeyID)
+					continue
+				}
+				verifiers = append(verifiers, hatypes.AuthJWTVerifier{
+					Algorithm: alg,
+					KeyFile:   k.Filename,
+					KeyHash:   k.SHA1Hash,
+					KeyID:     k.KeyID,
+				})
+			}
+		}
+		if len(verifiers) == 0 {
+			c.logger.Warn("denying requests on %s: no JWT key matches the allowed algorithms: %s", key.Source.String(), algorithms.Value)
+			continue
+		}
+
+		issuer := config.Get(ingtypes.BackAuthJWTIssuer)
+		if !jwtClaimValueRegex.MatchString(issuer.Value) {
+			c.logger.Warn("denying requests on %s: invalid JWT issuer: %s", issuer.Source.String(), issuer.Value)
+			continue
+		}
+		audience := config.Get(ingtypes.BackAuthJWTAudience)
+		if !jwtClaimValueRegex.MatchString(audience.Value) {
+			c.logger.Warn("denying requests on %s: invalid JWT audience: %s", audience.Source.String(), audience.Value)
+			continue
+		}
+
+		headers := config.Get(ingtypes.BackAuthJWTHeaders)
+		var jwtHeaders []hatypes.AuthJWTHeader
+		for _, header := range utils.Split(headers.Value, ",") {
+			name, claim, _ := strings.Cut(header, ":")
+			if !jwtHeaderNameRegex.MatchString(name) || !jwtClaimRegex.MatchString(claim) {
+				c.logger.Warn("ignoring JWT header on %s: invalid header name or claim: %s", headers.Source.String(), header)
+				continue
+			}
+			jwtHeaders = append(jwtHeaders, hatypes.AuthJWTHeader{Claim: claim, Name: name})
+		}
+
+		jwt.AlwaysDeny = false
+		jwt.Audience = audience.Value
+		jwt.Headers = jwtHeaders
+		jwt.Issuer = issuer.Value
+		jwt.Verifiers = verifiers
+	}
+}
+
 func (c *updater) buildBackendBlueGreenBalance(d *backData) {
 	balance := d.mapper.Get(ingtypes.BackBlueGreenBalance)
 	if balance.Source == nil || balance.Value == "" {
@@ -551,6 +657,120 @@ func (c *updater) buildBackendBodySize(d *backData) {
 	}
 }
 
+func (c *updater) buildBackendCache(d *backData) {
+	if d.backend.ModeTCP {
+		return
+	}
+	version := c.haproxy.Global().HAProxy
+	for _, path := range d.backend.Paths {
+		config := d.mapper.GetConfig(path.Link)
+		if !config.Get(ingtypes.BackCacheEnable).Bool() {
+			continue
+		}
+		sizeCfg := config.Get(ingtypes.BackCacheSize)
+		size, err := utils.SizeSuffixToInt64(sizeCfg.Value)
+		if err != nil || size < 1<<20 || size >= 4095<<20 {
+			c.logger.Warn("ignoring cache on %v: invalid cache size, expected from 1m to 4094m: %s", sizeCfg.Source, sizeCfg.Value)
+			continue
+		}
+		maxAgeCfg := config.Get(ingtypes.BackCacheMaxAge)
+		maxAge, err := strconv.Atoi(maxAgeCfg.Value)
+		if err != nil {
+			var duration time.Duration
+			duration, err = time.ParseDuration(maxAgeCfg.Value)
+			maxAge = int(duration.Seconds())
+		}
+		if err != nil || maxAge <= 0 {
+			c.logger.Warn("ignoring cache on %v: invalid max age: %s", maxAgeCfg.Source, maxAgeCfg.Value)
+			continue
+		}
+		var maxObjectSize int64
+		if maxObjectSizeCfg := config.Get(ingtypes.BackCacheMaxObjectSize); maxObjectSizeCfg.Value != "" {
+			maxObjectSize, err = utils.SizeSuffixToInt64(maxObjectSizeCfg.Value)
+			if err != nil || maxObjectSize > size/2 {
+				c.logger.Warn("ignoring invalid cache max object size on %v, should not be greater than half of the cache size: %s", maxObjectSizeCfg.Source, maxObjectSizeCfg.Value)
+				maxObjectSize = 0
+			}
+		}
+		varyCfg := config.Get(ingtypes.BackCacheVary)
+		var vary bool
+		for _, header := range splitList(varyCfg.Value) {
+			switch strings.ToLower(header) {
+			case "accept-encoding", "referer":
+				vary = true
+			default:
+				c.logger.Warn("ignoring unsupported cache vary header on %v: %s", varyCfg.Source, header)
+			}
+		}
+		if vary && !version.Supports(hatypes.FeatureCacheVary) {
+			c.logger.Warn("ignoring cache vary on %v: not supported on haproxy %s", varyCfg.Source, version)
+			vary = false
+		}
+		path.Cache = hatypes.Cache{
+			Enabled:       true,
+			MaxAge:        maxAge,
+			MaxObjectSize: maxObjectSize,
+			ProcessVary:   vary,
+			TotalMaxSize:  int(size >> 20),
+		}
+	}
+}
+
+func (c *updater) buildBackendCompression(d *backData) {
+	if d.backend.ModeTCP || !d.mapper.Get(ingtypes.BackCompressionEnable).Bool() {
+		return
+	}
+	algoCfg := d.mapper.Get(ingtypes.BackCompressionAlgo)
+	var algos []string
+	for _, algo := range splitList(algoCfg.Value) {
+		switch algo {
+		case "gzip", "deflate", "raw-deflate":
+			algos = append(algos, algo)
+		default:
+			c.logger.Warn("ignoring invalid compression algorithm on %v: %s", algoCfg.Source, algo)
+		}
+	}
+	if len(algos) == 0 {
+		c.logger.Warn("ignoring compression on %v: no valid compression algorithm", algoCfg.Source)
+		return
+	}
+	version := c.haproxy.Global().HAProxy
+	direction := d.mapper.Get(ingtypes.BackCompressionDirection)
+	switch direction.Value {
+	case "", "response":
+	case "request", "both":
+		if !version.Supports(hatypes.FeatureCompressionReq) {
+			c.logger.Warn("ignoring compression direction on %v: request compression is not supported on haproxy %s", direction.Source, version)
+			direction.Value = ""
+		}
+	default:
+		c.logger.Warn("ignoring invalid compression direction on %v: %s", direction.Source, direction.Value)
+		direction.Value = ""
+	}
+	var minsize int64
+	if minsizeCfg := d.mapper.Get(ingtypes.BackCompressionMinSize); minsizeCfg.Value != "" {
+		value, err := utils.SizeSuffixToInt64(minsizeCfg.Value)
+		if err != nil {
+			c.logger.Warn("ignoring invalid compression min size on %v: %s", minsizeCfg.Source, minsizeCfg.Value)
+		} else if !version.Supports(hatypes.FeatureCompressionMinSize) {
+			c.logger.Warn("ignoring compression min size on %v: not supported on haproxy %s", minsizeCfg.Source, version)
+		} else {
+			minsize = value
+		}
+	}
+	d.backend.Compression = hatypes.Compression{
+		Algos:     algos,
+		Direction: direction.Value,
+		MinSize:   minsize,
+		Types:     splitList(d.mapper.Get(ingtypes.BackCompressionTypes).Value),
+	}
+}
+
+// splitList splits a comma or space separated list
+func splitList(list string) []string {
+	return strings.Fields(strings.ReplaceAll(list, ",", " "))
+}
+
 func (c *updater) buildBackendCors(d *backData) {
 	for _, path := range d.backend.Paths {
 		config := d.mapper.GetConfig(path.Link)
@@ -580,32 +800,41 @@ func (c *updater) buildBackendCustomConfig(d *backData) {
 	configBackendLate := d.mapper.Get(ingtypes.BackConfigBackendLate)
 	configBackend := d.mapper.Get(ingtypes.BackConfigBackend)
 
+	keyBackendLate := ingtypes.BackConfigBackendLate
 	if configBackendLate.Value == "" {
 		// act as an alias for backward compatibility
 		configBackendLate = configBackend
+		keyBackendLate = ingtypes.BackConfigBackend
 	} else if configBackend.Value != "" {
 		c.logger.Warn("both config-backend and config-backend-late were used on %v, ignoring config-backend", configBackend.Source)
 	}
 
-	d.backend.CustomConfigEarly = c.generateBackendSnippet(d, configBackendEarly)
-	d.backend.CustomConfigLate = c.generateBackendSnippet(d, configBackendLate)
+	d.backend.CustomConfigEarly, d.backend.CustomConfigSources.Early = c.generateBackendSnippet(d, ingtypes.BackConfigBackendEarly, configBackendEarly)
+	d.backend.CustomConfigLate, d.backend.CustomConfigSources.Late = c.generateBackendSnippet(d, keyBackendLate, configBackendLate)
 }
 
-func (c *updater) generateBackendSnippet(d *backData, config *ConfigValue) []string {
+func (c *updater) generateBackendSnippet(d *backData, key string, config *ConfigValue) ([]string, *hatypes.SnippetSource) {
 	lines := utils.PatternLineToSlice(d.vars, config.Value)
 	if len(lines) == 0 {
-		return nil
+		return nil, nil
 	}
 	source := "global config"
+	var snippetSource *hatypes.SnippetSource
 	if config.Source != nil {
 		source = config.Source.String()
+		snippetSource = hatypes.NewSnippetSource(string(config.Source.Type), config.Source.Namespace, config.Source.Name, key, lines)
+		if reason, found := c.haproxy.Quarantine().Reason(snippetSource); found {
+			c.logger.Warn("skipping configuration snippet on %s: rejected by haproxy: %s", source, reason)
+			// the source is still used, so the snippet is kept in the quarantine
+			return nil, snippetSource
+		}
 	}
 	for _, line := range lines {
 		for _, token := range strings.Fields(line) {
 			if strings.Contains(path.Clean(token), "secrets/kubernetes.io/serviceaccount") {
 				// attempt to read the well known path to Kubernetes credentials
 				c.logger.Warn("skipping configuration snippet on %s: attempt to read cluster credentials", source)
-				return nil
+				return nil, nil
 			}
 		}
 		for _, disableKeyword := range c.options.DisableKeywords {
@@ -614,15 +843,15 @@ func (c *updater) generateBackendSnippet(d *backData, config *ConfigValue) []str
 			}
 			if disableKeyword == "*" {
 				c.logger.Warn("skipping configuration snippet on %s: custom configuration is disabled", source)
-				return nil
+				return nil, nil
 			}
 			if firstToken(line) == disableKeyword {
 				c.logger.Warn("skipping configuration snippet on %s: keyword '%s' not allowed", source, disableKeyword)
-				return nil
+				return nil, nil
 			}
 		}
 	}
-	return lines
+	return lines, snippetSource
 }
 
 // kindly provided by strings/strings.go
@@ -734,6 +963,51 @@ func (c *updater) buildBackendLimit(d *backData) {
 	d.backend.Limit.Whitelist = c.splitCIDR(d.mapper.Get(ingtypes.BackLimitWhitelist))
 }
 
+func (c *updater) buildBackendMirror(d *backData) {
+	if d.backend.ModeTCP {
+		return
+	}
+	for _, path := range d.backend.Paths {
+		config := d.mapper.GetConfig(path.Link)
+		service := config.Get(ingtypes.BackMirrorService)
+		if service.Value == "" {
+			continue
+		}
+		if service.Source == nil {
+			c.logger.Warn("ignoring mirror-service on %s: a globally configured mirror service is not supported", service.Source.String())
+			continue
+		}
+		if len(c.haproxy.Global().Mirror.Endpoints) == 0 {
+			c.logger.Warn("ignoring mirror-service on %s: mirror agent endpoints are not configured", service.Source.String())
+			continue
+		}
+		name, port, found := strings.Cut(service.Value, ":")
+		if !found || name == "" || port == "" {
+			c.logger.Warn("ignoring mirror-service on %s: invalid service name and port: %s", service.Source.String(), service.Value)
+			continue
+		}
+		percentage := config.Get(ingtypes.BackMirrorPercentage)
+		percent := percentage.Int()
+		if percent < 1 || percent > 100 {
+			c.logger.Warn("ignoring mirror-service on %s: invalid mirror percentage: %s", percentage.Source.String(), percentage.Value)
+			continue
+		}
+		backend := c.haproxy.Backends().FindBackend(service.Source.Namespace, name, port)
+		if backend == nil {
+			// warn was already logged in the ingress if a service couldn't be found,
+			// see the auth-url counterpart in buildBackendAuthExternal()
+			c.logger.Warn("ignoring mirror-service on %s: service '%s:%s' was not found", service.Source.String(), name, port)
+			continue
+		}
+		if backend.ID == d.backend.ID {
+			c.logger.Warn("ignoring mirror-service on %s: a backend cannot mirror to itself", service.Source.String())
+			continue
+		}
+		path.Mirror.BackendID = backend.ID
+		path.Mirror.Percent = percent
+	}
+}
+
 func (c *updater) buildBackendOAuth(d *backData) {
 	for _, path := range d.backend.Paths {
 		config := d.mapper.GetConfig(path.Link)
@@ -958,6 +1232,43 @@ func (c *updater) buildBackendProxyProtocol(d *backData) {
 	}
 }
 
+var retryOnConditions = []string{
+	"none", "conn-failure", "empty-response", "junk-response", "response-timeout", "0rtt-rejected", "all-retryable-errors",
+	"401", "403", "404", "408", "425", "500", "501", "502", "503", "504",
+}
+
+func (c *updater) buildBackendRetry(d *backData) {
+	if retries := d.mapper.Get(ingtypes.BackRetries); retries.Value != "" {
+		if value, err := strconv.Atoi(retries.Value); err != nil || value < 0 {
+			c.logger.Warn("ignoring invalid retries on %v: %s", retries.Source, retries.Value)
+		} else {
+			d.backend.Retry.Retries = strconv.Itoa(value)
+		}
+	}
+	if retryOn := d.mapper.Get(ingtypes.BackRetryOn); retryOn.Value != "" {
+		conditions := splitList(retryOn.Value)
+		for _, cond := range conditions {
+			if !slices.Contains(retryOnConditions, cond) {
+				c.logger.Warn("ignoring retry-on on %v: invalid condition '%s'", retryOn.Source, cond)
+				conditions = nil
+				break
+			}
+		}
+		if d.backend.ModeTCP {
+			c.logger.Warn("ignoring retry-on on %v: only supported on HTTP backends", retryOn.Source)
+			conditions = nil
+		}
+		d.backend.Retry.RetryOn = conditions
+	}
+	if redispatch := d.mapper.Get(ingtypes.BackRedispatch); redispatch.Value != "" {
+		if value, err := strconv.ParseBool(redispatch.Value); err != nil {
+			c.logger.Warn("ignoring invalid redispatch on %v: %s", redispatch.Source, redispatch.Value)
+		} else {
+			d.backend.Retry.Redispatch = strconv.FormatBool(value)
+		}
+	}
+}
+
 func (c *updater) buildBackendRewriteURL(d *backData) {
 	for _, path := range d.backend.Paths {
 		config := d.mapper.GetConfig(path.Link)
@@ -18,7 +18,9 @@ package annotations
 
 import (
 	"fmt"
+	"maps"
 	"net"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -758,6 +760,273 @@ usr2::clearpwd2`)}},
 	}
 }
 
+func TestAuthJWT(t *testing.T) {
+	annDefault := map[string]string{
+		ingtypes.BackAuthJWTAlgorithms: "RS256",
+	}
+	haproxy28 := hatypes.HAProxyVersion{Version: "2.8.10", Major: 2, Minor: 8}
+	keys := map[string][]types.JWTKeyFile{
+		"default/jwks": {
+			{Filename: "/var/lib/haproxy/jwtkeys/default_jwks_0.pem", SHA1Hash: "a1", KeyID: "key1", Algorithm: "RS256", KeyType: "RSA"},
+			{Filename: "/var/lib/haproxy/jwtkeys/default_jwks_1.pem", KeyID: "key2", KeyType: "EC"},
+		},
+		"configmap://default/jwtpem": {
+			{Filename: "/var/lib/haproxy/jwtkeys/cm_default_jwtpem_0.pem", KeyType: "RSA"},
+		},
+		"default/badkid": {
+			{Filename: "/var/lib/haproxy/jwtkeys/default_badkid_0.pem", KeyID: "key 1", KeyType: "RSA"},
+		},
+	}
+	testCases := []struct {
+		ann      map[string]map[string]string
+		version  hatypes.HAProxyVersion
+		modeTCP  bool
+		expected map[string]hatypes.AuthJWT
+		logging  string
+	}{
+		// 0
+		{
+			ann: map[string]map[string]string{
+				"/": {},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {},
+			},
+		},
+		// 1
+		{
+			ann: map[string]map[string]string{
+				"/": {},
+				"/api": {
+					ingtypes.BackAuthJWTKey:      "jwks",
+					ingtypes.BackAuthJWTIssuer:   "https://issuer.local/",
+					ingtypes.BackAuthJWTAudience: "api",
+					ingtypes.BackAuthJWTHeaders:  "X-User:sub,X-Email:email",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {},
+				"/api": {
+					Audience: "api",
+					Headers: []hatypes.AuthJWTHeader{
+						{Claim: "sub", Name: "X-User"},
+						{Claim: "email", Name: "X-Email"},
+					},
+					Issuer: "https://issuer.local/",
+					Verifiers: []hatypes.AuthJWTVerifier{
+						{Algorithm: "RS256", KeyFile: "/var/lib/haproxy/jwtkeys/default_jwks_0.pem", KeyHash: "a1", KeyID: "key1"},
+					},
+				},
+			},
+		},
+		// 2
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:        "jwks",
+					ingtypes.BackAuthJWTAlgorithms: "RS256,ES256,HS256",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {
+					Verifiers: []hatypes.AuthJWTVerifier{
+						{Algorithm: "RS256", KeyFile: "/var/lib/haproxy/jwtkeys/default_jwks_0.pem", KeyHash: "a1", KeyID: "key1"},
+						{Algorithm: "ES256", KeyFile: "/var/lib/haproxy/jwtkeys/default_jwks_1.pem", KeyID: "key2"},
+					},
+				},
+			},
+			logging: `WARN ignoring unsupported JWT algorithm on ingress 'default/ing1': HS256`,
+		},
+		// 3
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:        "configmap://jwtpem",
+					ingtypes.BackAuthJWTAlgorithms: "RS256,PS512",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {
+					Verifiers: []hatypes.AuthJWTVerifier{
+						{Algorithm: "RS256", KeyFile: "/var/lib/haproxy/jwtkeys/cm_default_jwtpem_0.pem"},
+						{Algorithm: "PS512", KeyFile: "/var/lib/haproxy/jwtkeys/cm_default_jwtpem_0.pem"},
+					},
+				},
+			},
+		},
+		// 4
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey: "jwks",
+				},
+			},
+			expected: map[string]hatypes.AuthJWT{
+				"/": {AlwaysDeny: true},
+			},
+			logging: `WARN denying requests on ingress 'default/ing1': JWT validation needs haproxy 2.5 or newer`,
+		},
+		// 5
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey: "notfound",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {AlwaysDeny: true},
+			},
+			logging: `ERROR denying requests on ingress 'default/ing1': error reading JWT keys: secret not found: 'default/notfound'`,
+		},
+		// 6
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:        "jwks",
+					ingtypes.BackAuthJWTAlgorithms: "PS256",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {AlwaysDeny: true},
+			},
+			logging: `WARN denying requests on ingress 'default/ing1': no JWT key matches the allowed algorithms: PS256`,
+		},
+		// 7
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey: "badkid",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {AlwaysDeny: true},
+			},
+			logging: `
+WARN ignoring JWT key with an invalid key ID on ingress 'default/ing1': key 1
+WARN denying requests on ingress 'default/ing1': no JWT key matches the allowed algorithms: RS256`,
+		},
+		// 8
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:    "jwks",
+					ingtypes.BackAuthJWTIssuer: "https://issuer.local/ x",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {AlwaysDeny: true},
+			},
+			logging: `WARN denying requests on ingress 'default/ing1': invalid JWT issuer: https://issuer.local/ x`,
+		},
+		// 9
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:     "jwks",
+					ingtypes.BackAuthJWTHeaders: "X-User:sub,X User:name,X-Role:",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {
+					Headers: []hatypes.AuthJWTHeader{
+						{Claim: "sub", Name: "X-User"},
+					},
+					Verifiers: []hatypes.AuthJWTVerifier{
+						{Algorithm: "RS256", KeyFile: "/var/lib/haproxy/jwtkeys/default_jwks_0.pem", KeyHash: "a1", KeyID: "key1"},
+					},
+				},
+			},
+			logging: `
+WARN ignoring JWT header on ingress 'default/ing1': invalid header name or claim: X User:name
+WARN ignoring JWT header on ingress 'default/ing1': invalid header name or claim: X-Role:`,
+		},
+		// 10
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey: "jwks",
+				},
+			},
+			version: haproxy28,
+			modeTCP: true,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {},
+			},
+		},
+		// 11
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:      "jwks",
+					ingtypes.BackAuthJWTAudience: "urn:my:api",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {
+					Audience: "urn:my:api",
+					Verifiers: []hatypes.AuthJWTVerifier{
+						{Algorithm: "RS256", KeyFile: "/var/lib/haproxy/jwtkeys/default_jwks_0.pem", KeyHash: "a1", KeyID: "key1"},
+					},
+				},
+			},
+		},
+		// 12
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:      "jwks",
+					ingtypes.BackAuthJWTAudience: "my#api",
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {AlwaysDeny: true},
+			},
+			logging: `WARN denying requests on ingress 'default/ing1': invalid JWT audience: my#api`,
+		},
+		// 13
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackAuthJWTKey:    "jwks",
+					ingtypes.BackAuthJWTIssuer: `"issuer"`,
+				},
+			},
+			version: haproxy28,
+			expected: map[string]hatypes.AuthJWT{
+				"/": {AlwaysDeny: true},
+			},
+			logging: `WARN denying requests on ingress 'default/ing1': invalid JWT issuer: "issuer"`,
+		},
+	}
+	source := &Source{Namespace: "default", Name: "ing1", Type: "ingress"}
+	for i, test := range testCases {
+		c := setup(t)
+		c.haproxy.Global().HAProxy = test.version
+		c.cache.JWTKeyPath = keys
+		d := c.createBackendMappingData("default/app", source, annDefault, test.ann, []string{})
+		d.backend.ModeTCP = test.modeTCP
+		c.createUpdater().buildBackendAuthJWT(d)
+		actual := map[string]hatypes.AuthJWT{}
+		for _, path := range d.backend.Paths {
+			actual[path.Path()] = path.AuthJWT
+		}
+		c.compareObjects("auth jwt", i, actual, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestBlueGreen(t *testing.T) {
 	buildPod := func(labels string) *api.Pod {
 		l := make(map[string]string)
@@ -1251,6 +1520,257 @@ func TestBodySize(t *testing.T) {
 	}
 }
 
+func TestCache(t *testing.T) {
+	annDefault := map[string]string{
+		ingtypes.BackCacheMaxAge: "60s",
+		ingtypes.BackCacheSize:   "64m",
+	}
+	testCases := []struct {
+		ann      map[string]map[string]string
+		version  hatypes.HAProxyVersion
+		expected map[string]hatypes.Cache
+		logging  string
+	}{
+		// 0
+		{
+			ann: map[string]map[string]string{
+				"/": {},
+			},
+			expected: map[string]hatypes.Cache{
+				"/": {},
+			},
+		},
+		// 1
+		{
+			ann: map[string]map[string]string{
+				"/": {},
+				"/static": {
+					ingtypes.BackCacheEnable: "true",
+				},
+			},
+			expected: map[string]hatypes.Cache{
+				"/":       {},
+				"/static": {Enabled: true, MaxAge: 60, TotalMaxSize: 64},
+			},
+		},
+		// 2
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackCacheEnable:        "true",
+					ingtypes.BackCacheMaxAge:        "300",
+					ingtypes.BackCacheMaxObjectSize: "1m",
+					ingtypes.BackCacheSize:          "128m",
+				},
+				"/static": {
+					ingtypes.BackCacheEnable: "true",
+					ingtypes.BackCacheMaxAge: "1h",
+				},
+			},
+			expected: map[string]hatypes.Cache{
+				"/":       {Enabled: true, MaxAge: 300, MaxObjectSize: 1048576, TotalMaxSize: 128},
+				"/static": {Enabled: true, MaxAge: 3600, TotalMaxSize: 64},
+			},
+		},
+		// 3
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackCacheEnable: "true",
+					ingtypes.BackCacheSize:   "100k",
+				},
+				"/static": {
+					ingtypes.BackCacheEnable: "true",
+					ingtypes.BackCacheMaxAge: "1x",
+				},
+			},
+			expected: map[string]hatypes.Cache{
+				"/":       {},
+				"/static": {},
+			},
+			logging: `
+WARN ignoring cache on ingress 'default/ing1': invalid cache size, expected from 1m to 4094m: 100k
+WARN ignoring cache on ingress 'default/ing1': invalid max age: 1x`,
+		},
+		// 4
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackCacheEnable:        "true",
+					ingtypes.BackCacheMaxObjectSize: "40m",
+					ingtypes.BackCacheVary:          "accept-encoding,user-agent",
+				},
+			},
+			version: hatypes.HAProxyVersion{Version: "2.8.10", Major: 2, Minor: 8},
+			expected: map[string]hatypes.Cache{
+				"/": {Enabled: true, MaxAge: 60, ProcessVary: true, TotalMaxSize: 64},
+			},
+			logging: `
+WARN ignoring invalid cache max object size on ingress 'default/ing1', should not be greater than half of the cache size: 40m
+WARN ignoring unsupported cache vary header on ingress 'default/ing1': user-agent`,
+		},
+		// 5
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackCacheEnable: "true",
+					ingtypes.BackCacheVary:   "Referer",
+				},
+			},
+			expected: map[string]hatypes.Cache{
+				"/": {Enabled: true, MaxAge: 60, TotalMaxSize: 64},
+			},
+			logging: `WARN ignoring cache vary on ingress 'default/ing1': not supported on haproxy <unknown>`,
+		},
+	}
+	source := &Source{Namespace: "default", Name: "ing1", Type: "ingress"}
+	for i, test := range testCases {
+		c := setup(t)
+		c.haproxy.Global().HAProxy = test.version
+		d := c.createBackendMappingData("default/app", source, annDefault, test.ann, []string{})
+		c.createUpdater().buildBackendCache(d)
+		actual := map[string]hatypes.Cache{}
+		for _, path := range d.backend.Paths {
+			actual[path.Path()] = path.Cache
+		}
+		c.compareObjects("cache", i, actual, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
+func TestCompression(t *testing.T) {
+	annDefault := map[string]string{
+		ingtypes.BackCompressionAlgo:      "gzip",
+		ingtypes.BackCompressionDirection: "response",
+		ingtypes.BackCompressionTypes:     "text/html text/plain",
+	}
+	testCases := []struct {
+		ann      map[string]string
+		version  hatypes.HAProxyVersion
+		modeTCP  bool
+		expected hatypes.Compression
+		logging  string
+	}{
+		// 0
+		{},
+		// 1
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable: "true",
+			},
+			expected: hatypes.Compression{
+				Algos:     []string{"gzip"},
+				Direction: "response",
+				Types:     []string{"text/html", "text/plain"},
+			},
+		},
+		// 2
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable: "true",
+			},
+			modeTCP: true,
+		},
+		// 3
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable: "true",
+				ingtypes.BackCompressionAlgo:   "deflate, gzip,br",
+				ingtypes.BackCompressionTypes:  "application/json,text/css",
+			},
+			expected: hatypes.Compression{
+				Algos:     []string{"deflate", "gzip"},
+				Direction: "response",
+				Types:     []string{"application/json", "text/css"},
+			},
+			logging: `WARN ignoring invalid compression algorithm on ingress 'default/ing1': br`,
+		},
+		// 4
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable: "true",
+				ingtypes.BackCompressionAlgo:   "br",
+			},
+			logging: `
+WARN ignoring invalid compression algorithm on ingress 'default/ing1': br
+WARN ignoring compression on ingress 'default/ing1': no valid compression algorithm`,
+		},
+		// 5
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable:    "true",
+				ingtypes.BackCompressionDirection: "both",
+				ingtypes.BackCompressionMinSize:   "1k",
+			},
+			expected: hatypes.Compression{
+				Algos: []string{"gzip"},
+				Types: []string{"text/html", "text/plain"},
+			},
+			logging: `
+WARN ignoring compression direction on ingress 'default/ing1': request compression is not supported on haproxy <unknown>
+WARN ignoring compression min size on ingress 'default/ing1': not supported on haproxy <unknown>`,
+		},
+		// 6
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable:    "true",
+				ingtypes.BackCompressionDirection: "both",
+				ingtypes.BackCompressionMinSize:   "1k",
+			},
+			version: hatypes.HAProxyVersion{Version: "2.8.10", Major: 2, Minor: 8},
+			expected: hatypes.Compression{
+				Algos:     []string{"gzip"},
+				Direction: "both",
+				Types:     []string{"text/html", "text/plain"},
+			},
+			logging: `WARN ignoring compression min size on ingress 'default/ing1': not supported on haproxy 2.8.10`,
+		},
+		// 7
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable:    "true",
+				ingtypes.BackCompressionDirection: "request",
+				ingtypes.BackCompressionMinSize:   "1k",
+			},
+			version: hatypes.HAProxyVersion{Version: "3.2.0", Major: 3, Minor: 2},
+			expected: hatypes.Compression{
+				Algos:     []string{"gzip"},
+				Direction: "request",
+				MinSize:   1024,
+				Types:     []string{"text/html", "text/plain"},
+			},
+		},
+		// 8
+		{
+			ann: map[string]string{
+				ingtypes.BackCompressionEnable:    "true",
+				ingtypes.BackCompressionDirection: "upstream",
+				ingtypes.BackCompressionMinSize:   "1x",
+			},
+			version: hatypes.HAProxyVersion{Version: "3.2.0", Major: 3, Minor: 2},
+			expected: hatypes.Compression{
+				Algos: []string{"gzip"},
+				Types: []string{"text/html", "text/plain"},
+			},
+			logging: `
+WARN ignoring invalid compression direction on ingress 'default/ing1': upstream
+WARN ignoring invalid compression min size on ingress 'default/ing1': 1x`,
+		},
+	}
+	source := &Source{Namespace: "default", Name: "ing1", Type: "ingress"}
+	for i, test := range testCases {
+		c := setup(t)
+		c.haproxy.Global().HAProxy = test.version
+		d := c.createBackendMappingData("default/app", source, annDefault, map[string]map[string]string{"/": test.ann}, []string{})
+		d.backend.ModeTCP = test.modeTCP
+		c.createUpdater().buildBackendCompression(d)
+		c.compareObjects("compression", i, d.backend.Compression, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 const (
 	corsDefaultHeaders = "DNT,X-CustomHeader,Keep-Alive,User-Agent,X-Requested-With,If-Modified-Since,Cache-Control,Content-Type,Authorization"
 	corsDefaultMethods = "GET, PUT, POST, DELETE, PATCH, OPTIONS"
@@ -1478,6 +1998,7 @@ func TestCustomConfig(t *testing.T) {
 		lateConfig  string
 		config      string
 		source      *Source
+		quarantined bool
 		expEarly    []string
 		expLate     []string
 		logging     string
@@ -1563,6 +2084,15 @@ http-request deny if { src,lua.peers_sum(%[peers_group_backend],http_req_rate) g
 				"http-request deny if { src,lua.peers_sum(default_app_8080,http_req_rate) gt 100 }",
 			},
 		},
+		// 10
+		{
+			earlyConfig: "http-request deny if { path /internal }",
+			lateConfig:  "http-request invalid-keyword",
+			source:      defaultSource,
+			quarantined: true,
+			expEarly:    []string{"http-request deny if { path /internal }"},
+			logging:     `WARN skipping configuration snippet on Ingress 'default/app': rejected by haproxy: unknown keyword`,
+		},
 	}
 	for i, test := range testCases {
 		c := setup(t)
@@ -1576,7 +2106,16 @@ http-request deny if { src,lua.peers_sum(%[peers_group_backend],http_req_rate) g
 		d := c.createBackendMappingData("default/app", test.source, map[string]string{}, ann, []string{"/"})
 		updater := c.createUpdater()
 		updater.options.DisableKeywords = test.disabled
+		var lateSource *hatypes.SnippetSource
+		if test.quarantined {
+			lateSource = hatypes.NewSnippetSource(string(test.source.Type), test.source.Namespace, test.source.Name, ingtypes.BackConfigBackendLate, []string{test.lateConfig})
+			c.haproxy.Quarantine().Add(lateSource, "unknown keyword")
+		}
 		updater.buildBackendCustomConfig(d)
+		if test.quarantined && !reflect.DeepEqual(d.backend.CustomConfigSources.Late, lateSource) {
+			// the source of a quarantined snippet should be preserved, so it is not pruned
+			t.Errorf("source of the quarantined snippet differs on %d: expected %v, actual %v", i, lateSource, d.backend.CustomConfigSources.Late)
+		}
 		c.compareObjects("custom config early", i, d.backend.CustomConfigEarly, test.expEarly)
 		c.compareObjects("custom config late", i, d.backend.CustomConfigLate, test.expLate)
 		c.logger.CompareLogging(test.logging)
@@ -1784,6 +2323,176 @@ func TestHSTS(t *testing.T) {
 	}
 }
 
+func TestMirror(t *testing.T) {
+	annDefault := map[string]string{
+		ingtypes.BackMirrorPercentage: "100",
+	}
+	testCases := []struct {
+		annDefault map[string]string
+		ann        map[string]map[string]string
+		endpoints  []string
+		modeTCP    bool
+		expected   map[string]hatypes.Mirror
+		logging    string
+	}{
+		// 0
+		{
+			ann: map[string]map[string]string{
+				"/": {},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/": {},
+			},
+		},
+		// 1
+		{
+			ann: map[string]map[string]string{
+				"/": {},
+				"/app": {
+					ingtypes.BackMirrorService: "shadow:8080",
+				},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/":    {},
+				"/app": {BackendID: "default_shadow_8080", Percent: 100},
+			},
+		},
+		// 2
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackMirrorService:    "shadow:8080",
+					ingtypes.BackMirrorPercentage: "10",
+				},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/": {BackendID: "default_shadow_8080", Percent: 10},
+			},
+		},
+		// 3
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackMirrorService: "shadow:8080",
+				},
+			},
+			expected: map[string]hatypes.Mirror{
+				"/": {},
+			},
+			logging: `WARN ignoring mirror-service on ingress 'default/ing1': mirror agent endpoints are not configured`,
+		},
+		// 4
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackMirrorService: "shadow",
+				},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/": {},
+			},
+			logging: `WARN ignoring mirror-service on ingress 'default/ing1': invalid service name and port: shadow`,
+		},
+		// 5
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackMirrorService:    "shadow:8080",
+					ingtypes.BackMirrorPercentage: "101",
+				},
+				"/app": {
+					ingtypes.BackMirrorService:    "shadow:8080",
+					ingtypes.BackMirrorPercentage: "0",
+				},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/":    {},
+				"/app": {},
+			},
+			logging: `
+WARN ignoring mirror-service on ingress 'default/ing1': invalid mirror percentage: 101
+WARN ignoring mirror-service on ingress 'default/ing1': invalid mirror percentage: 0`,
+		},
+		// 6
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackMirrorService: "notfound:8080",
+				},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/": {},
+			},
+			logging: `WARN ignoring mirror-service on ingress 'default/ing1': service 'notfound:8080' was not found`,
+		},
+		// 7
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackMirrorService: "app:8080",
+				},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/": {},
+			},
+			logging: `WARN ignoring mirror-service on ingress 'default/ing1': a backend cannot mirror to itself`,
+		},
+		// 8
+		{
+			ann: map[string]map[string]string{
+				"/": {
+					ingtypes.BackMirrorService: "shadow:8080",
+				},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			modeTCP:   true,
+			expected: map[string]hatypes.Mirror{
+				"/": {},
+			},
+		},
+		// 9
+		{
+			annDefault: map[string]string{
+				ingtypes.BackMirrorService: "shadow:8080",
+			},
+			ann: map[string]map[string]string{
+				"/": {},
+			},
+			endpoints: []string{"10.0.0.101:12345"},
+			expected: map[string]hatypes.Mirror{
+				"/": {},
+			},
+			logging: `WARN ignoring mirror-service on <global>: a globally configured mirror service is not supported`,
+		},
+	}
+	source := &Source{Namespace: "default", Name: "ing1", Type: "ingress"}
+	for i, test := range testCases {
+		c := setup(t)
+		c.haproxy.Global().Mirror.Endpoints = test.endpoints
+		c.haproxy.Backends().AcquireBackend("default", "app", "8080")
+		c.haproxy.Backends().AcquireBackend("default", "shadow", "8080")
+		ann := maps.Clone(annDefault)
+		maps.Copy(ann, test.annDefault)
+		d := c.createBackendMappingData("default/app", source, ann, test.ann, []string{})
+		d.backend.ModeTCP = test.modeTCP
+		c.createUpdater().buildBackendMirror(d)
+		actual := map[string]hatypes.Mirror{}
+		for _, path := range d.backend.Paths {
+			actual[path.Path()] = path.Mirror
+		}
+		c.compareObjects("mirror", i, actual, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestOAuth(t *testing.T) {
 	testCases := []struct {
 		ann      map[string]map[string]string
@@ -2090,6 +2799,81 @@ WARN oauth2_proxy on ingress 'default/ing1' needs Lua json module, install lua-j
 	}
 }
 
+func TestRetry(t *testing.T) {
+	testCases := []struct {
+		annDefault map[string]string
+		ann        map[string]string
+		modeTCP    bool
+		expected   hatypes.BackendRetry
+		logging    string
+	}{
+		// 0
+		{},
+		// 1
+		{
+			ann: map[string]string{
+				ingtypes.BackRetries:    "5",
+				ingtypes.BackRetryOn:    "conn-failure,empty-response 503",
+				ingtypes.BackRedispatch: "true",
+			},
+			expected: hatypes.BackendRetry{
+				Redispatch: "true",
+				Retries:    "5",
+				RetryOn:    []string{"conn-failure", "empty-response", "503"},
+			},
+		},
+		// 2
+		{
+			annDefault: map[string]string{
+				ingtypes.BackRetries:    "2",
+				ingtypes.BackRedispatch: "true",
+			},
+			ann: map[string]string{
+				ingtypes.BackRetries:    "0",
+				ingtypes.BackRedispatch: "false",
+			},
+			expected: hatypes.BackendRetry{
+				Redispatch: "false",
+				Retries:    "0",
+			},
+		},
+		// 3
+		{
+			ann: map[string]string{
+				ingtypes.BackRetries:    "-1",
+				ingtypes.BackRetryOn:    "conn-failure,429",
+				ingtypes.BackRedispatch: "always",
+			},
+			logging: `
+WARN ignoring invalid retries on ingress 'default/ing1': -1
+WARN ignoring retry-on on ingress 'default/ing1': invalid condition '429'
+WARN ignoring invalid redispatch on ingress 'default/ing1': always`,
+		},
+		// 4
+		{
+			ann: map[string]string{
+				ingtypes.BackRetries: "3",
+				ingtypes.BackRetryOn: "conn-failure",
+			},
+			modeTCP: true,
+			expected: hatypes.BackendRetry{
+				Retries: "3",
+			},
+			logging: `WARN ignoring retry-on on ingress 'default/ing1': only supported on HTTP backends`,
+		},
+	}
+	source := &Source{Namespace: "default", Name: "ing1", Type: "ingress"}
+	for i, test := range testCases {
+		c := setup(t)
+		d := c.createBackendMappingData("default/app", source, test.annDefault, map[string]map[string]string{"/": test.ann}, []string{})
+		d.backend.ModeTCP = test.modeTCP
+		c.createUpdater().buildBackendRetry(d)
+		c.compareObjects("retry", i, d.backend.Retry, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestRewriteURL(t *testing.T) {
 	testCases := []struct {
 		source   Source
@@ -300,6 +300,10 @@ func (c *updater) buildGlobalStats(d *globalData) {
 	// prometheus
 	d.global.Prometheus.BindIP = d.mapper.Get(ingtypes.GlobalBindIPAddrPrometheus).Value
 	d.global.Prometheus.Port = d.mapper.Get(ingtypes.GlobalPrometheusPort).Int()
+	if d.global.Prometheus.Port > 0 && !d.global.HAProxy.Supports(hatypes.FeaturePromex) {
+		c.logger.Warn("ignoring prometheus-port: haproxy %s was built without the prometheus exporter", d.global.HAProxy)
+		d.global.Prometheus.Port = 0
+	}
 	// stats
 	d.global.Stats.AcceptProxy = d.mapper.Get(ingtypes.GlobalStatsProxyProtocol).Bool()
 	d.global.Stats.Auth = d.mapper.Get(ingtypes.GlobalStatsAuth).Value
@@ -388,6 +392,18 @@ func (c *updater) buildGlobalSSL(d *globalData) {
 	ssl.SSLRedirect = d.mapper.Get(ingtypes.BackSSLRedirect).Bool()
 }
 
+func (c *updater) buildGlobalMirror(d *globalData) {
+	d.global.Mirror.Endpoints = utils.Split(d.mapper.Get(ingtypes.GlobalMirrorAgentEndpoints).Value, ",")
+	d.global.Mirror.Port = d.mapper.Get(ingtypes.GlobalMirrorPort).Int()
+	if len(d.global.Mirror.Endpoints) > 0 && (d.global.Mirror.Port < 1 || d.global.Mirror.Port > 65535) {
+		c.logger.Warn("ignoring mirror agent endpoints: invalid value of mirror-port: '%s'", d.mapper.Get(ingtypes.GlobalMirrorPort).Value)
+		d.global.Mirror.Endpoints = nil
+	}
+	d.global.Mirror.Timeout.Connect = c.validateTime(d.mapper.Get(ingtypes.GlobalMirrorAgentTimeoutConnect))
+	d.global.Mirror.Timeout.Processing = c.validateTime(d.mapper.Get(ingtypes.GlobalMirrorAgentTimeoutProcessing))
+	d.global.Mirror.Timeout.Server = c.validateTime(d.mapper.Get(ingtypes.GlobalMirrorAgentTimeoutServer))
+}
+
 func (c *updater) buildGlobalModSecurity(d *globalData) {
 	d.global.ModSecurity.Endpoints = utils.Split(d.mapper.Get(ingtypes.GlobalModsecurityEndpoints).Value, ",")
 	d.global.ModSecurity.Timeout.Connect = c.validateTime(d.mapper.Get(ingtypes.GlobalModsecurityTimeoutConnect))
@@ -478,6 +494,15 @@ func (c *updater) buildGlobalForwardFor(d *globalData) {
 	d.global.RealIPHdr = validateString(headerNameRegex, ingtypes.GlobalRealIPHdr, "X-Real-IP")
 }
 
+func (c *updater) buildGlobalHTX(d *globalData) {
+	useHTX := d.mapper.Get(ingtypes.GlobalUseHTX).Bool()
+	if !useHTX && !d.global.HAProxy.Supports(hatypes.FeatureLegacyHTTP) {
+		c.logger.Warn("ignoring use-htx=false: legacy HTTP mode is not supported on haproxy %s", d.global.HAProxy)
+		useHTX = true
+	}
+	d.global.UseHTX = useHTX
+}
+
 func (c *updater) buildGlobalCustomConfig(d *globalData) {
 	d.global.CustomConfig = utils.PatternLineToSlice(c.vars, d.mapper.Get(ingtypes.GlobalConfigGlobal).Value)
 	d.global.CustomDefaults = utils.PatternLineToSlice(c.vars, d.mapper.Get(ingtypes.GlobalConfigDefaults).Value)
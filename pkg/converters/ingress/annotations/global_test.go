@@ -310,6 +310,50 @@ func TestModSecurity(t *testing.T) {
 	}
 }
 
+func TestGlobalMirror(t *testing.T) {
+	testCases := []struct {
+		endpoints string
+		port      string
+		expected  []string
+		logging   string
+	}{
+		// 0
+		{
+			endpoints: "",
+			port:      "0",
+		},
+		// 1
+		{
+			endpoints: "127.0.0.1:12345",
+			port:      "10280",
+			expected:  []string{"127.0.0.1:12345"},
+		},
+		// 2
+		{
+			endpoints: "127.0.0.1:12345",
+			port:      "0",
+			logging:   `WARN ignoring mirror agent endpoints: invalid value of mirror-port: '0'`,
+		},
+		// 3
+		{
+			endpoints: "127.0.0.1:12345",
+			port:      "65536",
+			logging:   `WARN ignoring mirror agent endpoints: invalid value of mirror-port: '65536'`,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		d := c.createGlobalData(map[string]string{
+			ingtypes.GlobalMirrorAgentEndpoints: test.endpoints,
+			ingtypes.GlobalMirrorPort:           test.port,
+		})
+		c.createUpdater().buildGlobalMirror(d)
+		c.compareObjects("mirror endpoints", i, d.global.Mirror.Endpoints, test.expected)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
+
 func TestDNS(t *testing.T) {
 	testCases := []struct {
 		config   map[string]string
@@ -1048,3 +1092,68 @@ body
 		c.teardown()
 	}
 }
+
+func TestHAProxyCapabilities(t *testing.T) {
+	haproxy20 := hatypes.HAProxyVersion{Version: "2.0.33", Major: 2, Minor: 0}
+	haproxy28 := hatypes.HAProxyVersion{Version: "2.8.10", Major: 2, Minor: 8, Services: []string{}}
+	testCases := []struct {
+		version hatypes.HAProxyVersion
+		ann     map[string]string
+		expHTX  bool
+		expProm int
+		logging string
+	}{
+		// 0
+		{
+			ann: map[string]string{
+				ingtypes.GlobalUseHTX:         "false",
+				ingtypes.GlobalPrometheusPort: "9100",
+			},
+			expHTX:  false,
+			expProm: 9100,
+		},
+		// 1
+		{
+			version: haproxy20,
+			ann: map[string]string{
+				ingtypes.GlobalUseHTX:         "false",
+				ingtypes.GlobalPrometheusPort: "9100",
+			},
+			expHTX:  false,
+			expProm: 9100,
+		},
+		// 2
+		{
+			version: haproxy28,
+			ann: map[string]string{
+				ingtypes.GlobalUseHTX: "true",
+			},
+			expHTX: true,
+		},
+		// 3
+		{
+			version: haproxy28,
+			ann: map[string]string{
+				ingtypes.GlobalUseHTX:         "false",
+				ingtypes.GlobalPrometheusPort: "9100",
+			},
+			expHTX:  true,
+			expProm: 0,
+			logging: `
+WARN ignoring use-htx=false: legacy HTTP mode is not supported on haproxy 2.8.10
+WARN ignoring prometheus-port: haproxy 2.8.10 was built without the prometheus exporter`,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		d := c.createGlobalData(test.ann)
+		d.global.HAProxy = test.version
+		u := c.createUpdater()
+		u.buildGlobalHTX(d)
+		u.buildGlobalStats(d)
+		c.compareObjects("use htx", i, d.global.UseHTX, test.expHTX)
+		c.compareObjects("prometheus port", i, d.global.Prometheus.Port, test.expProm)
+		c.logger.CompareLogging(test.logging)
+		c.teardown()
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotations
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/labels"
+
+	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/types"
+)
+
+// NamespaceLabelsFnc returns the labels of a namespace.
+type NamespaceLabelsFnc func(namespace string) (map[string]string, error)
+
+// KeyDeniedFnc is called when the configuration keys of a source, denied by the policy, change.
+type KeyDeniedFnc func(source *Source, keys []string)
+
+// SourceExistsFnc returns true if the resource of a source still exists.
+type SourceExistsFnc func(source *Source) bool
+
+// KeyPolicyReports has the configuration keys denied by the policy, by source,
+// that were already reported. It should outlive the converter, so the same
+// denied keys are not reported again on every sync.
+type KeyPolicyReports map[string]string
+
+// KeyPolicy restricts the configuration keys that Ingress and Service
+// resources are allowed to use, based on the resource namespace.
+type KeyPolicy struct {
+	logger   types.Logger
+	rules    []*keyPolicyRule
+	nsLabels NamespaceLabelsFnc
+	denied   KeyDeniedFnc
+	nsRules  map[string]*keyPolicyRule
+	reported KeyPolicyReports
+}
+
+type keyPolicyRule struct {
+	name       string
+	namespaces []string
+	selector   labels.Selector
+	allow      []string
+	deny       []string
+	invalid    bool
+}
+
+type keyPolicyRuleSpec struct {
+	Namespaces        []string `yaml:"namespaces"`
+	NamespaceSelector string   `yaml:"namespaceSelector"`
+	Allow             []string `yaml:"allow"`
+	Deny              []string `yaml:"deny"`
+}
+
+// denyAllRule is used on namespaces whose rule cannot be evaluated.
+var denyAllRule = &keyPolicyRule{deny: []string{"*"}}
+
+// NewKeyPolicy parses the annotations policy from the data of a ConfigMap.
+// Every key of the map is a rule, and rules are evaluated in the key order.
+// A nil policy is returned if data is empty, meaning that all keys are allowed.
+// reported is updated with the denied keys, a new one is used if nil.
+func NewKeyPolicy(logger types.Logger, data map[string]string, nsLabels NamespaceLabelsFnc, denied KeyDeniedFnc, reported KeyPolicyReports) *KeyPolicy {
+	if len(data) == 0 {
+		clear(reported)
+		return nil
+	}
+	if reported == nil {
+		reported = KeyPolicyReports{}
+	}
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rules := make([]*keyPolicyRule, 0, len(names))
+	for _, name := range names {
+		rule, err := parseKeyPolicyRule(name, data[name])
+		if err != nil {
+			// an invalid rule cannot tell which namespaces it applies to, so all the
+			// namespaces reaching it are denied, instead of falling back to allow all.
+			logger.Warn("annotations policy rule '%s' is invalid and denies all configuration keys: %v", name, err)
+			rule = &keyPolicyRule{name: name, invalid: true}
+		}
+		rules = append(rules, rule)
+	}
+	return &KeyPolicy{
+		logger:   logger,
+		rules:    rules,
+		nsLabels: nsLabels,
+		denied:   denied,
+		nsRules:  map[string]*keyPolicyRule{},
+		reported: reported,
+	}
+}
+
+func parseKeyPolicyRule(name, data string) (*keyPolicyRule, error) {
+	var spec keyPolicyRuleSpec
+	if err := yaml.UnmarshalStrict([]byte(data), &spec); err != nil {
+		return nil, err
+	}
+	rule := &keyPolicyRule{
+		name:       name,
+		namespaces: spec.Namespaces,
+		allow:      spec.Allow,
+		deny:       spec.Deny,
+	}
+	if spec.NamespaceSelector != "" {
+		selector, err := labels.Parse(spec.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		rule.selector = selector
+	}
+	for _, pattern := range append(slices.Clone(rule.allow), rule.deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern '%s': %w", pattern, err)
+		}
+	}
+	return rule, nil
+}
+
+// Filter returns the configuration keys that source is allowed to use. Denied
+// keys are logged and reported only when they differ from the ones already
+// reported to the same source, including the ones reported on former syncs.
+func (p *KeyPolicy) Filter(source *Source, keys map[string]string) map[string]string {
+	if p == nil || source == nil || source.Namespace == "" {
+		return keys
+	}
+	if source.Type != convtypes.ResourceIngress && source.Type != convtypes.ResourceService {
+		return keys
+	}
+	id := reportID(source)
+	if len(keys) == 0 {
+		delete(p.reported, id)
+		return keys
+	}
+	rule := p.findRule(source.Namespace)
+	if rule == nil {
+		delete(p.reported, id)
+		return keys
+	}
+	allowed := make(map[string]string, len(keys))
+	var denied []string
+	for key, value := range keys {
+		if rule.allowKey(key) {
+			allowed[key] = value
+		} else {
+			denied = append(denied, key)
+		}
+	}
+	if len(denied) == 0 {
+		delete(p.reported, id)
+		return keys
+	}
+	sort.Strings(denied)
+	report := strings.Join(denied, ",")
+	if p.reported[id] != report {
+		p.reported[id] = report
+		p.logger.Warn("ignoring configuration key(s) %v on %s: not allowed by the annotations policy", denied, source)
+		if p.denied != nil {
+			p.denied(source, denied)
+		}
+	}
+	return allowed
+}
+
+// Prune removes the denied keys reported to sources whose resource doesn't
+// exist anymore, so they do not accumulate, and are reported again if the
+// resource is recreated.
+func (p *KeyPolicy) Prune(exists SourceExistsFnc) {
+	if p == nil {
+		return
+	}
+	for id := range p.reported {
+		if source := parseReportID(id); source == nil || !exists(source) {
+			delete(p.reported, id)
+		}
+	}
+}
+
+func reportID(source *Source) string {
+	return string(source.Type) + "/" + source.FullName()
+}
+
+func parseReportID(id string) *Source {
+	s := strings.SplitN(id, "/", 3)
+	if len(s) != 3 {
+		return nil
+	}
+	return &Source{Type: convtypes.ResourceType(s[0]), Namespace: s[1], Name: s[2]}
+}
+
+func (p *KeyPolicy) findRule(namespace string) *keyPolicyRule {
+	if rule, found := p.nsRules[namespace]; found {
+		return rule
+	}
+	rule := p.matchRule(namespace)
+	p.nsRules[namespace] = rule
+	return rule
+}
+
+func (p *KeyPolicy) matchRule(namespace string) *keyPolicyRule {
+	var nsLabels labels.Set
+	var nsLabelsRead bool
+	for _, rule := range p.rules {
+		if rule.invalid {
+			return denyAllRule
+		}
+		if len(rule.namespaces) == 0 && rule.selector == nil {
+			return rule
+		}
+		if slices.Contains(rule.namespaces, namespace) {
+			return rule
+		}
+		if rule.selector == nil {
+			continue
+		}
+		if !nsLabelsRead {
+			nsLabelsRead = true
+			l, err := p.nsLabels(namespace)
+			if err != nil {
+				p.logger.Warn("denying all configuration keys of namespace '%s': error reading namespace labels: %v", namespace, err)
+				return denyAllRule
+			}
+			nsLabels = l
+		}
+		if rule.selector.Matches(nsLabels) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func (r *keyPolicyRule) allowKey(key string) bool {
+	if matchKey(r.deny, key) {
+		return false
+	}
+	return len(r.allow) == 0 || matchKey(r.allow, key)
+}
+
+func matchKey(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if match, _ := path.Match(pattern, key); match {
+			return true
+		}
+	}
+	return false
+}
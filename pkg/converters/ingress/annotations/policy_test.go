@@ -0,0 +1,308 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotations
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
+)
+
+func TestKeyPolicy(t *testing.T) {
+	nsLabels := map[string]map[string]string{
+		"team-a":  {"tenant": "trusted"},
+		"team-b":  {"tenant": "untrusted"},
+		"team-c":  {},
+		"default": {},
+	}
+	testCases := []struct {
+		policy     map[string]string
+		source     *Source
+		keys       []string
+		expKeys    []string
+		expDenied  string
+		expLogging string
+	}{
+		// 0
+		{
+			source:  &Source{Namespace: "team-b", Name: "ing1", Type: convtypes.ResourceIngress},
+			keys:    []string{"config-backend", "timeout-server"},
+			expKeys: []string{"config-backend", "timeout-server"},
+		},
+		// 1
+		{
+			policy: map[string]string{
+				"untrusted": `
+namespaceSelector: tenant=untrusted
+deny:
+- config-*
+- auth-*
+`,
+			},
+			source:     &Source{Namespace: "team-b", Name: "ing1", Type: convtypes.ResourceIngress},
+			keys:       []string{"auth-url", "config-backend", "timeout-server"},
+			expKeys:    []string{"timeout-server"},
+			expDenied:  "Ingress 'team-b/ing1': [auth-url config-backend]",
+			expLogging: "WARN ignoring configuration key(s) [auth-url config-backend] on Ingress 'team-b/ing1': not allowed by the annotations policy",
+		},
+		// 2
+		{
+			policy: map[string]string{
+				"untrusted": `
+namespaceSelector: tenant=untrusted
+deny: ["config-*"]
+`,
+			},
+			source:  &Source{Namespace: "team-a", Name: "svc1", Type: convtypes.ResourceService},
+			keys:    []string{"config-backend", "timeout-server"},
+			expKeys: []string{"config-backend", "timeout-server"},
+		},
+		// 3
+		{
+			policy: map[string]string{
+				"a-team": `
+namespaces: [team-c]
+allow: ["*"]
+`,
+				"b-default": `
+allow:
+- timeout-*
+- path-type
+`,
+			},
+			source:     &Source{Namespace: "default", Name: "svc1", Type: convtypes.ResourceService},
+			keys:       []string{"cors-allow-origin", "path-type", "timeout-server"},
+			expKeys:    []string{"path-type", "timeout-server"},
+			expDenied:  "Service 'default/svc1': [cors-allow-origin]",
+			expLogging: "WARN ignoring configuration key(s) [cors-allow-origin] on Service 'default/svc1': not allowed by the annotations policy",
+		},
+		// 4
+		{
+			policy: map[string]string{
+				"a-team": `
+namespaces: [team-c]
+allow: ["*"]
+`,
+				"b-default": `
+allow: [timeout-*]
+`,
+			},
+			source:  &Source{Namespace: "team-c", Name: "ing1", Type: convtypes.ResourceIngress},
+			keys:    []string{"cors-allow-origin", "timeout-server"},
+			expKeys: []string{"cors-allow-origin", "timeout-server"},
+		},
+		// 5
+		{
+			policy: map[string]string{
+				"default": `
+allow: [timeout-*]
+`,
+			},
+			source:  &Source{Name: "default-backend", Type: convtypes.ResourceIngress},
+			keys:    []string{"cors-allow-origin"},
+			expKeys: []string{"cors-allow-origin"},
+		},
+		// 6
+		{
+			policy: map[string]string{
+				"default": `
+allow: [timeout-*]
+`,
+			},
+			source:  &Source{Namespace: "default", Name: "echo", Type: convtypes.ResourceConfigMap},
+			keys:    []string{"cors-allow-origin"},
+			expKeys: []string{"cors-allow-origin"},
+		},
+		// 7
+		{
+			policy: map[string]string{
+				"default": `
+allowed: [timeout-*]
+`,
+			},
+			source:    &Source{Namespace: "default", Name: "ing1", Type: convtypes.ResourceIngress},
+			keys:      []string{"timeout-server"},
+			expKeys:   []string{},
+			expDenied: "Ingress 'default/ing1': [timeout-server]",
+			expLogging: `
+WARN annotations policy rule 'default' is invalid and denies all configuration keys: yaml: unmarshal errors:
+  line 2: field allowed not found in type annotations.keyPolicyRuleSpec
+WARN ignoring configuration key(s) [timeout-server] on Ingress 'default/ing1': not allowed by the annotations policy`,
+		},
+		// 8
+		{
+			policy: map[string]string{
+				"default": `
+deny: ["auth-["]
+`,
+			},
+			source:    &Source{Namespace: "default", Name: "ing1", Type: convtypes.ResourceIngress},
+			keys:      []string{"timeout-server"},
+			expKeys:   []string{},
+			expDenied: "Ingress 'default/ing1': [timeout-server]",
+			expLogging: `
+WARN annotations policy rule 'default' is invalid and denies all configuration keys: invalid key pattern 'auth-[': syntax error in pattern
+WARN ignoring configuration key(s) [timeout-server] on Ingress 'default/ing1': not allowed by the annotations policy`,
+		},
+		// 9
+		{
+			policy: map[string]string{
+				"untrusted": `
+namespaceSelector: tenant=untrusted
+deny: ["config-*"]
+`,
+			},
+			source:    &Source{Namespace: "team-d", Name: "ing1", Type: convtypes.ResourceIngress},
+			keys:      []string{"timeout-server"},
+			expKeys:   []string{},
+			expDenied: "Ingress 'team-d/ing1': [timeout-server]",
+			expLogging: `
+WARN denying all configuration keys of namespace 'team-d': error reading namespace labels: namespace not found: team-d
+WARN ignoring configuration key(s) [timeout-server] on Ingress 'team-d/ing1': not allowed by the annotations policy`,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		var denied []string
+		policy := NewKeyPolicy(c.logger, test.policy,
+			func(namespace string) (map[string]string, error) {
+				if labels, found := nsLabels[namespace]; found {
+					return labels, nil
+				}
+				return nil, fmt.Errorf("namespace not found: %s", namespace)
+			},
+			func(source *Source, keys []string) {
+				denied = append(denied, fmt.Sprintf("%s: %v", source, keys))
+			},
+			nil,
+		)
+		keys := make(map[string]string, len(test.keys))
+		for _, key := range test.keys {
+			keys[key] = "value"
+		}
+		// filter twice, denied keys should be reported only once
+		_ = policy.Filter(test.source, keys)
+		filtered := policy.Filter(test.source, keys)
+		expKeys := make(map[string]string, len(test.expKeys))
+		for _, key := range test.expKeys {
+			expKeys[key] = "value"
+		}
+		c.compareObjects("keys", i, filtered, expKeys)
+		c.compareObjects("denied", i, strings.Join(denied, "\n"), test.expDenied)
+		c.logger.CompareLogging(test.expLogging)
+		c.teardown()
+	}
+}
+
+func TestKeyPolicyReports(t *testing.T) {
+	policy := map[string]string{
+		"default": `
+deny: ["config-*", "auth-*"]
+`,
+	}
+	source := &Source{Namespace: "default", Name: "ing1", Type: convtypes.ResourceIngress}
+	testCases := []struct {
+		policy     map[string]string
+		keys       []string
+		removed    bool
+		expDenied  string
+		expLogging string
+	}{
+		// 0
+		{
+			policy:     policy,
+			keys:       []string{"config-backend", "timeout-server"},
+			expDenied:  "Ingress 'default/ing1': [config-backend]",
+			expLogging: "WARN ignoring configuration key(s) [config-backend] on Ingress 'default/ing1': not allowed by the annotations policy",
+		},
+		// 1 - same denied keys on a new sync
+		{
+			policy: policy,
+			keys:   []string{"config-backend", "timeout-server"},
+		},
+		// 2 - denied keys changed
+		{
+			policy:     policy,
+			keys:       []string{"auth-url", "config-backend"},
+			expDenied:  "Ingress 'default/ing1': [auth-url config-backend]",
+			expLogging: "WARN ignoring configuration key(s) [auth-url config-backend] on Ingress 'default/ing1': not allowed by the annotations policy",
+		},
+		// 3 - denied keys removed
+		{
+			policy: policy,
+			keys:   []string{"timeout-server"},
+		},
+		// 4 - denied keys added again
+		{
+			policy:     policy,
+			keys:       []string{"auth-url", "config-backend"},
+			expDenied:  "Ingress 'default/ing1': [auth-url config-backend]",
+			expLogging: "WARN ignoring configuration key(s) [auth-url config-backend] on Ingress 'default/ing1': not allowed by the annotations policy",
+		},
+		// 5 - source removed
+		{
+			policy:  policy,
+			removed: true,
+		},
+		// 6
+		{
+			policy:     policy,
+			keys:       []string{"auth-url", "config-backend"},
+			expDenied:  "Ingress 'default/ing1': [auth-url config-backend]",
+			expLogging: "WARN ignoring configuration key(s) [auth-url config-backend] on Ingress 'default/ing1': not allowed by the annotations policy",
+		},
+		// 7 - policy removed
+		{
+			keys: []string{"auth-url", "config-backend"},
+		},
+		// 8
+		{
+			policy:     policy,
+			keys:       []string{"auth-url", "config-backend"},
+			expDenied:  "Ingress 'default/ing1': [auth-url config-backend]",
+			expLogging: "WARN ignoring configuration key(s) [auth-url config-backend] on Ingress 'default/ing1': not allowed by the annotations policy",
+		},
+	}
+	c := setup(t)
+	defer c.teardown()
+	reported := KeyPolicyReports{}
+	for i, test := range testCases {
+		var denied []string
+		// a new policy on every sync, sharing the reported keys
+		policy := NewKeyPolicy(c.logger, test.policy,
+			func(string) (map[string]string, error) { return nil, nil },
+			func(source *Source, keys []string) {
+				denied = append(denied, fmt.Sprintf("%s: %v", source, keys))
+			},
+			reported,
+		)
+		if !test.removed {
+			keys := make(map[string]string, len(test.keys))
+			for _, key := range test.keys {
+				keys[key] = "value"
+			}
+			_ = policy.Filter(source, keys)
+		}
+		policy.Prune(func(s *Source) bool {
+			return !test.removed && *s == *source
+		})
+		c.compareObjects("denied", i, strings.Join(denied, "\n"), test.expDenied)
+		c.logger.CompareLogging(test.expLogging)
+	}
+}
@@ -170,6 +170,9 @@ func (c *updater) UpdateGlobalConfig(haproxyConfig haproxy.Config, mapper *Mappe
 	d.global.AdminSocket = c.options.AdminSocket
 	d.global.TCPBindIP = d.mapper.Get(ingtypes.GlobalBindIPAddrTCP).String()
 	d.global.LocalFSPrefix = c.options.LocalFSPrefix
+	if c.options.HAProxyVersion != nil {
+		d.global.HAProxy = c.options.HAProxyVersion()
+	}
 	d.global.MaxConn = mapper.Get(ingtypes.GlobalMaxConnections).Int()
 	d.global.DefaultBackendRedir = mapper.Get(ingtypes.GlobalDefaultBackendRedirect).String()
 	d.global.DefaultBackendRedirCode = mapper.Get(ingtypes.GlobalDefaultBackendRedirectCode).Int()
@@ -184,7 +187,6 @@ func (c *updater) UpdateGlobalConfig(haproxyConfig haproxy.Config, mapper *Mappe
 	d.global.Master.IsMasterWorker = c.options.MasterSocket != ""
 	d.global.Master.WorkerMaxReloads = mapper.Get(ingtypes.GlobalWorkerMaxReloads).Int()
 	d.global.StrictHost = mapper.Get(ingtypes.GlobalStrictHost).Bool()
-	d.global.UseHTX = mapper.Get(ingtypes.GlobalUseHTX).Bool()
 	c.buildGlobalAcme(d)
 	c.buildGlobalAuthProxy(d)
 	c.buildGlobalCloseSessions(d)
@@ -194,6 +196,8 @@ func (c *updater) UpdateGlobalConfig(haproxyConfig haproxy.Config, mapper *Mappe
 	c.buildGlobalDynamic(d)
 	c.buildGlobalFastCGI(d)
 	c.buildGlobalForwardFor(d)
+	c.buildGlobalHTX(d)
+	c.buildGlobalMirror(d)
 	c.buildGlobalModSecurity(d)
 	c.buildGlobalPathTypeOrder(d)
 	c.buildGlobalProc(d)
@@ -286,9 +290,12 @@ func (c *updater) UpdateBackendConfig(backend *hatypes.Backend, mapper *Mapper)
 	c.buildBackendAffinity(data)
 	c.buildBackendAuthExternal(data)
 	c.buildBackendAuthHTTP(data)
+	c.buildBackendAuthJWT(data)
 	c.buildBackendBlueGreenBalance(data)
 	c.buildBackendBlueGreenSelector(data)
 	c.buildBackendBodySize(data)
+	c.buildBackendCache(data)
+	c.buildBackendCompression(data)
 	c.buildBackendCors(data)
 	c.buildBackendCustomConfig(data)
 	c.buildBackendCustomResponses(data)
@@ -299,10 +306,12 @@ func (c *updater) UpdateBackendConfig(backend *hatypes.Backend, mapper *Mapper)
 	c.buildBackendHealthCheck(data)
 	c.buildBackendHSTS(data)
 	c.buildBackendLimit(data)
+	c.buildBackendMirror(data)
 	c.buildBackendOAuth(data)
 	c.buildBackendPeers(data)
 	c.buildBackendProtocol(data)
 	c.buildBackendProxyProtocol(data)
+	c.buildBackendRetry(data)
 	c.buildBackendRewriteURL(data)
 	c.buildBackendServerNaming(data)
 	c.buildBackendSourceAddressIntf(data)
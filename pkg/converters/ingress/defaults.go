@@ -48,11 +48,19 @@ func createDefaults() map[string]string {
 		types.BackAuthHeadersFail:        "*",
 		types.BackAuthHeadersRequest:     "*",
 		types.BackAuthHeadersSucceed:     "*",
+		types.BackAuthJWTAlgorithms:      "RS256",
 		types.BackAuthMethod:             "GET",
 		types.BackBackendServerNaming:    "sequence",
 		types.BackBackendServerSlotsInc:  "1",
 		types.BackSlotsMinFree:           "6",
 		types.BackBalanceAlgorithm:       "random(2)",
+		types.BackCacheEnable:            "false",
+		types.BackCacheMaxAge:            "60s",
+		types.BackCacheSize:              "64m",
+		types.BackCompressionAlgo:        "gzip",
+		types.BackCompressionDirection:   "response",
+		types.BackCompressionEnable:      "false",
+		types.BackCompressionTypes:       "text/html text/plain text/css text/javascript application/javascript application/json application/xml image/svg+xml",
 		types.BackCorsAllowHeaders:       "DNT,X-CustomHeader,Keep-Alive,User-Agent,X-Requested-With,If-Modified-Since,Cache-Control,Content-Type,Authorization",
 		types.BackCorsAllowMethods:       "GET, PUT, POST, DELETE, PATCH, OPTIONS",
 		types.BackCorsAllowOrigin:        "*",
@@ -64,6 +72,7 @@ func createDefaults() map[string]string {
 		types.BackHSTSMaxAge:             "15768000",
 		types.BackHSTSPreload:            "false",
 		types.BackInitialWeight:          "1",
+		types.BackMirrorPercentage:       "100",
 		types.BackOAuthHeaders:           "X-Auth-Request-Email",
 		types.BackSessionCookieDynamic:   "true",
 		types.BackSessionCookiePreserve:  "false",
@@ -97,6 +106,10 @@ func createDefaults() map[string]string {
 		types.GlobalHTTPSPort:                    "443",
 		types.GlobalMasterExitOnFailure:          "true",
 		types.GlobalMaxConnections:               "2000",
+		types.GlobalMirrorAgentTimeoutConnect:    "5s",
+		types.GlobalMirrorAgentTimeoutProcessing: "1s",
+		types.GlobalMirrorAgentTimeoutServer:     "5s",
+		types.GlobalMirrorPort:                   "10280",
 		types.GlobalModsecurityArgs:              "unique-id method path query req.ver req.hdrs_bin req.body_size req.body", // Ref: https://github.com/haproxy/spoa-modsecurity/blob/3c895f3e7dd291dba19d57ba054b277e6fb80ca4/README#L70
 		types.GlobalModsecurityTimeoutConnect:    "5s",
 		types.GlobalModsecurityTimeoutHello:      "100ms",
@@ -30,6 +30,7 @@ import (
 	api "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/annotations"
 	ingtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/ingress/types"
@@ -83,6 +84,11 @@ func NewIngressConverter(options *convtypes.ConverterOptions, haproxy haproxy.Co
 		backendAnnotations: map[*hatypes.Backend]*annotations.Mapper{},
 		ingressClasses:     map[string]*ingressClassConfig{},
 	}
+	annPolicy := changed.AnnPolicyConfigMapDataNew
+	if annPolicy == nil {
+		annPolicy = changed.AnnPolicyConfigMapDataCur
+	}
+	c.keyPolicy = annotations.NewKeyPolicy(options.Logger, annPolicy, c.readNamespaceLabels, c.recordKeysDenied, options.AnnPolicyReports)
 	c.readDefaultCertificate()
 	return c
 }
@@ -99,6 +105,7 @@ type converter struct {
 	mapBuilder         *annotations.MapBuilder
 	updater            annotations.Updater
 	globalConfig       *annotations.Mapper
+	keyPolicy          *annotations.KeyPolicy
 	tcpsvcAnnotations  map[*hatypes.TCPServicePort]*annotations.Mapper
 	frontAnnotations   map[*hatypes.Frontend]*annotations.Mapper
 	frontLocalPorts    map[*hatypes.Frontend]bool
@@ -133,7 +140,7 @@ type ingressClassConfig struct {
 }
 
 func (c *converter) NeedFullSync() bool {
-	needFullSync := c.defaultCrtNeedFullSync() || c.globalConfigNeedFullSync()
+	needFullSync := c.defaultCrtNeedFullSync() || c.globalConfigNeedFullSync() || c.annPolicyNeedFullSync()
 	if needFullSync && c.defaultCrt.SHA1Hash == c.options.FakeCrtFile.SHA1Hash {
 		c.logger.Info("using auto generated fake certificate")
 	}
@@ -147,6 +154,7 @@ func (c *converter) Sync(full bool) {
 	} else {
 		c.syncPartial()
 	}
+	c.keyPolicy.Prune(c.sourceExists)
 }
 
 func (c *converter) defaultCrtNeedFullSync() bool {
@@ -173,6 +181,52 @@ func (c *converter) globalConfigNeedFullSync() bool {
 	return new != nil && !reflect.DeepEqual(cur, new)
 }
 
+func (c *converter) annPolicyNeedFullSync() bool {
+	// the annotations policy may change the keys allowed on any ingress or service
+	cur, new := c.changed.AnnPolicyConfigMapDataCur, c.changed.AnnPolicyConfigMapDataNew
+	return new != nil && !reflect.DeepEqual(cur, new)
+}
+
+func (c *converter) readNamespaceLabels(namespace string) (map[string]string, error) {
+	ns, err := c.cache.GetNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return ns.Labels, nil
+}
+
+func (c *converter) sourceExists(source *annotations.Source) bool {
+	var err error
+	switch source.Type {
+	case convtypes.ResourceIngress:
+		_, err = c.cache.GetIngress(source.FullName())
+	case convtypes.ResourceService:
+		_, err = c.cache.GetService("", source.FullName())
+	default:
+		return false
+	}
+	return err == nil
+}
+
+func (c *converter) recordKeysDenied(source *annotations.Source, keys []string) {
+	var obj client.Object
+	var err error
+	switch source.Type {
+	case convtypes.ResourceIngress:
+		obj, err = c.cache.GetIngress(source.FullName())
+	case convtypes.ResourceService:
+		obj, err = c.cache.GetService("", source.FullName())
+	default:
+		return
+	}
+	if err != nil {
+		c.logger.Warn("cannot record event on %s: %v", source, err)
+		return
+	}
+	c.cache.RecordEvent(obj, api.EventTypeWarning, "AnnotationDenied",
+		fmt.Sprintf("configuration key(s) not allowed by the annotations policy were ignored: %s", strings.Join(keys, ", ")))
+}
+
 func (c *converter) readDefaultCertificate() {
 	crt := c.options.FakeCrtFile
 	if c.options.DefaultCrtSecret != "" {
@@ -510,6 +564,15 @@ func (c *converter) syncIngressHTTP(source *annotations.Source, ing *networking.
 			if headerMatch := annBack[ingtypes.BackHTTPHeaderMatchRegex]; headerMatch != "" {
 				c.addHeaderMatch(source, pathLink, headerMatch, true)
 			}
+			if methodMatch := annBack[ingtypes.BackHTTPMethodMatch]; methodMatch != "" {
+				c.addMethodMatch(source, pathLink, methodMatch)
+			}
+			if queryMatch := annBack[ingtypes.BackHTTPQueryMatch]; queryMatch != "" {
+				c.addQueryMatch(source, pathLink, queryMatch, false)
+			}
+			if queryMatch := annBack[ingtypes.BackHTTPQueryMatchRegex]; queryMatch != "" {
+				c.addQueryMatch(source, pathLink, queryMatch, true)
+			}
 			if sslpassthrough && uri == "/" {
 				if host.inner.FindPath(uri) != nil {
 					c.logger.Warn("skipping redeclared ssl-passthrough root path on %v", source)
@@ -582,6 +645,16 @@ func (c *converter) syncIngressHTTP(source *annotations.Source, ing *networking.
 					}
 				}
 			}
+			// pre-building the mirror backend, same TODOs of the auth-url above apply
+			if mirror := annBack[ingtypes.BackMirrorService]; mirror != "" {
+				mirrorSvcName, mirrorPort, _ := strings.Cut(mirror, ":")
+				if mirrorSvcName != "" && mirrorPort != "" {
+					_, err := c.addBackend(source, pathLink, ing.Namespace+"/"+mirrorSvcName, mirrorPort, map[string]string{}, nil)
+					if err != nil {
+						c.logger.Warn("skipping mirror-service on %v: %v", source, err)
+					}
+				}
+			}
 		}
 	}
 	for _, tls := range ing.Spec.TLS {
@@ -934,6 +1007,49 @@ func (c *converter) addHeaderMatch(source *annotations.Source, pathLink *hatypes
 	}
 }
 
+var (
+	methodRegex    = regexp.MustCompile(`^[A-Z]+$`)
+	queryNameRegex = regexp.MustCompile(`^[A-Za-z0-9._~-]+$`)
+)
+
+func (c *converter) addMethodMatch(source *annotations.Source, pathLink *hatypes.PathLink, methodMatch string) {
+	method := strings.ToUpper(strings.TrimSpace(methodMatch))
+	if !methodRegex.MatchString(method) {
+		c.logger.Warn("ignoring invalid HTTP method on %s: %s", source, methodMatch)
+		return
+	}
+	pathLink.WithMethodMatch(method)
+}
+
+func (c *converter) addQueryMatch(source *annotations.Source, pathLink *hatypes.PathLink, queryMatch string, regex bool) {
+	var query hatypes.HTTPQueryMatch
+	for _, param := range utils.LineToSlice(queryMatch) {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		name, value, found := strings.Cut(param, "=")
+		if !found || !queryNameRegex.MatchString(name) {
+			c.logger.Warn("ignoring query param on %s: missing or invalid name or value: %s", source, param)
+			continue
+		}
+		if regex {
+			if _, err := regexp.Compile(value); err != nil {
+				c.logger.Warn("ignoring invalid regex on %s: %v", source, err)
+				continue
+			}
+		}
+		query = append(query, hatypes.HTTPMatch{
+			Regex: regex,
+			Name:  name,
+			Value: value,
+		})
+	}
+	if len(query) > 0 {
+		pathLink.AddQueryMatch(query)
+	}
+}
+
 func (c *converter) addBackend(source *annotations.Source, pathLink *hatypes.PathLink, fullSvcName, svcPort string, ann map[string]string, ingressClass *networking.IngressClass) (*hatypes.Backend, error) {
 	// TODO build a stronger tracking
 	hostname := pathLink.Hostname()
@@ -986,12 +1102,13 @@ func (c *converter) addBackend(source *annotations.Source, pathLink *hatypes.Pat
 		c.backendAnnotations[backend] = mapper
 	}
 	// Starting with service annotations, giving precedence
-	_, _, _, svcann := c.readAnnotations(source, svc.Annotations)
-	mapper.AddAnnotations(&annotations.Source{
+	svcSource := &annotations.Source{
 		Namespace: namespace,
 		Name:      svcName,
 		Type:      convtypes.ResourceService,
-	}, pathLink, svcann)
+	}
+	_, _, _, svcann := c.readAnnotations(svcSource, svc.Annotations)
+	mapper.AddAnnotations(svcSource, pathLink, svcann)
 	// Merging Ingress annotations
 	conflict := mapper.AddAnnotations(source, pathLink, ann)
 	if len(conflict) > 0 {
@@ -1167,7 +1284,11 @@ func (c *converter) addEndpoints(svc *api.Service, svcPort *api.ServicePort, bac
 }
 
 func (c *converter) readAnnotations(source *annotations.Source, ann map[string]string) (annTCP, annFront, annHost, annBack map[string]string) {
-	keys := c.readConfigKeys(source, ann)
+	// the annotations policy is applied here instead of in the mapper: this is the
+	// only place that sees all the keys of a resource at once, so the denied ones
+	// are reported once per resource. The mapper also receives keys that do not
+	// come from annotations, like IngressClass parameters, which should not be filtered.
+	keys := c.keyPolicy.Filter(source, c.readConfigKeys(source, ann))
 	annTCP = make(map[string]string, len(keys))
 	annFront = make(map[string]string, len(keys))
 	annHost = make(map[string]string, len(keys))
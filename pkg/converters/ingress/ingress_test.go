@@ -2209,6 +2209,61 @@ func TestSyncAnnBacksSvcIng(t *testing.T) {
   maxconnserver: 10` + defaultBackendConfig)
 }
 
+func TestSyncAnnPolicy(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.cache.NsList["default"] = &api.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "default",
+		Labels: map[string]string{"tenant": "untrusted"},
+	}}
+	c.cache.Changed.AnnPolicyConfigMapDataNew = map[string]string{
+		"untrusted": `
+namespaceSelector: tenant=untrusted
+deny: [auth-*, config-*]
+`,
+	}
+	c.createSvc1AutoAnn(map[string]string{
+		"ingress.kubernetes.io/config-backend": "http-request deny",
+	})
+	ing := c.createIng1Ann("default/echo", "echo.example.com", "/", "echo:8080", map[string]string{
+		"ingress.kubernetes.io/auth-url":       "http://auth.local",
+		"ingress.kubernetes.io/maxconn-server": "10",
+	})
+	c.cache.SecretTLSPath["system/default"] = "/tls/tls-default.pem"
+	conv := c.createConverter()
+	c.SyncConverter(conv, ing)
+
+	c.compareConfigBack(`
+- id: default_echo_8080
+  endpoints:
+  - ip: 172.17.0.11
+    port: 8080
+  maxconnserver: 10` + defaultBackendConfig)
+
+	c.compareText(strings.Join(c.cache.EventList, "\n"), `
+Warning default/echo AnnotationDenied: configuration key(s) not allowed by the annotations policy were ignored: auth-url
+Warning default/echo AnnotationDenied: configuration key(s) not allowed by the annotations policy were ignored: config-backend`)
+
+	c.logger.CompareLogging(`
+WARN ignoring configuration key(s) [auth-url] on Ingress 'default/echo': not allowed by the annotations policy
+WARN ignoring configuration key(s) [config-backend] on Service 'default/echo': not allowed by the annotations policy`)
+
+	// reports of removed resources are pruned, so the ingress is reported again
+	// when recreated, but not the service, which was not removed
+	c.cache.EventList = nil
+	c.cache.IngList = nil
+	c.hconfig.Clear()
+	conv.Sync(true)
+	c.cache.IngList = []*networking.Ingress{ing}
+	c.hconfig.Clear()
+	conv.Sync(true)
+	c.compareText(strings.Join(c.cache.EventList, "\n"), `
+Warning default/echo AnnotationDenied: configuration key(s) not allowed by the annotations policy were ignored: auth-url`)
+	c.logger.CompareLogging(`
+WARN ignoring configuration key(s) [auth-url] on Ingress 'default/echo': not allowed by the annotations policy`)
+}
+
 func TestSyncAnnBackSvcPath(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -2348,6 +2403,47 @@ func TestSyncAnnBackHeaderMatch(t *testing.T) {
 	c.logger.CompareLogging(``)
 }
 
+func TestSyncAnnBackMethodQueryMatch(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.createSvc1("default/echo", "http:8080", "172.17.1.101")
+	c.Sync(
+		c.createIng1Ann("default/echo1", "echo1.example.com", "/", "echo:8080",
+			map[string]string{
+				"ingress.kubernetes.io/http-method-match":      "get",
+				"ingress.kubernetes.io/http-query-match":       "version=2\ninvalid",
+				"ingress.kubernetes.io/http-query-match-regex": "lang=^(en|pt)$",
+			}),
+		c.createIng1Ann("default/echo2", "echo2.example.com", "/", "echo:8080",
+			map[string]string{
+				"ingress.kubernetes.io/http-method-match": "GET POST",
+			}),
+	)
+
+	c.compareConfigFront(`
+- hostname: echo1.example.com
+  paths:
+  - path: /
+    method: GET
+    query:
+    - name: version
+      value: "2"
+      regex: false
+    - name: lang
+      value: ^(en|pt)$
+      regex: true
+    backend: default_echo_8080
+- hostname: echo2.example.com
+  paths:
+  - path: /
+    backend: default_echo_8080
+`)
+	c.logger.CompareLogging(`
+WARN ignoring query param on Ingress 'default/echo1': missing or invalid name or value: invalid
+WARN ignoring invalid HTTP method on Ingress 'default/echo2': GET POST`)
+}
+
 func TestSyncAnnAuthURL(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -2382,6 +2478,40 @@ func TestSyncAnnAuthURL(t *testing.T) {
 	c.logger.CompareLogging(`WARN skipping auth-url on Ingress 'default/echo2': service not found: 'default/authsvc2'`)
 }
 
+func TestSyncAnnMirror(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.createSvc1("default/echo", "http:8080", "172.17.1.101")
+	c.createSvc1("default/shadow1", "http:8080", "172.17.1.110")
+	c.Sync(
+		c.createIng1Ann("default/echo1", "echo1.example.com", "/", "echo:8080",
+			map[string]string{
+				"ingress.kubernetes.io/mirror-service": "shadow1:8080",
+			}),
+		c.createIng1Ann("default/echo2", "echo2.example.com", "/", "echo:8080",
+			map[string]string{
+				"ingress.kubernetes.io/mirror-service": "shadow2:8080",
+			}),
+	)
+
+	c.compareConfigBack(`
+- id: default_echo_8080
+  endpoints:
+  - ip: 172.17.1.101
+    port: 8080
+- id: default_shadow1_8080
+  endpoints:
+  - ip: 172.17.1.110
+    port: 8080
+- id: system_default_8080
+  endpoints:
+  - ip: 172.17.0.99
+    port: 8080
+`)
+	c.logger.CompareLogging(`WARN skipping mirror-service on Ingress 'default/echo2': service not found: 'default/shadow2'`)
+}
+
 func TestSyncAnnPassthrough(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -149,6 +149,11 @@ const (
 	BackAuthHeadersFail        = "auth-headers-fail"
 	BackAuthHeadersRequest     = "auth-headers-request"
 	BackAuthHeadersSucceed     = "auth-headers-succeed"
+	BackAuthJWTAlgorithms      = "auth-jwt-algorithms"
+	BackAuthJWTAudience        = "auth-jwt-audience"
+	BackAuthJWTHeaders         = "auth-jwt-headers"
+	BackAuthJWTIssuer          = "auth-jwt-issuer"
+	BackAuthJWTKey             = "auth-jwt-key"
 	BackAuthMethod             = "auth-method"
 	BackAuthRealm              = "auth-realm"
 	BackAuthSecret             = "auth-secret"
@@ -165,6 +170,16 @@ const (
 	BackBlueGreenDeploy        = "blue-green-deploy"
 	BackBlueGreenHeader        = "blue-green-header"
 	BackBlueGreenMode          = "blue-green-mode"
+	BackCacheEnable            = "cache-enable"
+	BackCacheMaxAge            = "cache-max-age"
+	BackCacheMaxObjectSize     = "cache-max-object-size"
+	BackCacheSize              = "cache-size"
+	BackCacheVary              = "cache-vary"
+	BackCompressionAlgo        = "compression-algo"
+	BackCompressionDirection   = "compression-direction"
+	BackCompressionEnable      = "compression-enable"
+	BackCompressionMinSize     = "compression-min-size"
+	BackCompressionTypes       = "compression-types"
 	BackConfigBackend          = "config-backend"
 	BackConfigBackendEarly     = "config-backend-early"
 	BackConfigBackendLate      = "config-backend-late"
@@ -192,6 +207,9 @@ const (
 	BackHSTSPreload            = "hsts-preload"
 	BackHTTPHeaderMatch        = "http-header-match"
 	BackHTTPHeaderMatchRegex   = "http-header-match-regex"
+	BackHTTPMethodMatch        = "http-method-match"
+	BackHTTPQueryMatch         = "http-query-match"
+	BackHTTPQueryMatchRegex    = "http-query-match-regex"
 	BackHTTPResponse200        = "http-response-200"
 	BackHTTPResponse400        = "http-response-400"
 	BackHTTPResponse401        = "http-response-401"
@@ -214,6 +232,8 @@ const (
 	BackLimitWhitelist         = "limit-whitelist"
 	BackMaxconnServer          = "maxconn-server"
 	BackMaxQueueServer         = "maxqueue-server"
+	BackMirrorPercentage       = "mirror-percentage"
+	BackMirrorService          = "mirror-service"
 	BackOAuth                  = "oauth"
 	BackOAuthHeaders           = "oauth-headers"
 	BackOAuthURIPrefix         = "oauth-uri-prefix"
@@ -222,6 +242,9 @@ const (
 	BackProxyBodySize          = "proxy-body-size"
 	BackProxyProtocol          = "proxy-protocol"
 	BackRedirectTo             = "redirect-to"
+	BackRedispatch             = "redispatch"
+	BackRetries                = "retries"
+	BackRetryOn                = "retry-on"
 	BackRewriteTarget          = "rewrite-target"
 	BackSlotsMinFree           = "slots-min-free"
 	BackSecureBackends         = "secure-backends"
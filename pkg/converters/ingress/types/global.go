@@ -69,6 +69,11 @@ const (
 	GlobalLoadServerState              = "load-server-state"
 	GlobalMasterExitOnFailure          = "master-exit-on-failure"
 	GlobalMaxConnections               = "max-connections"
+	GlobalMirrorAgentEndpoints         = "mirror-agent-endpoints"
+	GlobalMirrorAgentTimeoutConnect    = "mirror-agent-timeout-connect"
+	GlobalMirrorAgentTimeoutProcessing = "mirror-agent-timeout-processing"
+	GlobalMirrorAgentTimeoutServer     = "mirror-agent-timeout-server"
+	GlobalMirrorPort                   = "mirror-port"
 	GlobalModsecurityArgs              = "modsecurity-args"
 	GlobalModsecurityEndpoints         = "modsecurity-endpoints"
 	GlobalModsecurityTimeoutConnect    = "modsecurity-timeout-connect"
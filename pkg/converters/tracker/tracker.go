@@ -19,6 +19,7 @@ package tracker
 import (
 	"slices"
 	"sort"
+	"sync"
 
 	convtypes "github.com/jcmoraisjr/haproxy-ingress/pkg/converters/types"
 )
@@ -34,6 +35,9 @@ type link struct{}
 type trackingMap map[convtypes.ResourceType]map[string]map[convtypes.TrackingRef]link
 
 type tracker struct {
+	// mu protects tracking, which is also read by the watchers,
+	// outside of the converter's synchronization
+	mu       sync.RWMutex
 	tracking trackingMap
 }
 
@@ -62,6 +66,8 @@ func (t *tracker) TrackRefs(left, right convtypes.TrackingRef) {
 	if left == emptyRef || right == emptyRef {
 		return
 	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.track(&left, &right)
 	t.track(&right, &left)
 }
@@ -83,6 +89,8 @@ func (t *tracker) track(dest, source *convtypes.TrackingRef) {
 // QueryLinks recursively lists all resource IDs that a list of input
 // resources are linked to, and remove the input IDs from the maps
 func (t *tracker) QueryLinks(input convtypes.TrackingLinks, removeMatches bool) convtypes.TrackingLinks {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	outputrefs := map[convtypes.ResourceType]map[string]link{}
 	var updateOutput func(convtypes.ResourceType, []string)
 	updateOutput = func(ctx convtypes.ResourceType, namelist []string) {
@@ -121,9 +129,37 @@ func (t *tracker) QueryLinks(input convtypes.TrackingLinks, removeMatches bool)
 }
 
 func (t *tracker) ClearLinks() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.tracking = trackingMap{}
 }
 
+// IsTracked returns true if the resource is linked to any other resource.
+func (t *tracker) IsTracked(context convtypes.ResourceType, name string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.tracking[context][name]) > 0
+}
+
+// Links lists the resources directly linked to a resource. Differently from
+// QueryLinks, the resources linked to the listed ones are not added.
+func (t *tracker) Links(context convtypes.ResourceType, name string) []convtypes.TrackingRef {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	refs := t.tracking[context][name]
+	links := make([]convtypes.TrackingRef, 0, len(refs))
+	for ref := range refs {
+		links = append(links, ref)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Context != links[j].Context {
+			return links[i].Context < links[j].Context
+		}
+		return links[i].UniqueName < links[j].UniqueName
+	})
+	return links
+}
+
 func (t *tracker) removeRef(ctx convtypes.ResourceType, name string) {
 	if refs, found := t.tracking[ctx]; found {
 		n := refs[name]
@@ -346,6 +346,64 @@ ingress
 	}
 }
 
+func TestIsTracked(t *testing.T) {
+	gw := convtypes.TrackingRef{Context: convtypes.ResourceGateway, UniqueName: "gw"}
+	c := setup(t)
+	c.tracker.TrackRefName([]convtypes.TrackingRef{gw}, convtypes.ResourceConfigMap, "default/ca")
+	testCases := []struct {
+		context  convtypes.ResourceType
+		name     string
+		expected bool
+	}{
+		// 0
+		{context: convtypes.ResourceConfigMap, name: "default/ca", expected: true},
+		// 1
+		{context: convtypes.ResourceConfigMap, name: "default/kube-root-ca.crt", expected: false},
+		// 2
+		{context: convtypes.ResourceSecret, name: "default/ca", expected: false},
+		// 3
+		{context: convtypes.ResourceGateway, name: "gw", expected: true},
+	}
+	for i, test := range testCases {
+		if actual := c.tracker.IsTracked(test.context, test.name); actual != test.expected {
+			t.Errorf("is tracked on %d: expected %t but was %t", i, test.expected, actual)
+		}
+	}
+	c.tracker.ClearLinks()
+	if c.tracker.IsTracked(convtypes.ResourceConfigMap, "default/ca") {
+		t.Errorf("is tracked after clear links: expected false but was true")
+	}
+	c.teardown()
+}
+
+func TestLinks(t *testing.T) {
+	ing1 := convtypes.TrackingRef{Context: convtypes.ResourceIngress, UniqueName: "default/ing1"}
+	ing2 := convtypes.TrackingRef{Context: convtypes.ResourceIngress, UniqueName: "default/ing2"}
+	svc1 := convtypes.TrackingRef{Context: convtypes.ResourceService, UniqueName: "default/svc1"}
+	c := setup(t)
+	c.tracker.TrackRefName([]convtypes.TrackingRef{ing2, ing1}, convtypes.ResourceHAHostname, "domain.local")
+	c.tracker.TrackRefName([]convtypes.TrackingRef{ing1}, convtypes.ResourceHABackend, "default_svc1_8080")
+	c.tracker.TrackRefName([]convtypes.TrackingRef{svc1}, convtypes.ResourceHABackend, "default_svc1_8080")
+	testCases := []struct {
+		context  convtypes.ResourceType
+		name     string
+		expected string
+	}{
+		// 0
+		{context: convtypes.ResourceHAHostname, name: "domain.local", expected: "[{Ingress default/ing1} {Ingress default/ing2}]"},
+		// 1
+		{context: convtypes.ResourceHABackend, name: "default_svc1_8080", expected: "[{Ingress default/ing1} {Service default/svc1}]"},
+		// 2
+		{context: convtypes.ResourceHAHostname, name: "other.local", expected: "[]"},
+	}
+	for i, test := range testCases {
+		if actual := fmt.Sprintf("%v", c.tracker.Links(test.context, test.name)); actual != test.expected {
+			t.Errorf("links on %d: expected %s but was %s", i, test.expected, actual)
+		}
+	}
+	c.teardown()
+}
+
 type testConfig struct {
 	t       *testing.T
 	tracker *tracker
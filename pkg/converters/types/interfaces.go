@@ -41,11 +41,17 @@ type Cache interface {
 	GetGatewayA2(namespace, name string) (*gatewayv1alpha2.Gateway, error)
 	GetGatewayB1(namespace, name string) (*gatewayv1beta1.Gateway, error)
 	GetGateway(namespace, name string) (*gatewayv1.Gateway, error)
+	GetGatewayA2List() ([]*gatewayv1alpha2.Gateway, error)
+	GetGatewayB1List() ([]*gatewayv1beta1.Gateway, error)
+	GetGatewayList() ([]*gatewayv1.Gateway, error)
 	GetHTTPRouteA2List() ([]*gatewayv1alpha2.HTTPRoute, error)
 	GetHTTPRouteB1List() ([]*gatewayv1beta1.HTTPRoute, error)
 	GetHTTPRouteList() ([]*gatewayv1.HTTPRoute, error)
+	GetGRPCRouteList() ([]*gatewayv1alpha2.GRPCRoute, error)
 	GetTCPRouteList() ([]*gatewayv1alpha2.TCPRoute, error)
 	GetTLSRouteList() ([]*gatewayv1alpha2.TLSRoute, error)
+	GetReferenceGrantList(namespace string) ([]*gatewayv1beta1.ReferenceGrant, error)
+	GetBackendTLSPolicyList() ([]*gatewayv1alpha2.BackendTLSPolicy, error)
 	GetService(defaultNamespace, serviceName string) (*api.Service, error)
 	GetConfigMap(configMapName string) (*api.ConfigMap, error)
 	GetNamespace(name string) (*api.Namespace, error)
@@ -55,10 +61,13 @@ type Cache interface {
 	GetControllerPod() types.NamespacedName
 	GetTLSSecretPath(defaultNamespace, secretName string, track []TrackingRef) (CrtFile, error)
 	GetCASecretPath(defaultNamespace, secretName string, track []TrackingRef) (ca, crl File, err error)
+	GetCAConfigMapPath(namespace, configMapName string, track []TrackingRef) (File, error)
 	GetDHSecretPath(defaultNamespace, secretName string) (File, error)
 	GetPasswdSecretContent(defaultNamespace, secretName string, track []TrackingRef) ([]byte, error)
+	GetJWTKeyPath(defaultNamespace, keyName string, track []TrackingRef) ([]JWTKeyFile, error)
 	SwapChangedObjects() *ChangedObjects
 	UpdateStatus(obj client.Object)
+	RecordEvent(obj client.Object, eventtype, reason, message string)
 	GetEndpointSlices(service *api.Service) ([]*discoveryv1.EndpointSlice, error)
 }
 
@@ -70,6 +79,9 @@ type ChangedObjects struct {
 	TCPConfigMapDataCur,
 	TCPConfigMapDataNew map[string]string
 	//
+	AnnPolicyConfigMapDataCur,
+	AnnPolicyConfigMapDataNew map[string]string
+	//
 	IngressesDel,
 	IngressesUpd,
 	IngressesAdd []*networking.Ingress
@@ -94,8 +106,12 @@ const (
 	ResourceGateway      ResourceType = "Gateway"
 	ResourceGatewayClass ResourceType = "GatewayClass"
 	ResourceHTTPRoute    ResourceType = "HTTPRoute"
+	ResourceGRPCRoute    ResourceType = "GRPCRoute"
 	ResourceTCPRoute     ResourceType = "TCPRoute"
 
+	ResourceReferenceGrant   ResourceType = "ReferenceGrant"
+	ResourceBackendTLSPolicy ResourceType = "BackendTLSPolicy"
+
 	ResourceConfigMap ResourceType = "ConfigMap"
 	ResourceService   ResourceType = "Service"
 	ResourceEndpoints ResourceType = "Endpoints"
@@ -126,6 +142,8 @@ type Tracker interface {
 	TrackRefs(left, right TrackingRef)
 	QueryLinks(input TrackingLinks, removeMatches bool) TrackingLinks
 	ClearLinks()
+	IsTracked(context ResourceType, name string) bool
+	Links(context ResourceType, name string) []TrackingRef
 }
 
 // AnnotationReader ...
@@ -145,3 +163,14 @@ type CrtFile struct {
 	SHA1Hash    string
 	Certificate *x509.Certificate
 }
+
+// JWTKeyFile is a PEM encoded public key used to verify JWT signatures
+type JWTKeyFile struct {
+	Filename string
+	SHA1Hash string
+	// KeyID and Algorithm are the optional `kid` and `alg` of a JWK
+	KeyID     string
+	Algorithm string
+	// KeyType is the public key algorithm: `RSA` or `EC`
+	KeyType string
+}
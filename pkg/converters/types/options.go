@@ -17,6 +17,7 @@ limitations under the License.
 package types
 
 import (
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/types"
 )
 
@@ -25,6 +26,7 @@ type ConverterOptions struct {
 	Logger           types.Logger
 	Cache            Cache
 	Tracker          Tracker
+	ControllerName   string
 	DynamicConfig    *DynamicConfig
 	LocalFSPrefix    string
 	IsExternal       bool
@@ -32,6 +34,7 @@ type ConverterOptions struct {
 	AdminSocket      string
 	AcmeSocket       string
 	DefaultConfig    func() map[string]string
+	HAProxyVersion   func() hatypes.HAProxyVersion
 	DefaultBackend   string
 	DefaultCrtSecret string
 	FakeCrtFile      CrtFile
@@ -43,8 +46,14 @@ type ConverterOptions struct {
 	HasGatewayA2     bool
 	HasGatewayB1     bool
 	HasGatewayV1     bool
+	HasGRPCRouteA2   bool
 	HasTCPRouteA2    bool
 	HasTLSRouteA2    bool
+	HasRefGrantB1    bool
+	HasBackendTLSA2  bool
+	// AnnPolicyReports has the configuration keys denied by the annotations
+	// policy and already reported, by source. It is shared between syncs.
+	AnnPolicyReports map[string]string
 }
 
 // DynamicConfig ...
@@ -42,15 +42,19 @@ type Config interface {
 	TCPServices() *hatypes.TCPServices
 	Backends() *hatypes.Backends
 	Userlists() *hatypes.Userlists
+	Quarantine() hatypes.SnippetQuarantine
+	PruneQuarantine()
 	Clear()
 	Shrink()
+	Rewrite()
 	Commit()
 }
 
 type config struct {
 	// external state, non haproxy data
-	options  options
-	acmeData *hatypes.AcmeData
+	options    options
+	acmeData   *hatypes.AcmeData
+	quarantine hatypes.SnippetQuarantine
 	// haproxy internal state
 	globalOld   *hatypes.Global
 	global      *hatypes.Global
@@ -59,6 +63,23 @@ type config struct {
 	tcpbackends *hatypes.TCPBackends
 	tcpservices *hatypes.TCPServices
 	userlists   *hatypes.Userlists
+	// rewrite is true if all the maps should be written, including unchanged ones
+	rewrite bool
+	// frontend maps of the current and the last committed state,
+	// used to dynamically update haproxy maps
+	frontendMapsOld map[*hatypes.Frontend]*frontendMaps
+	frontendMaps    map[*hatypes.Frontend]*frontendMaps
+}
+
+type frontendMaps struct {
+	maps    *hatypes.HostsMaps
+	crtList []*crtListLine
+}
+
+type crtListLine struct {
+	crtFile string
+	caFile  string
+	line    string
 }
 
 type options struct {
@@ -74,6 +95,7 @@ func createConfig(options options) *config {
 	return &config{
 		options:     options,
 		acmeData:    &hatypes.AcmeData{},
+		quarantine:  hatypes.SnippetQuarantine{},
 		global:      &hatypes.Global{},
 		frontends:   &hatypes.Frontends{},
 		backends:    hatypes.CreateBackends(options.shardCount),
@@ -170,14 +192,14 @@ func (c *config) syncPeers() {
 // config file. This func doesn't change model state, except the
 // link to the tcp services maps.
 func (c *config) WriteTCPServicesMaps() error {
-	if !c.tcpservices.Changed() {
+	if !c.tcpservices.Changed() && !c.rewrite {
 		return nil
 	}
 	mapBuilder := hatypes.CreateMaps(c.global.MatchOrder)
 	for _, tcpPort := range c.tcpservices.Items() {
 		sniMap := mapBuilder.AddMap(fmt.Sprintf("%s/_tcp_sni_%d.map", c.options.mapsDir, tcpPort.Port()))
 		for _, tcpHost := range tcpPort.BuildSortedItems() {
-			sniMap.AddHostnameMapping(tcpHost.Hostname(), tcpHost.Backend.String())
+			sniMap.AddTCPHostMapping(tcpHost, tcpHost.Backend.String())
 		}
 		tcpPort.SNIMap = sniMap
 	}
@@ -238,19 +260,52 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 		buildCommonMaps(commonMaps)
 	}
 	hasVarNamespace := f.HasVarNamespace()
+	f.RedirToRules = nil
+	redirToRules := map[hatypes.RedirToRule]*hatypes.RedirToRule{}
+	redirToTarget := func(path *hatypes.Path, defaultHost bool) string {
+		if path.RedirToCode == 0 && !path.RedirToFormat && !defaultHost {
+			return path.RedirTo
+		}
+		rule := hatypes.RedirToRule{
+			Location:    path.RedirTo,
+			Code:        path.RedirToCode,
+			DefaultHost: defaultHost,
+		}
+		if !path.RedirToFormat {
+			rule.Location = strings.ReplaceAll(rule.Location, "%", "%%")
+		}
+		if rule.Code == 0 {
+			rule.Code = f.RedirectToCode
+		}
+		if r, found := redirToRules[rule]; found {
+			return r.ID
+		}
+		r := rule
+		// the ID is the value of the map, it cannot be confused with the URL of a RedirTo
+		r.ID = fmt.Sprintf("_redirto%d", len(f.RedirToRules)+1)
+		redirToRules[rule] = &r
+		f.RedirToRules = append(f.RedirToRules, &r)
+		return r.ID
+	}
 	defaultHost := f.DefaultHost()
 	if defaultHost != nil && !defaultHost.SSLPassthrough {
 		for _, path := range defaultHost.Paths {
 			// using DefaultHost ID as hostname, see types.maps.go/buildMapKey()
-			commonMaps.DefaultHostMap.AddHostnamePathMapping(hatypes.DefaultHost, path, path.Backend.ID)
+			if path.Backend != nil {
+				commonMaps.DefaultHostMap.AddHostnamePathMapping(hatypes.DefaultHost, path, path.Backend.ID)
+			} else if path.RedirTo != "" {
+				commonMaps.DefaultHostMap.AddHostnamePathMapping(hatypes.DefaultHost, path, redirToTarget(path, true))
+			}
 		}
 	}
 	defaultCrtFile := c.frontends.DefaultCrtFile
 	var crtListItems []*hatypes.HostsMapEntry
+	var crtList []*crtListLine
 	if f.IsHTTPS {
 		// TODO crtList* to be removed after implement a template to the crt list
 		f.CrtListFile = mapsFilenamePrefix + "_bind_crt.list"
 		crtListItems = append(crtListItems, &hatypes.HostsMapEntry{Key: defaultCrtFile + " !*"})
+		crtList = append(crtList, &crtListLine{crtFile: defaultCrtFile, line: defaultCrtFile + " !*"})
 	}
 	for _, host := range f.BuildSortedHosts() {
 		for _, path := range host.Paths {
@@ -260,18 +315,19 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 				if f.IsHTTPS && host.SSLPassthrough {
 					// no ssl offload, cannot inspect incoming path, so tracking root only
 					if path.Path() == "/" {
-						httpsMaps.SSLPassthroughMap.AddHostnameMapping(host.Hostname, backendID)
+						httpsMaps.SSLPassthroughMap.AddHostMapping(host, backendID)
 					}
 				} else if f.IsHTTPS {
-					httpsMaps.HTTPSHostMap.AddHostnamePathMapping(host.Hostname, path, backendID)
+					httpsMaps.HTTPSHostMap.AddHostPathMapping(host, path, backendID)
 					httpsMaps.HTTPSHostMap.AddAliasPathMapping(host.Alias, path, backendID)
 				} else {
-					httpMaps.HTTPHostMap.AddHostnamePathMapping(host.Hostname, path, backendID)
+					httpMaps.HTTPHostMap.AddHostPathMapping(host, path, backendID)
 					httpMaps.HTTPHostMap.AddAliasPathMapping(host.Alias, path, backendID)
 				}
 			} else if path.RedirTo != "" {
-				commonMaps.RedirToMap.AddHostnamePathMapping(host.Hostname, path, path.RedirTo)
-				commonMaps.RedirToMap.AddAliasPathMapping(host.Alias, path, path.RedirTo)
+				target := redirToTarget(path, false)
+				commonMaps.RedirToMap.AddHostPathMapping(host, path, target)
+				commonMaps.RedirToMap.AddAliasPathMapping(host.Alias, path, target)
 			}
 			if hasVarNamespace {
 				// add "-" on missing paths to avoid overlap
@@ -281,7 +337,7 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 				} else {
 					ns = "-"
 				}
-				commonMaps.VarNamespaceMap.AddHostnamePathMapping(host.Hostname, path, ns)
+				commonMaps.VarNamespaceMap.AddHostPathMapping(host, path, ns)
 			}
 		}
 		if host.SSLPassthrough {
@@ -295,16 +351,16 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 		}
 		if f.IsHTTPS && host.HasTLSAuth() {
 			if host.TLS.CAVerify != hatypes.CAVerifySkipCheck {
-				httpsMaps.TLSAuthList.AddHostnameMapping(host.Hostname, "")
+				httpsMaps.TLSAuthList.AddHostMapping(host, "")
 			}
 			if !host.TLS.CAVerifyOptional() {
-				httpsMaps.TLSNeedCrtList.AddHostnameMapping(host.Hostname, "")
+				httpsMaps.TLSNeedCrtList.AddHostMapping(host, "")
 			}
 			page := host.TLS.CAErrorPage
 			if page != "" {
-				httpsMaps.TLSInvalidCrtPagesMap.AddHostnameMapping(host.Hostname, page)
+				httpsMaps.TLSInvalidCrtPagesMap.AddHostMapping(host, page)
 				if !host.TLS.CAVerifyOptional() {
-					httpsMaps.TLSMissingCrtPagesMap.AddHostnameMapping(host.Hostname, page)
+					httpsMaps.TLSMissingCrtPagesMap.AddHostMapping(host, page)
 				}
 			}
 		}
@@ -326,9 +382,9 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 				return redir
 			}
 			if !f.IsHTTPS && redirectssl() {
-				httpMaps.RedirRootSSLMap.AddHostnameMapping(host.Hostname, "")
+				httpMaps.RedirRootSSLMap.AddHostMapping(host, "")
 			}
-			commonMaps.RedirFromRootMap.AddHostnameMapping(host.Hostname, host.RootRedirect)
+			commonMaps.RedirFromRootMap.AddHostMapping(host, host.RootRedirect)
 		}
 		if !f.IsHTTPS {
 			continue
@@ -377,6 +433,7 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 				crtListEntry = fmt.Sprintf("%s [%s] %s", crtFile, strings.Join(bindConf, " "), host.Hostname)
 			}
 			crtListItems = append(crtListItems, &hatypes.HostsMapEntry{Key: crtListEntry})
+			crtList = append(crtList, &crtListLine{crtFile: crtFile, caFile: tls.CAFilename, line: crtListEntry})
 		}
 	}
 	if f.IsHTTPS {
@@ -389,6 +446,13 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 	}
 	f.HTTPMaps = httpMaps
 	f.HTTPSMaps = httpsMaps
+	if c.frontendMaps == nil {
+		c.frontendMaps = map[*hatypes.Frontend]*frontendMaps{}
+	}
+	c.frontendMaps[f] = &frontendMaps{
+		maps:    mapBuilder,
+		crtList: crtList,
+	}
 	return nil
 }
 
@@ -398,12 +462,15 @@ func (c *config) writeFrontendMaps(f *hatypes.Frontend) error {
 // link to the backend maps.
 func (c *config) WriteBackendMaps() error {
 	// TODO rename HostMap types to HAProxyMap
-	if !c.backends.Changed() {
+	backends := c.backends.ItemsAdd()
+	if c.rewrite {
+		backends = c.backends.Items()
+	} else if !c.backends.Changed() {
 		// backends are clean, maps are updated
 		return nil
 	}
 	mapBuilder := hatypes.CreateMaps(c.global.MatchOrder)
-	for _, backend := range c.backends.ItemsAdd() {
+	for _, backend := range backends {
 		if !backend.NeedACL() {
 			continue
 		}
@@ -417,7 +484,7 @@ func (c *config) WriteBackendMaps() error {
 					// using DefaultHost ID as hostname, see types/maps.go/buildMapKey()
 					pathsMap.DefMap.AddHostnamePathMapping(hatypes.DefaultHost, path, path.ID)
 				} else {
-					pathsMap.ReqMap.AddHostnamePathMapping(path.Hostname(), path, path.ID)
+					pathsMap.ReqMap.AddPathMapping(path, path.ID)
 				}
 			}
 		}
@@ -460,6 +527,24 @@ func (c *config) Userlists() *hatypes.Userlists {
 	return c.userlists
 }
 
+func (c *config) Quarantine() hatypes.SnippetQuarantine {
+	return c.quarantine
+}
+
+// PruneQuarantine removes from the quarantine the snippets that are not used
+// by the backends anymore, eg the snippet changed or its resource was removed.
+func (c *config) PruneQuarantine() {
+	ids := map[string]bool{}
+	for _, backend := range c.backends.Items() {
+		for _, source := range []*hatypes.SnippetSource{backend.CustomConfigSources.Early, backend.CustomConfigSources.Late} {
+			if source != nil {
+				ids[source.ID()] = true
+			}
+		}
+	}
+	c.quarantine.Retain(ids)
+}
+
 func (c *config) Clear() {
 	config := createConfig(c.options)
 
@@ -468,6 +553,9 @@ func (c *config) Clear() {
 	config.backends = c.backends
 	config.backends.Clear()
 
+	// rejected snippets continue rejected, they are only released if changed
+	config.quarantine = c.quarantine
+
 	*c = *config
 }
 
@@ -476,6 +564,14 @@ func (c *config) Shrink() {
 	c.backends.Shrink()
 }
 
+// Rewrite flags all the maps and backend shards to be written again by the
+// current update, including the ones that didn't change. Should be called
+// after Shrink.
+func (c *config) Rewrite() {
+	c.rewrite = true
+	c.backends.ChangeAllShards()
+}
+
 func (c *config) Commit() {
 	if !reflect.DeepEqual(c.globalOld, c.global) {
 		// globals still uses the old deepCopy+fullParsing+deepEqual strategy
@@ -486,11 +582,22 @@ func (c *config) Commit() {
 		c.globalOld = &globalOld
 	}
 	c.frontends.Commit()
+	frontendMapsOld := make(map[*hatypes.Frontend]*frontendMaps, len(c.frontendMaps))
+	for _, f := range c.frontends.Items() {
+		if maps := c.frontendMaps[f]; maps != nil {
+			frontendMapsOld[f] = maps
+		} else if maps := c.frontendMapsOld[f]; maps != nil {
+			frontendMapsOld[f] = maps
+		}
+	}
+	c.frontendMapsOld = frontendMapsOld
+	c.frontendMaps = nil
 	c.backends.Commit()
 	c.tcpbackends.Commit()
 	c.tcpservices.Commit()
 	c.userlists.Commit()
 	c.acmeData.Storages().Commit()
+	c.rewrite = false
 }
 
 func (c *config) hasCommittedData() bool {
@@ -18,7 +18,7 @@ package haproxy
 
 import (
 	"context"
-	"strings"
+	"errors"
 	"sync"
 	"time"
 
@@ -101,27 +101,16 @@ func (c *connections) shrinkConns() {
 }
 
 func shutdownSessionsSync(sock socket.HAProxySocket, duration time.Duration) {
-	sess, err := sock.Send(nil, "show sess")
+	client := socket.NewClient(sock, nil)
+	sessionList, err := client.ShowSess()
 	if err != nil {
 		return
 	}
-	// sess output:
-	//
-	// 0x7f9440810000: proto=unix_stream src=...
-	// 0x7f943f87f200: proto=unix_stream src=...
-	// 0x7f9442808200: proto=unix_stream src=...
-	// ...
-	var sessionList []string
-	for _, s := range strings.Split(sess[0], "\n") {
-		i := strings.Index(s, ":")
-		if i > 0 {
-			sessionList = append(sessionList, s[:i])
-		}
-	}
 	interval := duration / time.Duration((len(sessionList) + 1))
 	for _, s := range sessionList {
-		_, err := sock.Send(nil, "shutdown session "+s)
-		if err != nil {
+		err := client.ShutdownSession(s)
+		var cmdErr *socket.CommandError
+		if err != nil && !errors.As(err, &cmdErr) {
 			// maybe the connection or the instance is gone,
 			// haproxy takes care of the remaining sessions if any
 			return
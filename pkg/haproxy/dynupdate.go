@@ -17,25 +17,33 @@ limitations under the License.
 package haproxy
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/socket"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/template"
 	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
 	"github.com/jcmoraisjr/haproxy-ingress/pkg/types"
 )
 
 type dynUpdater struct {
-	logger  types.Logger
-	config  *config
-	socket  socket.HAProxySocket
-	cmdCnt  int
-	metrics types.Metrics
+	logger     types.Logger
+	config     *config
+	socket     socket.HAProxySocket
+	cmdCnt     int
+	metrics    types.Metrics
+	dynServers func() bool
+	sslFileUpd func() bool
+	serverTmpl *template.Config
+	certs      map[string]bool
+	sslFiles   map[string]bool
 }
 
 type hostPair struct {
@@ -55,13 +63,28 @@ type epPair struct {
 
 func (i *instance) newDynUpdater() *dynUpdater {
 	return &dynUpdater{
-		logger:  i.logger,
-		config:  i.config.(*config),
-		socket:  i.conns.DynUpdate(),
-		metrics: i.metrics,
+		logger:     i.logger,
+		config:     i.config.(*config),
+		socket:     i.conns.DynUpdate(),
+		metrics:    i.metrics,
+		dynServers: i.hasDynServers,
+		sslFileUpd: i.hasSSLFileUpdate,
+		serverTmpl: i.haproxyTmpl,
 	}
 }
 
+// hasDynServers checks if the running haproxy supports adding and removing
+// servers via the runtime API.
+func (i *instance) hasDynServers() bool {
+	return i.HAProxyVersion().Supports(hatypes.FeatureDynServers)
+}
+
+// hasSSLFileUpdate checks if the running haproxy supports updating the content
+// of CA and CRL files via the runtime API.
+func (i *instance) hasSSLFileUpdate() bool {
+	return i.HAProxyVersion().Supports(hatypes.FeatureUpdateSSLFile)
+}
+
 func (d *dynUpdater) update() bool {
 	updated := d.config.hasCommittedData() && d.checkConfigChange()
 	if !updated {
@@ -72,12 +95,13 @@ func (d *dynUpdater) update() bool {
 }
 
 // checkConfigChange defines if dynamic update was successfully applied.
-// The updated host and backend lists are fully verified even if a restart
-// should be made in order to leave haproxy as update as possible if a
-// reload fails.
+// The update runs in two steps: every section applies the changes it can
+// via runtime API, and the sections that still differ are listed and need
+// a reload. The updated host and backend lists are fully verified even if a
+// reload should be made, so urgent changes, like endpoints, are not delayed
+// by a reload that might be deferred, and haproxy stays as updated as possible
+// if a reload fails.
 func (d *dynUpdater) checkConfigChange() bool {
-	// TODO use two steps update and perform full dynamic update only if the reload failed.
-
 	var diff []string
 	if d.config.globalOld != nil && !reflect.DeepEqual(d.config.globalOld, d.config.global) {
 		diff = append(diff, "global")
@@ -120,20 +144,33 @@ func (d *dynUpdater) frontendUpdated(f *hatypes.Frontend) bool {
 		id := host.Hostname
 		h, found := hosts[id]
 		if !found {
-			d.logger.InfoV(2, "added host '%s'", id)
-			updated = false
+			hosts[id] = &hostPair{cur: host}
 		} else {
 			h.cur = host
 		}
 	}
 
-	for _, pair := range hosts {
-		if pair.cur == nil {
-			d.logger.InfoV(2, "removed host '%s'", pair.old.Hostname)
-			updated = false
-		} else if !d.checkHostPair(pair) {
+	// sorting only to have predictable results (tests)
+	hostnames := make([]string, 0, len(hosts))
+	for hostname := range hosts {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	var mapsChanged bool
+	for _, hostname := range hostnames {
+		hostUpdated, hostMapsChanged := d.checkHostPair(hosts[hostname])
+		if !hostUpdated {
 			updated = false
 		}
+		if hostMapsChanged {
+			mapsChanged = true
+		}
+	}
+
+	// hosts differ only in the config written in the maps, try to update them as well
+	if updated && mapsChanged && !d.updateFrontendMaps(f) {
+		updated = false
 	}
 
 	return updated
@@ -171,21 +208,47 @@ func (d *dynUpdater) backendUpdated() bool {
 	return updated
 }
 
-func (d *dynUpdater) checkHostPair(pair *hostPair) bool {
+// checkHostPair compares the old and the current state of a host, either of them might be
+// missing. mapsChanged is true if both differ, and the differences might be written in the maps.
+func (d *dynUpdater) checkHostPair(pair *hostPair) (updated, mapsChanged bool) {
 	oldHost := pair.old
 	curHost := pair.cur
 
-	updated := true
+	// added and removed hosts can be dynamically updated
+	// if all of their configuration lives in the maps
+	if oldHost == nil {
+		if !isMapsOnlyHost(curHost) {
+			d.logger.InfoV(2, "added host '%s'", curHost.Hostname)
+			return false, true
+		}
+		return true, true
+	}
+	if curHost == nil {
+		if !isMapsOnlyHost(oldHost) {
+			d.logger.InfoV(2, "removed host '%s'", oldHost.Hostname)
+			return false, true
+		}
+		return true, true
+	}
+
+	updated = true
 
-	// check equality of everything but server certificate
+	// check equality of everything but server certificate and CA/CRL content,
+	// and also if the differences are restricted to the maps
 	// TODO move this check to the host type
 	oldHostCopy := *oldHost
 	oldHostCopy.TLS.TLSCommonName = curHost.TLS.TLSCommonName
 	oldHostCopy.TLS.TLSHash = curHost.TLS.TLSHash
 	oldHostCopy.TLS.TLSNotAfter = curHost.TLS.TLSNotAfter
+	oldHostCopy.TLS.CAHash = curHost.TLS.CAHash
+	oldHostCopy.TLS.CRLHash = curHost.TLS.CRLHash
 	if !reflect.DeepEqual(&oldHostCopy, curHost) {
-		d.logger.InfoV(2, "diff outside server certificate of host '%s'", curHost.Hostname)
-		updated = false
+		mapsChanged = true
+		if !reflect.DeepEqual(hostStaticConfig(oldHost), hostStaticConfig(curHost)) ||
+			((hasAuthExternal(oldHost) || hasAuthExternal(curHost)) && !reflect.DeepEqual(oldHost.Paths, curHost.Paths)) {
+			d.logger.InfoV(2, "diff outside server certificate of host '%s'", curHost.Hostname)
+			updated = false
+		}
 	}
 
 	if curHost.TLS.HasTLS() && oldHost.TLS.TLSHash != curHost.TLS.TLSHash &&
@@ -194,7 +257,312 @@ func (d *dynUpdater) checkHostPair(pair *hostPair) bool {
 		updated = false
 	}
 
-	return updated
+	if !d.checkSSLFile("ca-file", curHost.Hostname, oldHost.TLS.CAFilename, curHost.TLS.CAFilename, oldHost.TLS.CAHash, curHost.TLS.CAHash) {
+		updated = false
+	}
+	if !d.checkSSLFile("crl-file", curHost.Hostname, oldHost.TLS.CRLFilename, curHost.TLS.CRLFilename, oldHost.TLS.CRLHash, curHost.TLS.CRLHash) {
+		updated = false
+	}
+
+	return updated, mapsChanged
+}
+
+// checkSSLFile updates the content of a CA or CRL file, of the kind `ca-file` or `crl-file`,
+// whose filename was preserved but the hash changed. A changed filename is handled by the
+// caller, since it changes the configuration. A reload is needed if the running haproxy
+// cannot update the file.
+func (d *dynUpdater) checkSSLFile(kind, owner, oldFilename, curFilename, oldHash, curHash string) bool {
+	if curFilename == "" || oldFilename != curFilename || oldHash == curHash {
+		return true
+	}
+	if !d.sslFileUpd() {
+		d.logger.InfoV(2, "%s of %s changed and haproxy does not support updating it", kind, owner)
+		return false
+	}
+	if d.sslFiles[curFilename] {
+		// already updated, file shared by more than one host or backend
+		return true
+	}
+	if !d.execUpdateSSLFile(kind, owner, curFilename) {
+		return false
+	}
+	if d.sslFiles == nil {
+		d.sslFiles = map[string]bool{}
+	}
+	d.sslFiles[curFilename] = true
+	return true
+}
+
+// hostStaticConfig returns a copy of host without the configurations
+// written in the frontend maps or in the crt list, which are compared
+// in their final form by updateFrontendMaps(), and without the hash of
+// the CA and CRL files, which are updated in place.
+func hostStaticConfig(host *hatypes.Host) hatypes.Host {
+	h := *host
+	h.Frontend = nil
+	h.Hostname = ""
+	h.Paths = nil
+	h.Alias = hatypes.HostAliasConfig{}
+	h.Redirect = hatypes.HostRedirectConfig{}
+	h.RootRedirect = ""
+	h.VarNamespace = false
+	h.CustomHTTPResponses.ID = ""
+	h.TLS.ALPN = ""
+	h.TLS.Ciphers = ""
+	h.TLS.CipherSuites = ""
+	h.TLS.Options = ""
+	h.TLS.CAHash = ""
+	h.TLS.CRLHash = ""
+	h.TLS.TLSCommonName = ""
+	h.TLS.TLSFilename = ""
+	h.TLS.TLSHash = ""
+	h.TLS.TLSNotAfter = time.Time{}
+	return h
+}
+
+func isMapsOnlyHost(host *hatypes.Host) bool {
+	return reflect.DeepEqual(hostStaticConfig(host), hostStaticConfig(&hatypes.Host{})) && !hasAuthExternal(host)
+}
+
+// hasAuthExternal checks if the frontend has auth external config
+// rendered from the paths of the host.
+func hasAuthExternal(host *hatypes.Host) bool {
+	for _, path := range host.Paths {
+		if path.AuthExtFront.AuthPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// updateFrontendMaps applies the differences between the last committed and
+// the current maps of a frontend via runtime API. Maps cannot be dynamically
+// updated if the configuration would change, e.g. a match file is added or
+// removed, or if the order of the entries cannot be preserved.
+func (d *dynUpdater) updateFrontendMaps(f *hatypes.Frontend) bool {
+	oldMaps := d.config.frontendMapsOld[f]
+	curMaps := d.config.frontendMaps[f]
+	if oldMaps == nil || curMaps == nil || len(oldMaps.maps.Items) != len(curMaps.maps.Items) {
+		d.logger.InfoV(2, "maps of frontend '%s' are not comparable", f.Name)
+		return false
+	}
+	crtCmd, ok := d.crtListCommands(f, oldMaps.crtList, curMaps.crtList)
+	if !ok {
+		return false
+	}
+	var cmd []*socket.Cmd
+	for i, curMap := range curMaps.maps.Items {
+		oldMap := oldMaps.maps.Items[i]
+		oldFiles := oldMap.MatchFiles()
+		curFiles := curMap.MatchFiles()
+		if oldMap.HasHost() != curMap.HasHost() || len(oldFiles) != len(curFiles) {
+			d.logger.InfoV(2, "match files of frontend '%s' changed", f.Name)
+			return false
+		}
+		for j, curFile := range curFiles {
+			oldFile := oldFiles[j]
+			if !curFile.HasSameLayout(oldFile) {
+				d.logger.InfoV(2, "match files of frontend '%s' changed", f.Name)
+				return false
+			}
+			fileCmd, ok := mapFileCommands(oldFile, curFile)
+			if !ok {
+				d.logger.InfoV(2, "cannot dynamically update map file '%s'", curFile.Filename())
+				return false
+			}
+			cmd = append(cmd, fileCmd...)
+		}
+	}
+	// certificates need to be in place before the maps route requests to the new hosts
+	if len(crtCmd) > 0 {
+		if !d.execFrontendCommands(f, "crt list", d.metrics.HAProxySetSSLCertResponseTime, crtCmd) {
+			return false
+		}
+	}
+	if len(cmd) > 0 {
+		if !d.execFrontendCommands(f, "maps", d.metrics.HAProxySetMapResponseTime, cmd) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *dynUpdater) execFrontendCommands(f *hatypes.Frontend, target string, observer func(duration time.Duration), cmd []*socket.Cmd) bool {
+	if failed, err := d.execCmds(observer, cmd...); err != nil {
+		d.logger.Error("error updating %s of frontend '%s': %v", target, f.Name, err)
+		return false
+	} else if failed != nil {
+		d.logger.Warn("unrecognized response updating %s of frontend '%s' with '%s': %s",
+			target, f.Name, strings.SplitN(failed.String(), "\n", 2)[0], oneLine(failed.Response))
+		return false
+	}
+	d.logger.InfoV(2, "updated %s of frontend '%s': %d command(s)", target, f.Name, len(cmd))
+	return true
+}
+
+// crtListCommands builds the runtime API commands that update the crt list of a frontend
+// from old to cur, loading the certificates that are not known by haproxy yet. The default
+// certificate cannot be changed, and removed entries are only deleted if their certificate
+// is used once in the crt list, since haproxy would need the line number otherwise. Entries
+// with a CA file are not added, since haproxy would need to load the file from the disk.
+func (d *dynUpdater) crtListCommands(f *hatypes.Frontend, oldList, curList []*crtListLine) (cmd []*socket.Cmd, ok bool) {
+	if slices.EqualFunc(oldList, curList, func(l1, l2 *crtListLine) bool { return l1.line == l2.line }) {
+		return nil, true
+	}
+	if len(oldList) == 0 || len(curList) == 0 || oldList[0].line != curList[0].line {
+		d.logger.InfoV(2, "default certificate of frontend '%s' changed", f.Name)
+		return nil, false
+	}
+	oldLines := make(map[string]bool, len(oldList))
+	oldCrtCount := make(map[string]int, len(oldList))
+	for _, entry := range oldList {
+		oldLines[entry.line] = true
+		oldCrtCount[entry.crtFile]++
+	}
+	curLines := make(map[string]bool, len(curList))
+	for _, entry := range curList {
+		curLines[entry.line] = true
+	}
+	for _, entry := range oldList {
+		if !curLines[entry.line] {
+			if oldCrtCount[entry.crtFile] > 1 {
+				d.logger.InfoV(2, "cannot remove certificate '%s' from the crt list of frontend '%s': used more than once", entry.crtFile, f.Name)
+				return nil, false
+			}
+			cmd = append(cmd, socket.DelSSLCrtListCmd(f.CrtListFile, entry.crtFile))
+			if !d.certInUse(entry.crtFile) {
+				cmd = append(cmd, socket.DelSSLCertCmd(entry.crtFile))
+				delete(d.loadedCerts(), entry.crtFile)
+			}
+		}
+	}
+	for _, entry := range curList {
+		if oldLines[entry.line] {
+			continue
+		}
+		if entry.caFile != "" {
+			d.logger.InfoV(2, "cannot add certificate '%s' to the crt list of frontend '%s': has a CA file", entry.crtFile, f.Name)
+			return nil, false
+		}
+		if !d.loadedCerts()[entry.crtFile] {
+			payload, err := readCertPayload(entry.crtFile)
+			if err != nil {
+				d.logger.Error("error reading certificate file '%s': %v", entry.crtFile, err)
+				return nil, false
+			}
+			cmd = append(cmd,
+				socket.NewSSLCertCmd(entry.crtFile),
+				socket.SetSSLCertCmd(entry.crtFile, payload),
+				socket.CommitSSLCertCmd(entry.crtFile),
+			)
+			d.certs[entry.crtFile] = true
+		}
+		cmd = append(cmd, socket.AddSSLCrtListCmd(f.CrtListFile, entry.line))
+	}
+	return cmd, true
+}
+
+// loadedCerts lists the certificates that haproxy already has in its certificate
+// storage, starting with the ones referenced by the crt lists of the last update.
+func (d *dynUpdater) loadedCerts() map[string]bool {
+	if d.certs == nil {
+		d.certs = map[string]bool{}
+		for _, maps := range d.config.frontendMapsOld {
+			for _, entry := range maps.crtList {
+				d.certs[entry.crtFile] = true
+			}
+		}
+	}
+	return d.certs
+}
+
+// certInUse checks if a certificate is referenced by any crt list of the current update.
+func (d *dynUpdater) certInUse(crtFile string) bool {
+	for _, maps := range d.config.frontendMaps {
+		for _, entry := range maps.crtList {
+			if entry.crtFile == crtFile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapFileCommands builds the runtime API commands that update the entries
+// of a map file from old to cur. Keys are removed, added or changed in place;
+// false is returned if the order of the entries matters and would not be the
+// same after the update. Entries without a value are pattern files used by
+// ACLs, where the order doesn't matter.
+func mapFileCommands(oldFile, curFile *hatypes.MatchFile) (cmd []*socket.Cmd, ok bool) {
+	oldValues, oldOK := mapFileValues(oldFile)
+	curValues, curOK := mapFileValues(curFile)
+	if !oldOK || !curOK {
+		// duplicated keys, cannot be addressed by the runtime API
+		return nil, false
+	}
+	isACL := true
+	for _, entry := range append(slices.Clone(oldFile.Values()), curFile.Values()...) {
+		if entry.Value != "" {
+			isACL = false
+			break
+		}
+	}
+	filename := curFile.Filename()
+	for _, entry := range oldFile.Values() {
+		if _, found := curValues[entry.Key]; !found {
+			if isACL {
+				cmd = append(cmd, socket.DelACLCmd(filename, entry.Key))
+			} else {
+				cmd = append(cmd, socket.DelMapCmd(filename, entry.Key))
+			}
+		}
+	}
+	for _, entry := range curFile.Values() {
+		oldValue, found := oldValues[entry.Key]
+		if !found {
+			if !isACL && !canAppendMapEntry(curFile, entry) {
+				return nil, false
+			}
+			if isACL {
+				cmd = append(cmd, socket.AddACLCmd(filename, entry.Key))
+			} else {
+				cmd = append(cmd, socket.AddMapCmd(filename, entry.Key, entry.Value))
+			}
+		} else if oldValue != entry.Value {
+			cmd = append(cmd, socket.SetMapCmd(filename, entry.Key, entry.Value))
+		}
+	}
+	return cmd, true
+}
+
+func mapFileValues(matchFile *hatypes.MatchFile) (values map[string]string, ok bool) {
+	values = make(map[string]string, len(matchFile.Values()))
+	for _, entry := range matchFile.Values() {
+		if _, found := values[entry.Key]; found {
+			return nil, false
+		}
+		values[entry.Key] = entry.Value
+	}
+	return values, true
+}
+
+// canAppendMapEntry checks if entry, added in the end of the map by the runtime API,
+// would give the same result as the sorted map file. Exact match uses a lookup tree
+// where the order doesn't matter; beg and dir matches need that no other entry is a
+// prefix of the new one or vice versa; the order of regex matches is always preserved.
+func canAppendMapEntry(matchFile *hatypes.MatchFile, entry *hatypes.HostsMapEntry) bool {
+	switch matchFile.Method() {
+	case "str":
+		return true
+	case "beg", "dir":
+		for _, other := range matchFile.Values() {
+			if other != entry && (strings.HasPrefix(other.Key, entry.Key) || strings.HasPrefix(entry.Key, other.Key)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
 }
 
 func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
@@ -213,16 +581,43 @@ func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
 	oldBackCopy.ID = curBack.ID
 	oldBackCopy.Dynamic = curBack.Dynamic
 	oldBackCopy.Endpoints = curBack.Endpoints
+	oldBackCopy.Server.CAHash = curBack.Server.CAHash
+	oldBackCopy.Server.CRLHash = curBack.Server.CRLHash
+	if oldBack.HasSamePathsConfig(curBack) {
+		// paths are added or removed via frontend maps
+		oldBackCopy.SharePaths(curBack)
+	}
+	// server parameters are updated via runtime API, so the same servers
+	// and empty slots should be found, which doesn't happen on DNS based updates
+	var params []serverParam
+	if curBack.Dynamic.DynUpdate && curBack.Resolver == "" {
+		params = serverParamsDiff(&oldBackCopy, curBack)
+	}
 	if !reflect.DeepEqual(&oldBackCopy, curBack) {
 		d.logger.InfoV(2, "diff outside endpoints of backend '%s'", curBack.ID)
 		updated = false
 	}
 
-	// can decrease endpoints, cannot increase
-	if len(oldBack.Endpoints) < len(curBack.Endpoints) {
+	// CA and CRL files are updated in place, the backend config doesn't change
+	if !d.checkSSLFile("ca-file", curBack.ID, oldBack.Server.CAFilename, curBack.Server.CAFilename, oldBack.Server.CAHash, curBack.Server.CAHash) {
+		updated = false
+	}
+	if !d.checkSSLFile("crl-file", curBack.ID, oldBack.Server.CRLFilename, curBack.Server.CRLFilename, oldBack.Server.CRLHash, curBack.Server.CRLHash) {
+		updated = false
+	}
+
+	// servers of oldBack are the ones currently declared in haproxy
+	if len(params) > 0 && !d.execUpdateServerParams(oldBack, params) {
+		updated = false
+	}
+
+	// can decrease endpoints, can only increase if haproxy supports dynamic servers
+	canAddServers := d.canAddServers(curBack)
+	if len(oldBack.Endpoints) < len(curBack.Endpoints) && !canAddServers {
 		d.logger.InfoV(2, "added endpoints on backend '%s'", curBack.ID)
-		// cannot continue -- missing empty slots in the backend
-		return false
+		// missing empty slots in the backend, the existing ones are
+		// still updated, new servers are created by the reload
+		updated = false
 	}
 
 	// Resolver == update via DNS discovery
@@ -261,7 +656,10 @@ func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
 	}
 
 	// reuse the backend/server which has the same target endpoint, if found,
-	// this will save some socket calls and will not mess endpoint metrics
+	// this will save some socket calls and will not mess endpoint metrics.
+	// Names are reused even if a reload is already needed, so the reloaded
+	// haproxy declares the endpoint in the same server it is currently using,
+	// preserving its state and the runtime changes applied below
 	var added []*hatypes.Endpoint
 	for _, endpoint := range curBack.Endpoints {
 		if pair, found := endpoints[endpoint.Target]; found {
@@ -286,12 +684,21 @@ func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
 		if pair.cur == nil {
 			if !d.execDisableEndpoint(curBack.ID, pair.old) || pair.old.Label != "" {
 				updated = false
+			} else if canAddServers && d.execDelEndpoint(curBack.ID, pair.old) {
+				// server removed from haproxy, it cannot be used as an empty slot
+				continue
 			}
 			empty = append(empty, pair.old)
 		} else if !d.checkEndpointPair(curBack, pair) {
 			updated = false
 		}
 	}
+	// new servers are created only when all the empty slots are in use
+	var created []*hatypes.Endpoint
+	if len(added) > len(empty) {
+		created = added[len(empty):]
+		added = added[:len(empty)]
+	}
 	for i := range added {
 		// reusing empty slots from oldBack
 		added[i].Name = empty[i].Name
@@ -304,6 +711,21 @@ func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
 		}
 	}
 
+	if len(created) > 0 {
+		// names of the servers still declared in haproxy should not be reused
+		names := make(map[string]bool, len(oldBack.Endpoints)+len(created))
+		for _, ep := range oldBack.Endpoints {
+			names[ep.Name] = true
+		}
+		for _, ep := range created {
+			ep.Name = uniqueServerName(curBack.EpNaming, ep.Name, names)
+			names[ep.Name] = true
+			if !canAddServers || !d.execAddEndpoint(curBack, ep) || ep.Label != "" {
+				updated = false
+			}
+		}
+	}
+
 	// copy remaining empty slots from oldBack to curBack, so it can be used in a future update
 	for i := len(added); i < len(empty); i++ {
 		curBack.AddEmptyEndpoint().Name = empty[i].Name
@@ -312,6 +734,124 @@ func (d *dynUpdater) checkBackendPair(pair *backendPair) bool {
 	return updated
 }
 
+// serverParam is a server parameter that can be changed via runtime API.
+// key is the configuration key, used in the logging, and cmd builds the
+// command for a single backend/server, nil if there is nothing to send.
+type serverParam struct {
+	key string
+	cmd func(backend, server string) *socket.Cmd
+}
+
+// serverParamsDiff returns the server parameters that differ between oldCopy and cur
+// and can be changed via runtime API. Such parameters are copied from cur to oldCopy,
+// so only the changes that need new configuration lines remain.
+//
+// The interval, rise and fall of health and agent checks remain: the runtime API
+// has no command that changes them. There is also no backend level maxconn to
+// update, the limit of a backend is the maxconn of its servers.
+func serverParamsDiff(oldCopy, cur *hatypes.Backend) []serverParam {
+	var params []serverParam
+	oldServer := &oldCopy.Server
+	if oldServer.MaxConn != cur.Server.MaxConn {
+		maxconn := cur.Server.MaxConn
+		params = append(params, serverParam{
+			key: "maxconn-server",
+			cmd: func(backend, server string) *socket.Cmd { return socket.SetMaxConnServerCmd(backend, server, maxconn) },
+		})
+		oldServer.MaxConn = maxconn
+	}
+	if oldServer.InitialWeight != cur.Server.InitialWeight {
+		// the weight of the servers is part of the endpoints,
+		// which are compared and updated later
+		params = append(params, serverParam{key: "initial-weight"})
+		oldServer.InitialWeight = cur.Server.InitialWeight
+	}
+	oldHC := &oldCopy.HealthCheck
+	if hasHealthCheck(*oldHC) && hasHealthCheck(cur.HealthCheck) {
+		// check port and addr cannot be removed, this needs a reload
+		if oldHC.Port != cur.HealthCheck.Port && cur.HealthCheck.Port > 0 {
+			port := cur.HealthCheck.Port
+			params = append(params, serverParam{
+				key: "health-check-port",
+				cmd: func(backend, server string) *socket.Cmd {
+					return socket.SetServerCmd(backend, server, "check-port", strconv.Itoa(port))
+				},
+			})
+			oldHC.Port = port
+		}
+		if oldHC.Addr != cur.HealthCheck.Addr && cur.HealthCheck.Addr != "" {
+			addr := cur.HealthCheck.Addr
+			params = append(params, serverParam{
+				key: "health-check-addr",
+				cmd: func(backend, server string) *socket.Cmd {
+					return socket.SetServerCmd(backend, server, "check-addr", addr)
+				},
+			})
+			oldHC.Addr = addr
+		}
+	}
+	oldAgent := &oldCopy.AgentCheck
+	if oldAgent.Port > 0 && cur.AgentCheck.Port > 0 {
+		if oldAgent.Port != cur.AgentCheck.Port {
+			port := cur.AgentCheck.Port
+			params = append(params, serverParam{
+				key: "agent-check-port",
+				cmd: func(backend, server string) *socket.Cmd {
+					return socket.SetServerCmd(backend, server, "agent-port", strconv.Itoa(port))
+				},
+			})
+			oldAgent.Port = port
+		}
+		if oldAgent.Addr != cur.AgentCheck.Addr && cur.AgentCheck.Addr != "" {
+			addr := cur.AgentCheck.Addr
+			params = append(params, serverParam{
+				key: "agent-check-addr",
+				cmd: func(backend, server string) *socket.Cmd {
+					return socket.SetServerCmd(backend, server, "agent-addr", addr)
+				},
+			})
+			oldAgent.Addr = addr
+		}
+		if oldAgent.Send != cur.AgentCheck.Send && cur.AgentCheck.Send != "" {
+			send := cur.AgentCheck.Send
+			params = append(params, serverParam{
+				key: "agent-check-send",
+				cmd: func(backend, server string) *socket.Cmd {
+					return socket.SetServerCmd(backend, server, "agent-send", send)
+				},
+			})
+			oldAgent.Send = send
+		}
+	}
+	return params
+}
+
+// canAddServers checks if new servers can be added to the backend via
+// runtime API, instead of reusing empty slots.
+func (d *dynUpdater) canAddServers(backend *hatypes.Backend) bool {
+	// source IPs are assigned only after the dynamic update
+	return backend.Dynamic.DynUpdate && backend.Resolver == "" && len(backend.SourceIPs) == 0 && d.dynServers()
+}
+
+func uniqueServerName(naming hatypes.EndpointNaming, name string, names map[string]bool) string {
+	if !names[name] {
+		return name
+	}
+	if naming == hatypes.EpSequence {
+		// use the next free sequence number
+		for i := len(names) + 1; ; i++ {
+			if sname := fmt.Sprintf("srv%03d", i); !names[sname] {
+				return sname
+			}
+		}
+	}
+	for i := 2; ; i++ {
+		if sname := fmt.Sprintf("%s__%d", name, i); !names[sname] {
+			return sname
+		}
+	}
+}
+
 func (d *dynUpdater) checkEndpointPair(backend *hatypes.Backend, pair *epPair) bool {
 	oldEPCopy := *pair.old
 	// SourceIP is lazily updated via FillSourceIPs() after dynupdate run
@@ -335,8 +875,9 @@ func (d *dynUpdater) checkEndpointPair(backend *hatypes.Backend, pair *epPair) b
 func (d *dynUpdater) alignSlots() {
 	backends := d.config.Backends()
 	for _, back := range backends.Items() {
-		if !back.Dynamic.DynUpdate {
-			// no need to add empty slots if won't dynamically update
+		if !back.Dynamic.DynUpdate || d.canAddServers(back) {
+			// no need to add empty slots if won't dynamically update,
+			// or if new servers can be added via runtime API
 			continue
 		}
 		minFreeSlots := back.Dynamic.MinFreeSlots
@@ -383,32 +924,34 @@ func (d *dynUpdater) alignSlots() {
 
 var readFile = os.ReadFile
 
-func (d *dynUpdater) execUpdateCert(hostname, filename string) bool {
+func readCertPayload(filename string) (string, error) {
 	// TODO read from the internal storage
 	payload, err := readFile(filename)
 	if err != nil {
-		d.logger.Error("error reading certificate file for %s: %v", hostname, err)
-		return false
+		return "", err
 	}
 	// TODO removing an empty line between crt and key, runtime api didn't like it.
 	// Remove this work around after the factoring of the ssl storage.
-	payloadStr := strings.ReplaceAll(string(payload), "\n\n", "\n")
-	cmd := []string{
-		fmt.Sprintf("set ssl cert %s <<\n%s\n", filename, payloadStr),
-		fmt.Sprintf("commit ssl cert %s", filename),
+	return strings.ReplaceAll(string(payload), "\n\n", "\n"), nil
+}
+
+func (d *dynUpdater) execUpdateCert(hostname, filename string) bool {
+	payloadStr, err := readCertPayload(filename)
+	if err != nil {
+		d.logger.Error("error reading certificate file for %s: %v", hostname, err)
+		return false
 	}
-	msg, err := d.execCommand(d.metrics.HAProxySetSSLCertResponseTime, cmd)
+	cmd := []*socket.Cmd{
+		socket.SetSSLCertCmd(filename, payloadStr),
+		socket.CommitSSLCertCmd(filename),
+	}
+	failed, err := d.execCmds(d.metrics.HAProxySetSSLCertResponseTime, cmd...)
 	if err != nil {
 		d.logger.Error("error updating certificate for %s: %v", hostname, err)
 		return false
 	}
-	for _, m := range msg {
-		if m != "" {
-			outmsg := strings.ReplaceAll(strings.TrimRight(m, "\n"), "\n", " \\\\ ")
-			d.logger.InfoV(2, "response from server: %s", outmsg)
-		}
-	}
-	if !cmdResponseOK("commit ssl cert", msg[1]) {
+	d.logResponses(cmd)
+	if failed != nil {
 		d.logger.Warn("cannot update certificate for %s", hostname)
 		return false
 	}
@@ -416,72 +959,181 @@ func (d *dynUpdater) execUpdateCert(hostname, filename string) bool {
 	return true
 }
 
+func (d *dynUpdater) execUpdateSSLFile(kind, owner, filename string) bool {
+	payload, err := readCertPayload(filename)
+	if err != nil {
+		d.logger.Error("error reading %s for %s: %v", kind, owner, err)
+		return false
+	}
+	cmd := []*socket.Cmd{
+		socket.SetSSLFileCmd(kind, filename, payload),
+		socket.CommitSSLFileCmd(kind, filename),
+	}
+	failed, err := d.execCmds(d.metrics.HAProxySetSSLCertResponseTime, cmd...)
+	if err != nil {
+		d.logger.Error("error updating %s for %s: %v", kind, owner, err)
+		return false
+	}
+	if failed != nil {
+		d.logger.Warn("cannot update %s for %s: %s", kind, owner, oneLine(cmd[0].Response+cmd[1].Response))
+		return false
+	}
+	d.logger.Info("%s updated for %s", kind, owner)
+	return true
+}
+
+func (d *dynUpdater) execUpdateServerParams(backend *hatypes.Backend, params []serverParam) bool {
+	keys := make([]string, len(params))
+	var cmd []*socket.Cmd
+	for i, param := range params {
+		keys[i] = param.key
+		if param.cmd == nil {
+			continue
+		}
+		// empty slots are also updated, they can be enabled later without a reload
+		for _, ep := range backend.Endpoints {
+			cmd = append(cmd, param.cmd(backend.ID, ep.Name))
+		}
+	}
+	if len(cmd) > 0 {
+		failed, err := d.execCmds(d.metrics.HAProxySetServerResponseTime, cmd...)
+		if err != nil {
+			d.logger.Error("error updating servers of backend '%s': %v", backend.ID, err)
+			return false
+		}
+		if failed != nil {
+			d.logger.Warn("unrecognized response updating servers of backend '%s': %s", backend.ID, strings.TrimRight(failed.Response, "\n"))
+			return false
+		}
+	}
+	d.logger.Info("updated %v of backend '%s' via runtime API", keys, backend.ID)
+	return true
+}
+
 func (d *dynUpdater) execDisableEndpoint(backname string, ep *hatypes.Endpoint) bool {
-	server := fmt.Sprintf("set server %s/%s ", backname, ep.Name)
-	cmd := []string{
-		server + "state maint",
-		server + "addr 127.0.0.1 port 1023",
-		server + "weight 0",
+	cmd := []*socket.Cmd{
+		socket.SetServerCmd(backname, ep.Name, "state", "maint"),
+		socket.SetServerCmd(backname, ep.Name, "addr", "127.0.0.1", "port", "1023"),
+		socket.SetServerCmd(backname, ep.Name, "weight", "0"),
 	}
-	msg, err := d.execCommand(d.metrics.HAProxySetServerResponseTime, cmd)
+	failed, err := d.execCmds(d.metrics.HAProxySetServerResponseTime, cmd...)
 	if err != nil {
 		d.logger.Error("error disabling endpoint %s/%s: %v", backname, ep.Name, err)
 		return false
 	}
-	for _, m := range msg {
-		if m != "" {
-			if !cmdResponseOK("set server", m) {
-				d.logger.Warn("unrecognized response disabling endpoint %s/%s: %s", backname, ep.Name, m)
-				return false
-			}
-			d.logger.InfoV(2, "response from server: %s", m)
-		}
+	if failed != nil {
+		d.logger.Warn("unrecognized response disabling endpoint %s/%s: %s", backname, ep.Name, failed.Response)
+		return false
 	}
+	d.logResponses(cmd)
 	d.logger.InfoV(2, "disabled endpoint '%s' on backend/server '%s/%s'", ep.Target, backname, ep.Name)
 	return true
 }
 
 func (d *dynUpdater) execEnableEndpoint(backname string, oldEP, curEP *hatypes.Endpoint) bool {
 	state := map[bool]string{true: "ready", false: "drain"}[curEP.Weight > 0]
-	server := fmt.Sprintf("set server %s/%s ", backname, curEP.Name)
-	cmd := []string{
-		server + "addr " + curEP.IP + " port " + strconv.Itoa(curEP.Port),
-		server + "state " + state,
-		server + "weight " + strconv.Itoa(curEP.Weight),
+	cmd := []*socket.Cmd{
+		socket.SetServerCmd(backname, curEP.Name, "addr", curEP.IP, "port", strconv.Itoa(curEP.Port)),
+		socket.SetServerCmd(backname, curEP.Name, "state", state),
+		socket.SetServerCmd(backname, curEP.Name, "weight", strconv.Itoa(curEP.Weight)),
 	}
-	msg, err := d.execCommand(d.metrics.HAProxySetServerResponseTime, cmd)
+	failed, err := d.execCmds(d.metrics.HAProxySetServerResponseTime, cmd...)
 	if err != nil {
 		d.logger.Error("error adding/updating endpoint %s/%s: %v", backname, curEP.Name, err)
 		return false
 	}
-	for _, m := range msg {
-		if m != "" {
-			if !cmdResponseOK("set server", m) {
-				d.logger.Warn("unrecognized response adding/updating endpoint %s/%s: %s", backname, curEP.Name, m)
-				return false
-			}
-			d.logger.InfoV(2, "response from server: %s", m)
-		}
+	if failed != nil {
+		d.logger.Warn("unrecognized response adding/updating endpoint %s/%s: %s", backname, curEP.Name, failed.Response)
+		return false
 	}
+	d.logResponses(cmd)
 	event := map[bool]string{true: "updated", false: "added"}[oldEP != nil]
 	d.logger.InfoV(2, "%s endpoint '%s' weight '%d' state '%s' on backend/server '%s/%s'",
 		event, curEP.Target, curEP.Weight, state, backname, curEP.Name)
 	return true
 }
 
-func (d *dynUpdater) execCommand(observer func(duration time.Duration), cmd []string) ([]string, error) {
-	msg, err := d.socket.Send(observer, cmd...)
-	d.cmdCnt = d.cmdCnt + len(cmd)
-	return msg, err
+func (d *dynUpdater) execAddEndpoint(backend *hatypes.Backend, ep *hatypes.Endpoint) bool {
+	state := map[bool]string{true: "ready", false: "drain"}[ep.Weight > 0]
+	server := fmt.Sprintf("%s/%s", backend.ID, ep.Name)
+	// options are built by the same template of the servers declared in the configuration file
+	options, err := d.serverTmpl.ExecuteTemplate("server", map[string]interface{}{"p1": backend, "p2": ep})
+	if err != nil {
+		d.logger.Error("error building options of server %s: %v", server, err)
+		return false
+	}
+	cmd := []*socket.Cmd{
+		socket.AddServerCmd(backend.ID, ep.Name, fmt.Sprintf("%s:%d", ep.IP, ep.Port), strings.TrimSpace(options)),
+	}
+	if hasHealthCheck(backend.HealthCheck) {
+		cmd = append(cmd, socket.EnableHealthCmd(backend.ID, ep.Name))
+	}
+	if backend.AgentCheck.Port > 0 {
+		cmd = append(cmd, socket.EnableAgentCmd(backend.ID, ep.Name))
+	}
+	// dynamic servers start in maintenance mode
+	cmd = append(cmd, socket.SetServerCmd(backend.ID, ep.Name, "state", state))
+	failed, err := d.execCmds(d.metrics.HAProxySetServerResponseTime, cmd...)
+	if err != nil {
+		d.logger.Error("error adding server %s: %v", server, err)
+		return false
+	}
+	if failed != nil {
+		d.logger.Warn("unrecognized response adding server %s: %s", server, failed.Response)
+		return false
+	}
+	d.logResponses(cmd[1:])
+	d.logger.InfoV(2, "added endpoint '%s' weight '%d' state '%s' on new backend/server '%s'",
+		ep.Target, ep.Weight, state, server)
+	return true
+}
+
+func (d *dynUpdater) execDelEndpoint(backname string, ep *hatypes.Endpoint) bool {
+	server := fmt.Sprintf("%s/%s", backname, ep.Name)
+	cmd := socket.DelServerCmd(backname, ep.Name)
+	if _, err := d.execCmds(d.metrics.HAProxySetServerResponseTime, cmd); err != nil {
+		d.logger.Error("error removing server %s: %v", server, err)
+		return false
+	}
+	if cmd.Err != nil {
+		// eg server has active connections, keeping it as an empty slot
+		d.logger.InfoV(2, "server %s was not removed, keeping it as an empty slot: %s", server, cmd.Response)
+		return false
+	}
+	d.logger.InfoV(2, "removed backend/server '%s'", server)
+	return true
+}
+
+func hasHealthCheck(hc hatypes.HealthCheck) bool {
+	return hc.Port > 0 || hc.Addr != "" || hc.Interval != "" || hc.RiseCount > 0 || hc.FallCount > 0
 }
 
-func cmdResponseOK(cmd, response string) bool {
-	switch cmd {
-	case "set server":
-		return response == "" || strings.HasPrefix(response, "IP changed from ") || strings.HasPrefix(response, "no need to change ")
-	case "commit ssl cert":
-		return strings.Contains(response, "Success")
-	default:
-		panic(fmt.Errorf("invalid cmd: %s", cmd))
+// execCmds sends cmds to the runtime API using the same connection. An error is
+// returned if the commands could not be sent, otherwise failed is the first command
+// that haproxy refused, or responded with an unrecognized message, if any.
+func (d *dynUpdater) execCmds(observer func(duration time.Duration), cmds ...*socket.Cmd) (failed *socket.Cmd, err error) {
+	d.cmdCnt = d.cmdCnt + len(cmds)
+	err = socket.NewClient(d.socket, observer).Pipeline(cmds...)
+	for _, cmd := range cmds {
+		var cmdErr *socket.CommandError
+		if errors.As(cmd.Err, &cmdErr) {
+			return cmd, nil
+		}
 	}
+	return nil, err
+}
+
+// logResponses logs the non empty responses of successful commands, eg the
+// changes made by a `set server` command.
+func (d *dynUpdater) logResponses(cmds []*socket.Cmd) {
+	for _, cmd := range cmds {
+		if cmd.Response != "" {
+			d.logger.InfoV(2, "response from server: %s", oneLine(cmd.Response))
+		}
+	}
+}
+
+// oneLine formats a multi line response in a single line
+func oneLine(response string) string {
+	return strings.ReplaceAll(strings.TrimRight(response, "\n"), "\n", " \\\\ ")
 }
@@ -18,22 +18,28 @@ package haproxy
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
 )
 
 func TestDynUpdate(t *testing.T) {
 	testCases := map[string]struct {
-		doconfig1 func(c *testConfig)
-		doconfig2 func(c *testConfig)
-		expected  []string
-		dynamic   bool
-		cmd       string
-		cmdOutput []string
-		logging   string
+		doconfig1  func(c *testConfig)
+		doconfig2  func(c *testConfig)
+		expected   []string
+		dynamic    bool
+		dynServers bool
+		sslFileUpd bool
+		cmd        string
+		cmdOutput  []string
+		cmdOutputs [][]string
+		logging    string
 	}{
 		"test01": {
 			dynamic: true,
@@ -129,8 +135,8 @@ set server default_app_8080/srv001 weight 1`,
 				b.AcquireEndpoint("172.17.0.3", 8080, "")
 			},
 			expected: []string{
-				"srv001:172.17.0.2:8080:1",
-				"srv002:172.17.0.3:8080:1",
+				"srv002:172.17.0.2:8080:1",
+				"srv001:172.17.0.3:8080:1",
 				"srv003:127.0.0.1:1023:1",
 				"srv004:127.0.0.1:1023:1",
 				"srv005:127.0.0.1:1023:1",
@@ -351,8 +357,14 @@ INFO-V(2) disabled endpoint '172.17.0.4:8080' on backend/server 'default_app_808
 				"srv007:127.0.0.1:1023:1",
 			},
 			dynamic: false,
+			cmd: `
+set server default_app_8080/srv002 addr 172.17.0.3 port 8080
+set server default_app_8080/srv002 state ready
+set server default_app_8080/srv002 weight 1
+`,
 			logging: `
 INFO-V(2) added endpoints on backend 'default_app_8080'
+INFO-V(2) added endpoint '172.17.0.3:8080' weight '1' state 'ready' on backend/server 'default_app_8080/srv002'
 INFO-V(2) need to reload due to config changes: [backends]`,
 		},
 		"test14": {
@@ -872,22 +884,39 @@ INFO certificate updated for domain2.local
 		},
 		"test32": {
 			doconfig1: func(c *testConfig) {
-				f := c.httpFrontend(80)
+				c.config.frontends.DefaultCrtFile = "/tmp/default.pem"
+				f := c.httpsFrontend(443)
 				h1 := f.AcquireHost("domain1.local")
 				h1.TLS.TLSFilename = "/tmp/domain1.pem"
 				h1.TLS.TLSHash = "1"
 			},
 			doconfig2: func(c *testConfig) {
-				f := c.httpFrontend(80)
+				f := c.httpsFrontend(443)
 				h1 := f.AcquireHost("domain1.local")
 				h1.TLS.TLSFilename = "/tmp/domain2.pem"
 				h1.TLS.TLSHash = "2"
 			},
-			dynamic: false,
-			logging: `
-INFO-V(2) diff outside server certificate of host 'domain1.local'
-INFO-V(2) need to reload due to config changes: [hosts (_front_http)]
+			dynamic: true,
+			cmd: `
+del ssl crt-list <maps>/_front_https_bind_crt.list /tmp/domain1.pem
+del ssl cert /tmp/domain1.pem
+new ssl cert /tmp/domain2.pem
+set ssl cert /tmp/domain2.pem <<
+<content>
+
+commit ssl cert /tmp/domain2.pem
+add ssl crt-list <maps>/_front_https_bind_crt.list <<
+/tmp/domain2.pem domain1.local
 `,
+			cmdOutput: []string{
+				"Entry '/tmp/domain1.pem' deleted in crtlist '<maps>/_front_https_bind_crt.list'!\n",
+				"Certificate '/tmp/domain1.pem' deleted!\n",
+				"New empty certificate store '/tmp/domain2.pem'!\n",
+				"Transaction created for certificate /tmp/domain2.pem!\n",
+				"Committing /tmp/domain2.pem.\nSuccess!\n",
+				"Inserting certificate '/tmp/domain2.pem' in crt-list '<maps>/_front_https_bind_crt.list'.\nSuccess!\n",
+			},
+			logging: `INFO-V(2) updated crt list of frontend '_front_https': 6 command(s)`,
 		},
 		"test33": {
 			doconfig1: func(c *testConfig) {
@@ -962,7 +991,8 @@ INFO-V(2) need to reload due to config changes: [hosts (_front_http)]
 				h1 := f.AcquireHost("domain1.local")
 				h1.TLS.TLSFilename = "/tmp/domain1.pem"
 				h1.TLS.TLSHash = "1"
-				f.AcquireHost("domain2.local")
+				h2 := f.AcquireHost("domain2.local")
+				h2.SSLPassthrough = true
 			},
 			doconfig2: func(c *testConfig) {
 				f := c.httpFrontend(80)
@@ -976,68 +1006,967 @@ INFO-V(2) removed host 'domain2.local'
 INFO-V(2) need to reload due to config changes: [hosts (_front_http)]
 `,
 		},
-	}
-	readFile = func(_ string) ([]byte, error) {
-		return []byte("<content>"), nil
-	}
-	for name, test := range testCases {
-		t.Run(name, func(t *testing.T) {
-			c := setup(t)
-			f := c.httpFrontend(80)
-			if test.doconfig1 != nil {
-				test.doconfig1(c)
-			}
-			c.instance.config.Commit()
-			hostnames := []string{}
-			for hostname := range f.Hosts() {
-				hostnames = append(hostnames, hostname)
-			}
-			f.RemoveAllHosts(hostnames)
-			backendIDs := []string{}
-			for _, backend := range c.config.Backends().Items() {
-				backendIDs = append(backendIDs, backend.ID)
-			}
-			c.config.Backends().RemoveAll(backendIDs)
-			if test.doconfig2 != nil {
-				test.doconfig2(c)
-			}
-			clientMock := &clientMock{
-				cmdOutput: test.cmdOutput,
-			}
-			dynUpdater := c.instance.newDynUpdater()
-			dynUpdater.socket = clientMock
-			dynamic := dynUpdater.update()
-			var actual []string
-			for _, ep := range c.config.Backends().AcquireBackend("default", "app", "8080").Endpoints {
-				actual = append(actual, fmt.Sprintf("%s:%s:%d:%d", ep.Name, ep.IP, ep.Port, ep.Weight))
-			}
-			assert.Equal(t, test.expected, actual, "endpoints differ")
-			assert.Equal(t, test.dynamic, dynamic, "dynamic differ")
-			cmd := strings.TrimSpace(clientMock.cmd)
-			test.cmd = strings.TrimSpace(test.cmd)
-			assert.Equal(t, test.cmd, cmd, "cmd differ")
-			c.logger.CompareLogging(test.logging)
-			c.teardown()
-		})
-	}
-}
-
-type clientMock struct {
-	cmd       string
-	cmdOutput []string
-}
-
-func (cli *clientMock) Address() string {
-	return ""
-}
-
-func (cli *clientMock) HasConn() bool {
-	return true
-}
-
-func (cli *clientMock) Send(observer func(duration time.Duration), command ...string) ([]string, error) {
-	for _, c := range command {
-		cli.cmd = cli.cmd + c + "\n"
+		"test36": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv002:172.17.0.3:8080:1",
+			},
+			dynamic:    true,
+			dynServers: true,
+			cmd: `
+add server default_app_8080/srv002 172.17.0.3:8080 weight 1
+set server default_app_8080/srv002 state ready`,
+			cmdOutputs: [][]string{{"New server registered.", ""}},
+			logging: `
+INFO-V(2) added endpoint '172.17.0.3:8080' weight '1' state 'ready' on new backend/server 'default_app_8080/srv002'`,
+		},
+		"test37": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Cookie.Name = "serverId"
+				b.Server.MaxConn = 10
+				b.HealthCheck.Interval = "2s"
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+				b.AddEmptyEndpoint()
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.Cookie.Name = "serverId"
+				b.Server.MaxConn = 10
+				b.HealthCheck.Interval = "2s"
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+				b.AcquireEndpoint("172.17.0.4", 8080, "")
+				b.AcquireEndpoint("172.17.0.5", 8080, "")
+				ep := b.AcquireEndpoint("172.17.0.6", 8080, "")
+				ep.CookieValue = "ep6"
+				ep.PUID = 606
+				ep.Weight = 0
+			},
+			expected: []string{
+				"srv002:172.17.0.3:8080:1",
+				"srv001:172.17.0.4:8080:1",
+				"srv003:172.17.0.5:8080:1",
+				"srv004:172.17.0.6:8080:0",
+			},
+			dynamic:    true,
+			dynServers: true,
+			cmd: `
+set server default_app_8080/srv001 addr 172.17.0.4 port 8080
+set server default_app_8080/srv001 state ready
+set server default_app_8080/srv001 weight 1
+set server default_app_8080/srv003 addr 172.17.0.5 port 8080
+set server default_app_8080/srv003 state ready
+set server default_app_8080/srv003 weight 1
+add server default_app_8080/srv004 172.17.0.6:8080 weight 0 cookie ep6 id 606 maxconn 10 check inter 2s
+enable health default_app_8080/srv004
+set server default_app_8080/srv004 state drain`,
+			cmdOutputs: [][]string{{"", "", ""}, {"", "", ""}, {"New server registered.", "", ""}},
+			logging: `
+INFO-V(2) updated endpoint '172.17.0.4:8080' weight '1' state 'ready' on backend/server 'default_app_8080/srv001'
+INFO-V(2) added endpoint '172.17.0.5:8080' weight '1' state 'ready' on backend/server 'default_app_8080/srv003'
+INFO-V(2) added endpoint '172.17.0.6:8080' weight '0' state 'drain' on new backend/server 'default_app_8080/srv004'`,
+		},
+		"test38": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv002:172.17.0.3:8080:1",
+			},
+			dynamic:    false,
+			dynServers: true,
+			cmd: `
+add server default_app_8080/srv002 172.17.0.3:8080 weight 1
+set server default_app_8080/srv002 state ready`,
+			cmdOutputs: [][]string{{"Backend must use a dynamic load balancing to support dynamic servers.", ""}},
+			logging: `
+WARN unrecognized response adding server default_app_8080/srv002: Backend must use a dynamic load balancing to support dynamic servers.
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test39": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+				b.AcquireEndpoint("172.17.0.4", 8080, "")
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv003:127.0.0.1:1023:1",
+			},
+			dynamic:    true,
+			dynServers: true,
+			cmd: `
+set server default_app_8080/srv002 state maint
+set server default_app_8080/srv002 addr 127.0.0.1 port 1023
+set server default_app_8080/srv002 weight 0
+del server default_app_8080/srv002
+set server default_app_8080/srv003 state maint
+set server default_app_8080/srv003 addr 127.0.0.1 port 1023
+set server default_app_8080/srv003 weight 0
+del server default_app_8080/srv003`,
+			cmdOutputs: [][]string{
+				{"", "", ""},
+				{"Server deleted."},
+				{"", "", ""},
+				{"Server still has connections attached to it, cannot remove it."},
+			},
+			logging: `
+INFO-V(2) disabled endpoint '172.17.0.3:8080' on backend/server 'default_app_8080/srv002'
+INFO-V(2) removed backend/server 'default_app_8080/srv002'
+INFO-V(2) disabled endpoint '172.17.0.4:8080' on backend/server 'default_app_8080/srv003'
+INFO-V(2) server default_app_8080/srv003 was not removed, keeping it as an empty slot: Server still has connections attached to it, cannot remove it.`,
+		},
+		"test40": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+			},
+			doconfig2: func(c *testConfig) {
+				c.config.Global().MaxConn = 1
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.Dynamic.MinFreeSlots = 4
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:    false,
+			dynServers: true,
+			logging:    `INFO-V(2) need to reload due to config changes: [global]`,
+		},
+		"test41": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain2.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:   true,
+			cmd:       `add map <maps>/_front_http_host__begin.map domain2.local#/ default_app_8080`,
+			cmdOutput: []string{""},
+			logging:   `INFO-V(2) updated maps of frontend '_front_http': 1 command(s)`,
+		},
+		"test42": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain2.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:   true,
+			cmd:       `del map <maps>/_front_http_host__begin.map domain2.local#/`,
+			cmdOutput: []string{""},
+			logging:   `INFO-V(2) updated maps of frontend '_front_http': 1 command(s)`,
+		},
+		"test43": {
+			doconfig1: func(c *testConfig) {
+				b1 := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b1.AcquireEndpoint("172.17.0.2", 8080, "")
+				b2 := c.config.Backends().AcquireBackend("default", "web", "8080")
+				b2.AcquireEndpoint("172.17.0.3", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b1, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain2.local").AddPath(b1, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain3.local").AddPath(b2, "/", hatypes.MatchBegin)
+			},
+			doconfig2: func(c *testConfig) {
+				b1 := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b1.AcquireEndpoint("172.17.0.2", 8080, "")
+				b2 := c.config.Backends().AcquireBackend("default", "web", "8080")
+				b2.AcquireEndpoint("172.17.0.3", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b1, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain2.local").AddPath(b2, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain3.local").AddPath(b2, "/", hatypes.MatchBegin)
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:   true,
+			cmd:       `set map <maps>/_front_http_host__begin.map domain2.local#/ default_web_8080`,
+			cmdOutput: []string{""},
+			logging:   `INFO-V(2) updated maps of frontend '_front_http': 1 command(s)`,
+		},
+		"test44": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			doconfig2: func(c *testConfig) {
+				b1 := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b1.AcquireEndpoint("172.17.0.2", 8080, "")
+				b2 := c.config.Backends().AcquireBackend("default", "web", "8080")
+				b2.AcquireEndpoint("172.17.0.3", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b1, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain2.local").AddPath(b2, "/", hatypes.MatchBegin)
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:   false,
+			cmd:       `add map <maps>/_front_http_host__begin.map domain2.local#/ default_web_8080`,
+			cmdOutput: []string{""},
+			logging: `
+INFO-V(2) updated maps of frontend '_front_http': 1 command(s)
+INFO-V(2) added backend 'default_web_8080'
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test45": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				h := f.AcquireHost("domain1.local")
+				h.AddPath(b, "/", hatypes.MatchBegin)
+				h.AddPath(b, "/api", hatypes.MatchBegin)
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) cannot dynamically update map file '<maps>/_front_http_host__begin.map'
+INFO-V(2) need to reload due to config changes: [hosts (_front_http)]`,
+		},
+		"test46": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+				f.AcquireHost("domain2.local").AddPath(b, "/", hatypes.MatchBegin).SSLRedirect = true
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:   false,
+			cmd:       `add map <maps>/_front_http_host__begin.map domain2.local#/ default_app_8080`,
+			cmdOutput: []string{""},
+			logging: `
+INFO-V(2) updated maps of frontend '_front_http': 1 command(s)
+INFO-V(2) diff outside endpoints of backend 'default_app_8080'
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test47": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpFrontend(80)
+				f.AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+				h := f.AcquireHost("domain2.local")
+				h.AddPath(b, "/", hatypes.MatchBegin)
+				h.RootRedirect = "/app"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) match files of frontend '_front_http' changed
+INFO-V(2) need to reload due to config changes: [hosts (_front_http)]`,
+		},
+		"test52": {
+			doconfig1: func(c *testConfig) {
+				f := c.httpFrontend(80)
+				for _, hostname := range []string{"domain1.local", "domain2.local"} {
+					h := f.AcquireHost(hostname)
+					h.TLS.CAFilename = "/tmp/ca.pem"
+					h.TLS.CAHash = "1"
+					h.TLS.CRLFilename = "/tmp/crl.pem"
+					h.TLS.CRLHash = "1"
+				}
+			},
+			doconfig2: func(c *testConfig) {
+				f := c.httpFrontend(80)
+				for _, hostname := range []string{"domain1.local", "domain2.local"} {
+					h := f.AcquireHost(hostname)
+					h.TLS.CAFilename = "/tmp/ca.pem"
+					h.TLS.CAHash = "1"
+					h.TLS.CRLFilename = "/tmp/crl.pem"
+					h.TLS.CRLHash = "2"
+				}
+			},
+			dynamic:    true,
+			sslFileUpd: true,
+			cmd: `
+set ssl crl-file /tmp/crl.pem <<
+<content>
+
+commit ssl crl-file /tmp/crl.pem
+`,
+			cmdOutput: []string{
+				"transaction created for CA /tmp/crl.pem!\n",
+				"Committing /tmp/crl.pem\nSuccess!\n",
+			},
+			logging: `INFO crl-file updated for domain1.local`,
+		},
+		"test53": {
+			doconfig1: func(c *testConfig) {
+				f := c.httpFrontend(80)
+				h := f.AcquireHost("domain1.local")
+				h.TLS.CAFilename = "/tmp/ca1.pem"
+				h.TLS.CAHash = "1"
+			},
+			doconfig2: func(c *testConfig) {
+				f := c.httpFrontend(80)
+				h := f.AcquireHost("domain1.local")
+				h.TLS.CAFilename = "/tmp/ca2.pem"
+				h.TLS.CAHash = "2"
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) diff outside server certificate of host 'domain1.local'
+INFO-V(2) need to reload due to config changes: [hosts (_front_http)]`,
+		},
+		"test54": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "1"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "2"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:    false,
+			sslFileUpd: true,
+			cmd: `
+set ssl ca-file /tmp/ca.pem <<
+<content>
+
+commit ssl ca-file /tmp/ca.pem
+`,
+			cmdOutput: []string{
+				"transaction created for CA /tmp/ca.pem!\n",
+				"Committing /tmp/ca.pem\nunable to commit: invalid CA\n",
+			},
+			logging: `
+WARN cannot update ca-file for default_app_8080: transaction created for CA /tmp/ca.pem! \\ Committing /tmp/ca.pem \\ unable to commit: invalid CA
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test55": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "1"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "2"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:    true,
+			sslFileUpd: true,
+			cmd: `
+set ssl ca-file /tmp/ca.pem <<
+<content>
+
+commit ssl ca-file /tmp/ca.pem
+`,
+			cmdOutput: []string{
+				"transaction created for CA /tmp/ca.pem!\n",
+				"Committing /tmp/ca.pem\nSuccess!\n",
+			},
+			logging: `INFO ca-file updated for default_app_8080`,
+		},
+		"test48": {
+			doconfig1: func(c *testConfig) {
+				c.config.frontends.DefaultCrtFile = "/tmp/default.pem"
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpsFrontend(443)
+				h1 := f.AcquireHost("domain1.local")
+				h1.AddPath(b, "/", hatypes.MatchBegin)
+				h1.TLS.TLSFilename = "/tmp/domain1.pem"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				f := c.httpsFrontend(443)
+				h1 := f.AcquireHost("domain1.local")
+				h1.AddPath(b, "/", hatypes.MatchBegin)
+				h1.TLS.TLSFilename = "/tmp/domain1.pem"
+				h2 := f.AcquireHost("domain2.local")
+				h2.AddPath(b, "/", hatypes.MatchBegin)
+				h2.TLS.TLSFilename = "/tmp/domain2.pem"
+				h2.TLS.TLSHash = "2"
+				h3 := f.AcquireHost("domain3.local")
+				h3.AddPath(b, "/", hatypes.MatchBegin)
+				h3.TLS.TLSFilename = "/tmp/domain1.pem"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: true,
+			cmd: `
+new ssl cert /tmp/domain2.pem
+set ssl cert /tmp/domain2.pem <<
+<content>
+
+commit ssl cert /tmp/domain2.pem
+add ssl crt-list <maps>/_front_https_bind_crt.list <<
+/tmp/domain2.pem domain2.local
+
+add ssl crt-list <maps>/_front_https_bind_crt.list <<
+/tmp/domain1.pem domain3.local
+
+add map <maps>/_front_https_host__begin.map domain2.local#/ default_app_8080
+add map <maps>/_front_https_host__begin.map domain3.local#/ default_app_8080
+`,
+			cmdOutputs: [][]string{
+				{
+					"New empty certificate store '/tmp/domain2.pem'!\n",
+					"Transaction created for certificate /tmp/domain2.pem!\n",
+					"Committing /tmp/domain2.pem.\nSuccess!\n",
+					"Inserting certificate '/tmp/domain2.pem' in crt-list '<maps>/_front_https_bind_crt.list'.\nSuccess!\n",
+					"Inserting certificate '/tmp/domain1.pem' in crt-list '<maps>/_front_https_bind_crt.list'.\nSuccess!\n",
+				},
+				{"", ""},
+			},
+			logging: `
+INFO-V(2) updated crt list of frontend '_front_https': 5 command(s)
+INFO-V(2) updated maps of frontend '_front_https': 2 command(s)`,
+		},
+		"test49": {
+			doconfig1: func(c *testConfig) {
+				c.config.frontends.DefaultCrtFile = "/tmp/default.pem"
+				f := c.httpsFrontend(443)
+				f.AcquireHost("domain1.local").TLS.TLSFilename = "/tmp/domain1.pem"
+			},
+			doconfig2: func(c *testConfig) {
+				f := c.httpsFrontend(443)
+				f.AcquireHost("domain1.local").TLS.TLSFilename = "/tmp/domain1.pem"
+				h2 := f.AcquireHost("domain2.local")
+				h2.TLS.TLSFilename = "/tmp/domain2.pem"
+				h2.TLS.CAFilename = "/tmp/ca.pem"
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) added host 'domain2.local'
+INFO-V(2) need to reload due to config changes: [hosts (_front_https)]`,
+		},
+		"test50": {
+			doconfig1: func(c *testConfig) {
+				c.config.frontends.DefaultCrtFile = "/tmp/default.pem"
+				f := c.httpsFrontend(443)
+				f.AcquireHost("domain1.local").TLS.TLSFilename = "/tmp/domain1.pem"
+				f.AcquireHost("domain2.local").TLS.TLSFilename = "/tmp/domain1.pem"
+			},
+			doconfig2: func(c *testConfig) {
+				f := c.httpsFrontend(443)
+				f.AcquireHost("domain1.local").TLS.TLSFilename = "/tmp/domain1.pem"
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) cannot remove certificate '/tmp/domain1.pem' from the crt list of frontend '_front_https': used more than once
+INFO-V(2) need to reload due to config changes: [hosts (_front_https)]`,
+		},
+		"test51": {
+			doconfig1: func(c *testConfig) {
+				c.config.frontends.DefaultCrtFile = "/tmp/default.pem"
+				f := c.httpsFrontend(443)
+				f.AcquireHost("domain1.local").TLS.TLSFilename = "/tmp/domain1.pem"
+			},
+			doconfig2: func(c *testConfig) {
+				f := c.httpsFrontend(443)
+				f.AcquireHost("domain1.local").TLS.TLSFilename = "/tmp/domain1.pem"
+				f.AcquireHost("domain2.local").TLS.TLSFilename = "/tmp/domain2.pem"
+			},
+			dynamic: false,
+			cmd: `
+new ssl cert /tmp/domain2.pem
+set ssl cert /tmp/domain2.pem <<
+<content>
+
+commit ssl cert /tmp/domain2.pem
+add ssl crt-list <maps>/_front_https_bind_crt.list <<
+/tmp/domain2.pem domain2.local
+`,
+			cmdOutput: []string{
+				"New empty certificate store '/tmp/domain2.pem'!\n",
+				"unable to load the certificate\n",
+			},
+			logging: `
+WARN unrecognized response updating crt list of frontend '_front_https' with 'set ssl cert /tmp/domain2.pem <<': unable to load the certificate
+INFO-V(2) need to reload due to config changes: [hosts (_front_https)]`,
+		},
+		"test56": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AddEmptyEndpoint()
+				b.Server.MaxConn = 100
+				b.HealthCheck.Port = 8081
+				b.HealthCheck.Interval = "2s"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.MaxConn = 50
+				b.HealthCheck.Port = 8082
+				b.HealthCheck.Addr = "10.0.0.1"
+				b.HealthCheck.Interval = "2s"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv002:127.0.0.1:1023:1",
+			},
+			dynamic: true,
+			cmd: `
+set maxconn server default_app_8080/srv001 50
+set maxconn server default_app_8080/srv002 50
+set server default_app_8080/srv001 check-port 8082
+set server default_app_8080/srv002 check-port 8082
+set server default_app_8080/srv001 check-addr 10.0.0.1
+set server default_app_8080/srv002 check-addr 10.0.0.1
+`,
+			cmdOutput: []string{
+				"",
+				"",
+				"health check port updated.\n",
+				"health check port updated.\n",
+				"health check addr updated.\n",
+				"health check addr updated.\n",
+			},
+			logging: `INFO updated [maxconn-server health-check-port health-check-addr] of backend 'default_app_8080' via runtime API`,
+		},
+		"test57": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "").Weight = 10
+				b.Server.InitialWeight = 10
+				b.AgentCheck.Port = 8090
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "").Weight = 20
+				b.Server.InitialWeight = 20
+				b.AgentCheck.Port = 8090
+				b.AgentCheck.Addr = "10.0.0.1"
+				b.AgentCheck.Send = "hello"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:20",
+			},
+			dynamic: true,
+			cmd: `
+set server default_app_8080/srv001 agent-addr 10.0.0.1
+set server default_app_8080/srv001 agent-send hello
+set server default_app_8080/srv001 addr 172.17.0.2 port 8080
+set server default_app_8080/srv001 state ready
+set server default_app_8080/srv001 weight 20
+`,
+			logging: `
+INFO updated [initial-weight agent-check-addr agent-check-send] of backend 'default_app_8080' via runtime API
+INFO-V(2) updated endpoint '172.17.0.2:8080' weight '20' state 'ready' on backend/server 'default_app_8080/srv001'`,
+		},
+		"test58": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.MaxConn = 100
+				b.HealthCheck.Interval = "2s"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.MaxConn = 50
+				b.HealthCheck.Interval = "5s"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			cmd: `
+set maxconn server default_app_8080/srv001 50
+`,
+			logging: `
+INFO-V(2) diff outside endpoints of backend 'default_app_8080'
+INFO updated [maxconn-server] of backend 'default_app_8080' via runtime API
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test59": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.MaxConn = 100
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.MaxConn = 50
+				b.HealthCheck.Port = 8081
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			cmd: `
+set maxconn server default_app_8080/srv001 50
+`,
+			cmdOutput: []string{
+				"No such server.\n",
+			},
+			logging: `
+INFO-V(2) diff outside endpoints of backend 'default_app_8080'
+WARN unrecognized response updating servers of backend 'default_app_8080': No such server.
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test60": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.MaxConn = 100
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.MaxConn = 50
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			cmd:     ``,
+			logging: `
+INFO-V(2) diff outside endpoints of backend 'default_app_8080'
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test61": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+				b.AcquireEndpoint("172.17.0.4", 8080, "")
+				b.AcquireEndpoint("172.17.0.5", 8080, "")
+			},
+			expected: []string{
+				"srv002:172.17.0.3:8080:1",
+				"srv001:172.17.0.4:8080:1",
+				"srv003:172.17.0.5:8080:1",
+			},
+			dynamic: false,
+			cmd: `
+set server default_app_8080/srv001 addr 172.17.0.4 port 8080
+set server default_app_8080/srv001 state ready
+set server default_app_8080/srv001 weight 1
+`,
+			logging: `
+INFO-V(2) added endpoints on backend 'default_app_8080'
+INFO-V(2) updated endpoint '172.17.0.4:8080' weight '1' state 'ready' on backend/server 'default_app_8080/srv001'
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test62": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				p := c.httpFrontend(80).AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+				p.AuthJWT.Verifiers = []hatypes.AuthJWTVerifier{{Algorithm: "RS256", KeyFile: "/tmp/jwt_0.pem", KeyHash: "1"}}
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				p := c.httpFrontend(80).AcquireHost("domain1.local").AddPath(b, "/", hatypes.MatchBegin)
+				p.AuthJWT.Verifiers = []hatypes.AuthJWTVerifier{{Algorithm: "RS256", KeyFile: "/tmp/jwt_0.pem", KeyHash: "2"}}
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) diff outside endpoints of backend 'default_app_8080'
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test63": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.SourceIPs = []net.IP{net.ParseIP("192.168.0.1")}
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.AcquireEndpoint("172.17.0.3", 8080, "")
+				b.AcquireEndpoint("172.17.0.4", 8080, "")
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.SourceIPs = []net.IP{net.ParseIP("192.168.0.1")}
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+				"srv002:127.0.0.1:1023:1",
+				"srv003:127.0.0.1:1023:1",
+			},
+			dynamic:    true,
+			dynServers: true,
+			cmd: `
+set server default_app_8080/srv002 state maint
+set server default_app_8080/srv002 addr 127.0.0.1 port 1023
+set server default_app_8080/srv002 weight 0
+set server default_app_8080/srv003 state maint
+set server default_app_8080/srv003 addr 127.0.0.1 port 1023
+set server default_app_8080/srv003 weight 0`,
+			cmdOutputs: [][]string{
+				{"", "", ""},
+				{"", "", ""},
+			},
+			logging: `
+INFO-V(2) disabled endpoint '172.17.0.3:8080' on backend/server 'default_app_8080/srv002'
+INFO-V(2) disabled endpoint '172.17.0.4:8080' on backend/server 'default_app_8080/srv003'`,
+		},
+		"test64": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.HealthCheck.Port = 8081
+				b.HealthCheck.Interval = "2s"
+				b.AgentCheck.Port = 8090
+				b.AgentCheck.Interval = "2s"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.Dynamic.DynUpdate = true
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.HealthCheck.Port = 8081
+				b.HealthCheck.Interval = "5s"
+				b.AgentCheck.Port = 8090
+				b.AgentCheck.Interval = "5s"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) diff outside endpoints of backend 'default_app_8080'
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test65": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "1"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "2"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic: false,
+			logging: `
+INFO-V(2) ca-file of default_app_8080 changed and haproxy does not support updating it
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+		"test66": {
+			doconfig1: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "1"
+			},
+			doconfig2: func(c *testConfig) {
+				b := c.config.Backends().AcquireBackend("default", "app", "8080")
+				b.AcquireEndpoint("172.17.0.2", 8080, "")
+				b.Server.CAFilename = "/tmp/ca.pem"
+				b.Server.CAHash = "2"
+			},
+			expected: []string{
+				"srv001:172.17.0.2:8080:1",
+			},
+			dynamic:    false,
+			sslFileUpd: true,
+			cmd: `
+set ssl ca-file /tmp/ca.pem <<
+<content>
+
+commit ssl ca-file /tmp/ca.pem
+`,
+			cmdOutput: []string{
+				"transaction created for CA /tmp/ca.pem!\n",
+			},
+			logging: `
+ERROR error updating ca-file for default_app_8080: missing response of 'commit ssl ca-file /tmp/ca.pem'
+INFO-V(2) need to reload due to config changes: [backends]`,
+		},
+	}
+	readFile = func(_ string) ([]byte, error) {
+		return []byte("<content>"), nil
+	}
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := setup(t)
+			c.httpFrontend(80)
+			if test.doconfig1 != nil {
+				test.doconfig1(c)
+			}
+			if err := c.instance.config.WriteFrontendsMaps(); err != nil {
+				t.Errorf("error writing frontend maps: %v", err)
+			}
+			c.instance.config.Commit()
+			for _, f := range c.config.frontends.Items() {
+				hostnames := []string{}
+				for hostname := range f.Hosts() {
+					hostnames = append(hostnames, hostname)
+				}
+				f.RemoveAllHosts(hostnames)
+			}
+			backendIDs := []string{}
+			for _, backend := range c.config.Backends().Items() {
+				backendIDs = append(backendIDs, backend.ID)
+			}
+			c.config.Backends().RemoveAll(backendIDs)
+			if test.doconfig2 != nil {
+				test.doconfig2(c)
+			}
+			if err := c.instance.config.WriteFrontendsMaps(); err != nil {
+				t.Errorf("error writing frontend maps: %v", err)
+			}
+			for i := range test.cmdOutput {
+				test.cmdOutput[i] = strings.ReplaceAll(test.cmdOutput[i], "<maps>", c.tempdir)
+			}
+			clientMock := &clientMock{
+				cmdOutput:  test.cmdOutput,
+				cmdOutputs: test.cmdOutputs,
+			}
+			dynUpdater := c.instance.newDynUpdater()
+			dynUpdater.socket = clientMock
+			dynUpdater.dynServers = func() bool { return test.dynServers }
+			dynUpdater.sslFileUpd = func() bool { return test.sslFileUpd }
+			dynamic := dynUpdater.update()
+			var actual []string
+			for _, ep := range c.config.Backends().AcquireBackend("default", "app", "8080").Endpoints {
+				actual = append(actual, fmt.Sprintf("%s:%s:%d:%d", ep.Name, ep.IP, ep.Port, ep.Weight))
+			}
+			assert.Equal(t, test.expected, actual, "endpoints differ")
+			assert.Equal(t, test.dynamic, dynamic, "dynamic differ")
+			cmd := strings.TrimSpace(clientMock.cmd)
+			test.cmd = strings.ReplaceAll(strings.TrimSpace(test.cmd), "<maps>", c.tempdir)
+			assert.Equal(t, test.cmd, cmd, "cmd differ")
+			c.logger.CompareLogging(strings.ReplaceAll(test.logging, "<maps>", c.tempdir))
+			c.teardown()
+		})
+	}
+}
+
+type clientMock struct {
+	cmd        string
+	cmdOutput  []string
+	cmdOutputs [][]string
+}
+
+func (cli *clientMock) Address() string {
+	return ""
+}
+
+func (cli *clientMock) HasConn() bool {
+	return true
+}
+
+func (cli *clientMock) Send(observer func(duration time.Duration), command ...string) ([]string, error) {
+	for _, c := range command {
+		cli.cmd = cli.cmd + c + "\n"
+	}
+	if len(cli.cmdOutputs) > 0 {
+		// one output per Send() call
+		out := cli.cmdOutputs[0]
+		cli.cmdOutputs = cli.cmdOutputs[1:]
+		return out, nil
+	}
+	if cli.cmdOutput == nil {
+		// empty responses, the usual output of a successful command
+		return make([]string, len(command)), nil
 	}
 	return cli.cmdOutput, nil
 }
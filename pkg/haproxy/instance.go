@@ -22,7 +22,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -48,6 +47,7 @@ type InstanceOptions struct {
 	RootFSPrefix      string
 	LocalFSPrefix     string
 	BackendShards     int
+	ConfigRolledBack  func(hosts, backends []string)
 	HAProxyCfgDir     string
 	HAProxyMapsDir    string
 	IsMasterWorker    bool
@@ -60,12 +60,15 @@ type InstanceOptions struct {
 	Metrics           types.Metrics
 	ReloadQueue       *workqueue.WorkQueue[any]
 	ReloadStrategy    string
+	SnippetRejected   func(source *hatypes.SnippetSource, reason string)
 	SortEndpointsBy   string
 	StopCtx           context.Context
 	TrackInstances    bool
 	ValidateConfig    bool
 	// TODO Fake is used to skip real haproxy calls. Use a mock instead.
 	fake bool
+	// fakeReloadErrs are returned, in order, by the skipped reloads of a fake instance
+	fakeReloadErrs []error
 }
 
 // Instance ...
@@ -73,6 +76,7 @@ type Instance interface {
 	AcmeCheck(source string) (int, error)
 	ParseTemplates() error
 	Config() Config
+	HAProxyVersion() hatypes.HAProxyVersion
 	CalcIdleMetric()
 	AcmeUpdate()
 	HAProxyUpdate(timer *utils.Timer) error
@@ -93,6 +97,7 @@ func CreateInstance(logger types.Logger, options InstanceOptions) Instance {
 		mapsTmpl:        template.CreateConfig(),
 		crtlistTmpl:     template.CreateConfig(),
 		modsecTmpl:      template.CreateConfig(),
+		mirrorTmpl:      template.CreateConfig(),
 		haResponseTmpl:  template.CreateConfig(),
 		luaResponseTmpl: template.CreateConfig(),
 		luaPeersTmpl:    template.CreateConfig(),
@@ -109,11 +114,24 @@ type instance struct {
 	config      Config
 	conns       *connections
 	metrics     types.Metrics
+	// version of haproxy, nil if not detected yet, see version.go
+	version      *hatypes.HAProxyVersion
+	versionMutex sync.Mutex
+	// reloadPending is true if a reload was enqueued and not successfully applied yet
+	reloadPending bool
+	// last known good configuration state, see rollback.go
+	hasLastKnownGood bool
+	hasSnapshot      bool
+	rolledBack       bool
+	rewritePending   bool
+	changedHosts     map[string]bool
+	changedBackends  map[string]bool
 	//
 	haproxyTmpl     *template.Config
 	mapsTmpl        *template.Config
 	crtlistTmpl     *template.Config
 	modsecTmpl      *template.Config
+	mirrorTmpl      *template.Config
 	haResponseTmpl  *template.Config
 	luaResponseTmpl *template.Config
 	luaPeersTmpl    *template.Config
@@ -172,6 +190,7 @@ func (i *instance) ParseTemplates() error {
 	i.mapsTmpl.ClearTemplates()
 	i.crtlistTmpl.ClearTemplates()
 	i.modsecTmpl.ClearTemplates()
+	i.mirrorTmpl.ClearTemplates()
 	i.haResponseTmpl.ClearTemplates()
 	i.luaResponseTmpl.ClearTemplates()
 	i.luaPeersTmpl.ClearTemplates()
@@ -185,6 +204,15 @@ func (i *instance) ParseTemplates() error {
 	); err != nil {
 		return err
 	}
+	if err := i.mirrorTmpl.NewTemplate(
+		"mirror.tmpl",
+		templatesDir+"/mirror/mirror.tmpl",
+		i.options.HAProxyCfgDir+"/spoe-mirror.conf",
+		0,
+		1024,
+	); err != nil {
+		return err
+	}
 	if err := i.haproxyTmpl.NewTemplate(
 		"haproxy.tmpl",
 		templatesDir+"/haproxy/haproxy.tmpl",
@@ -254,25 +282,23 @@ func (i *instance) Config() Config {
 	return i.config
 }
 
-var idleRegex = regexp.MustCompile(`Idle_pct: ([0-9]+)`)
-
 func (i *instance) CalcIdleMetric() {
 	if !i.up {
 		return
 	}
-	msg, err := i.conns.IdleChk().Send(i.metrics.HAProxyShowInfoResponseTime, "show info")
+	info, err := socket.NewClient(i.conns.IdleChk(), i.metrics.HAProxyShowInfoResponseTime).ShowInfo()
 	if err != nil {
 		i.logger.Error("error reading admin socket: %v", err)
 		return
 	}
-	idleStr := idleRegex.FindStringSubmatch(msg[0])
-	if len(idleStr) < 2 {
+	idleStr, found := info.Fields["Idle_pct"]
+	if !found {
 		i.logger.Error("cannot find Idle_pct field in the show info socket command")
 		return
 	}
-	idle, err := strconv.Atoi(idleStr[1])
+	idle, err := strconv.Atoi(idleStr)
 	if err != nil {
-		i.logger.Error("Idle_pct has an invalid integer: %s", idleStr[1])
+		i.logger.Error("Idle_pct has an invalid integer: %s", idleStr)
 	}
 	i.metrics.AddIdleFactor(idle)
 }
@@ -304,10 +330,19 @@ func (i *instance) HAProxyUpdate(timer *utils.Timer) error {
 	//   - dynUpdater might change config state, so it should be called before templates.Write()
 	//   - i.metrics.IncUpdate<Status>() should be called always, but only once
 	//   - i.updateSuccessful(<bool>) should be called only if haproxy is reloaded or cfg is validated
+	//   - dynamic changes are applied even if a reload is needed, so they are not delayed
+	//     by a reload that is rate limited, or deferred to the next reload interval
 	//
 	defer i.config.Commit()
 	i.config.SyncConfig()
 	i.config.Shrink()
+	i.config.PruneQuarantine()
+	if i.rewritePending {
+		i.config.Rewrite()
+	}
+	if i.options.ValidateConfig {
+		i.snapshotConfig()
+	}
 	if err := i.config.WriteTCPServicesMaps(); err != nil {
 		i.metrics.IncUpdateNoop()
 		return fmt.Errorf("error building tcp services maps: %w", err)
@@ -329,8 +364,15 @@ func (i *instance) HAProxyUpdate(timer *utils.Timer) error {
 		// TODO update tests and remove `if !fake` above
 		i.logChanged()
 	}
+	i.trackChanges()
 	updater := i.newDynUpdater()
 	updated := updater.update()
+	if updated && (i.rolledBack || i.rewritePending) {
+		// the running haproxy doesn't have the changes of the rolled back configuration
+		i.logger.InfoV(2, "need to reload due to a former rollback")
+		updater.alignSlots()
+		updated = false
+	}
 	if i.options.SortEndpointsBy != "random" {
 		i.config.Backends().SortChangedEndpoints(i.options.SortEndpointsBy)
 	} else if !updated {
@@ -349,6 +391,8 @@ func (i *instance) HAProxyUpdate(timer *utils.Timer) error {
 			i.metrics.IncUpdateNoop()
 			return fmt.Errorf("error writing configuration: %w", err)
 		}
+		// a pending rewrite always reloads, so all the files were just written
+		i.rewritePending = false
 	}
 	i.updateCertExpiring()
 	defer func() {
@@ -360,12 +404,20 @@ func (i *instance) HAProxyUpdate(timer *utils.Timer) error {
 		if updater.cmdCnt > 0 {
 			if i.options.ValidateConfig {
 				var err error
-				if err = i.check(); err != nil {
+				if err = i.check(); err != nil && i.quarantineSnippets(err) {
+					err = nil
+				}
+				if err != nil {
 					i.logger.Error("error validating config file:\n%v", err)
+					i.rollbackUpdate()
 				}
 				timer.Tick("validate_cfg")
 				i.updateSuccessful(err == nil)
 			}
+			if i.failedSince == nil && !i.reloadPending {
+				// files written by a dynamic update match the running haproxy
+				i.saveLastKnownGood()
+			}
 			i.logger.Info("haproxy updated without needing to reload. Commands sent: %d", updater.cmdCnt)
 			i.metrics.IncUpdateDynamic()
 		} else {
@@ -374,15 +426,32 @@ func (i *instance) HAProxyUpdate(timer *utils.Timer) error {
 		}
 		return nil
 	}
+	// changes were partially applied if any command was sent to the runtime API
+	partial := updater.cmdCnt > 0
 	if i.options.ReloadQueue != nil {
+		// the queue's rate limiter coalesces reloads requested in the meantime
+		i.reloadPending = true
 		i.options.ReloadQueue.Add(nil)
+		if partial {
+			i.logger.Info("haproxy partially updated without reloading. Commands sent: %d; reload enqueued", updater.cmdCnt)
+			i.metrics.IncUpdatePartial()
+			return nil
+		}
 		i.logger.InfoV(2, "haproxy reload enqueued")
 		return nil
 	}
+	if partial {
+		i.logger.Info("haproxy partially updated without reloading. Commands sent: %d; reloading now", updater.cmdCnt)
+	}
 	return i.Reload(timer)
 }
 
 func (i *instance) Reload(timer *utils.Timer) error {
+	if i.options.ReloadQueue != nil && !i.reloadPending {
+		// a deferred reload whose changes were already applied by a former one
+		i.logger.InfoV(2, "haproxy reload skipped, no pending changes")
+		return nil
+	}
 	i.metrics.IncUpdateFull()
 	if i.options.TrackInstances {
 		timeoutStopDur := i.config.Global().TimeoutStopDuration
@@ -392,16 +461,24 @@ func (i *instance) Reload(timer *utils.Timer) error {
 		}
 	}
 	err := i.reloadHAProxy()
+	if err != nil && i.quarantineSnippets(i.check()) {
+		// configuration snippets rejected by haproxy were removed, trying again
+		err = i.reloadHAProxy()
+	}
 	timer.Tick("reload_haproxy")
 	if err != nil {
 		i.updateSuccessful(false)
 		if i.options.TrackInstances {
 			i.conns.ReleaseLastInstance()
 		}
+		i.rollback()
 		return fmt.Errorf("error reloading server: %w", err)
 	}
 	i.up = true
+	i.reloadPending = false
+	i.rolledBack = false
 	i.updateSuccessful(true)
+	i.saveLastKnownGood()
 	message := "haproxy successfully reloaded"
 	if i.options.IsExternal {
 		message += " (external)"
@@ -507,6 +584,13 @@ func (i *instance) writeConfig() (err error) {
 		return err
 	}
 	//
+	// mirror template execution
+	//
+	err = i.mirrorTmpl.Write(i.config)
+	if err != nil {
+		return err
+	}
+	//
 	// custom responses template execution, raw HTTP HAProxy and Lua script based
 	//
 	responsesList := i.buildCustomHTTPResponses()
@@ -691,6 +775,11 @@ func (i *instance) check() error {
 func (i *instance) reloadHAProxy() error {
 	if i.options.fake {
 		i.logger.Info("(test) reload was skipped")
+		if len(i.options.fakeReloadErrs) > 0 {
+			err := i.options.fakeReloadErrs[0]
+			i.options.fakeReloadErrs = i.options.fakeReloadErrs[1:]
+			return err
+		}
 		return nil
 	}
 	if i.options.IsExternal {
@@ -874,12 +963,14 @@ func (i *instance) waitWorker(prevReloads int) error {
 }
 
 func (i *instance) retrieveServersState() (string, error) {
-	state, err := i.conns.Admin().Send(nil, "show servers state")
-	if err != nil {
+	var states []socket.ServerState
+	cmd := socket.ShowServersStateCmd("", &states)
+	if err := socket.NewClient(i.conns.Admin(), nil).Pipeline(cmd); err != nil {
 		return "", fmt.Errorf("failed to retrieve servers state from external haproxy; %w", err)
 	}
 
-	return state[0], nil
+	// the raw response is persisted, it has the format of the server-state-file
+	return cmd.Response, nil
 }
 
 func (i *instance) persistServersState() error {
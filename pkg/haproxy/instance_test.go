@@ -1142,6 +1142,136 @@ frontend _front_http_8002
     http-request redirect scheme https if !https-request { var(txn.pathID) -m str path01 }
     http-response set-header Strict-Transport-Security "max-age=0" if https-request { var(txn.pathID) -m str path02 }`,
 		},
+		"test71 header modifiers": {
+			doconfig: func(c *testConfig, h *hatypes.Host, b *hatypes.Backend) {
+				h.FindPath("/app")[0].ReqHeaders = hatypes.HTTPHeaderModifier{
+					Set:    []hatypes.HTTPHeader{{Name: "X-Set", Value: "it's 100%"}},
+					Add:    []hatypes.HTTPHeader{{Name: "X-Add", Value: "add"}},
+					Remove: []string{"X-Remove"},
+				}
+				h.FindPath("/app")[0].ResHeaders = hatypes.HTTPHeaderModifier{
+					Remove: []string{"Server"},
+				}
+				h.FindPath("/api")[0].ResHeaders = hatypes.HTTPHeaderModifier{
+					Set: []hatypes.HTTPHeader{{Name: "Cache-Control", Value: "no-cache"}},
+				}
+			},
+			path: []string{"/app", "/api"},
+			expected: `
+    # path02 = d1.local/api
+    # path01 = d1.local/app
+    http-request set-var(txn.pathID) var(req.base),lower,map_beg(/etc/haproxy/maps/_back_d1_app_8080_front_http_req__begin.map)
+    http-request set-header X-Set 'it'"'"'s 100%%' if { var(txn.pathID) -m str path01 }
+    http-request add-header X-Add 'add' if { var(txn.pathID) -m str path01 }
+    http-request del-header X-Remove if { var(txn.pathID) -m str path01 }
+    http-response set-header Cache-Control 'no-cache' if { var(txn.pathID) -m str path02 }
+    http-response del-header Server if { var(txn.pathID) -m str path01 }`,
+			expCheck: map[string]string{
+				"_back_d1_app_8080_front_http_req__begin.map": `
+d1.local#/app path01
+d1.local#/api path02`,
+			},
+		},
+		"test72 rewrite full path": {
+			doconfig: func(c *testConfig, h *hatypes.Host, b *hatypes.Backend) {
+				h.FindPath("/app")[0].RewriteURL = "/other"
+				h.FindPath("/api")[0].RewriteURL = "/v2/api%20"
+				h.FindPath("/api")[0].RewriteFull = true
+			},
+			path: []string{"/app", "/api"},
+			expected: `
+    # path02 = d1.local/api
+    # path01 = d1.local/app
+    http-request set-var(txn.pathID) var(req.base),lower,map_beg(/etc/haproxy/maps/_back_d1_app_8080_front_http_req__begin.map)
+    http-request set-path '/v2/api%%20'     if { var(txn.pathID) -m str path02 }
+    http-request replace-path ^/app(.*)$       /other\1     if { var(txn.pathID) -m str path01 }`,
+			expCheck: map[string]string{
+				"_back_d1_app_8080_front_http_req__begin.map": `
+d1.local#/app path01
+d1.local#/api path02`,
+			},
+		},
+		"test73 method and query match": {
+			doconfig: func(c *testConfig, h *hatypes.Host, b *hatypes.Backend) {
+				f := c.httpFrontend(80)
+				link1 := hatypes.CreatePathLink("/search", hatypes.MatchPrefix).
+					WithMethodMatch("GET").
+					WithQueryMatch(hatypes.HTTPQueryMatch{{Name: "version", Value: "2"}})
+				link2 := hatypes.CreatePathLink("/search", hatypes.MatchPrefix).
+					WithQueryMatch(hatypes.HTTPQueryMatch{{Name: "version", Value: "^v[12]$", Regex: true}})
+
+				hdef := f.AcquireHost(hatypes.DefaultHost)
+				hdef.AddLink(b, link1)
+				h.AddLink(b, link2)
+			},
+			expFronts: `frontend _front_http
+    mode http
+    bind :80
+    <<set-req-base>>
+    <<http-headers>>
+    http-request set-var(req.backend) var(req.base),map_dir(/etc/haproxy/maps/_front_http_host__prefix_01.map) if { urlp(version) -m reg -- '^v[12]$' }
+    http-request set-var(req.backend) var(req.base),lower,map_beg(/etc/haproxy/maps/_front_http_host__begin.map) if !{ var(req.backend) -m found }
+    http-request set-var(req.defaultbackend) str(<default>\#),concat(,req.path),map_dir(/etc/haproxy/maps/_front_http_defaulthost__prefix_01.map) if !{ var(req.backend) -m found } { method GET } { urlp(version) -- '2' }
+    use_backend %[var(req.backend)] if { var(req.backend) -m found }
+    use_backend %[var(req.defaultbackend)]
+    default_backend _error404`,
+			expCheck: map[string]string{
+				"_front_http_host__prefix_01.map":        "d1.local#/search d1_app_8080",
+				"_front_http_defaulthost__prefix_01.map": "<default>#/search d1_app_8080",
+			},
+		},
+		"test74 response compression": {
+			doconfig: func(c *testConfig, h *hatypes.Host, b *hatypes.Backend) {
+				b.Compression = hatypes.Compression{
+					Algos:     []string{"gzip", "deflate"},
+					Direction: "response",
+					Types:     []string{"text/html", "application/json"},
+				}
+			},
+			expected: `
+    filter compression
+    compression algo gzip deflate
+    compression type text/html application/json`,
+		},
+		"test75 request and response compression": {
+			doconfig: func(c *testConfig, h *hatypes.Host, b *hatypes.Backend) {
+				b.Compression = hatypes.Compression{
+					Algos:     []string{"deflate", "gzip"},
+					Direction: "both",
+					MinSize:   1024,
+					Types:     []string{"application/json"},
+				}
+			},
+			expected: `
+    filter compression
+    compression algo deflate gzip
+    compression type application/json
+    compression minsize-res 1024
+    compression algo-req deflate
+    compression type-req application/json
+    compression minsize-req 1024
+    compression direction both`,
+		},
+		"test76 retry policy": {
+			doconfig: func(c *testConfig, h *hatypes.Host, b *hatypes.Backend) {
+				b.Retry = hatypes.BackendRetry{
+					Redispatch: "false",
+					Retries:    "5",
+					RetryOn:    []string{"conn-failure", "503"},
+				}
+			},
+			expected: `
+    retries 5
+    retry-on conn-failure 503
+    no option redispatch`,
+		},
+		"test77 redispatch": {
+			doconfig: func(c *testConfig, h *hatypes.Host, b *hatypes.Backend) {
+				b.Retry.Redispatch = "true"
+			},
+			expected: `
+    option redispatch`,
+		},
 	}
 	for name, test := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -1284,6 +1414,52 @@ d1.local#/ d1_app_8080`)
 	c.logger.CompareLogging(defaultLogging)
 }
 
+func TestInstanceCache(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	b := c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	b.Compression = hatypes.Compression{Algos: []string{"gzip"}}
+	h := c.httpFrontend(80).AcquireHost("d1.local")
+	h.AddPath(b, "/", hatypes.MatchBegin)
+	h.AddPath(b, "/static", hatypes.MatchBegin).Cache = hatypes.Cache{
+		Enabled:       true,
+		MaxAge:        60,
+		MaxObjectSize: 1048576,
+		ProcessVary:   true,
+		TotalMaxSize:  64,
+	}
+
+	c.Update()
+	c.checkConfig(`
+<<global>>
+<<defaults>>
+cache d1_app_8080_1
+    total-max-size 64
+    max-age 60
+    max-object-size 1048576
+    process-vary on
+backend d1_app_8080
+    mode http
+    filter cache d1_app_8080_1
+    filter compression
+    compression algo gzip
+    # path01 = d1.local/
+    # path02 = d1.local/static
+    http-request set-var(txn.pathID) var(req.base),lower,map_beg(/etc/haproxy/maps/_back_d1_app_8080_front_http_req__begin.map)
+    http-request cache-use d1_app_8080_1 if { var(txn.pathID) -m str path02 }
+    http-response cache-store d1_app_8080_1 if { var(txn.pathID) -m str path02 }
+    server s1 172.17.0.11:8080 weight 100
+<<backends-default>>
+<<frontend-http>>
+    default_backend _error404
+<<support>>
+`)
+
+	c.logger.CompareLogging(defaultLogging)
+}
+
 func TestInstanceFrontendList(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -5011,6 +5187,75 @@ frontend _front_http
 	}
 }
 
+func TestInstanceRedirectToRules(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	f := c.httpFrontend(80)
+	f.RedirectToCode = 302
+	b := c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+
+	h := f.AcquireHost("d1.local")
+	h.AddRedirect("/", hatypes.MatchBegin, "https://app.local/login%20")
+	p := h.AddRedirect("/app.v1", hatypes.MatchPrefix, "https://d1.local/new%[pathq,bytes(7)]")
+	p.RedirToCode = 301
+	p.RedirToFormat = true
+	p = h.AddRedirect("/app.v2", hatypes.MatchPrefix, "https://d1.local/new%[pathq,bytes(7)]")
+	p.RedirToCode = 301
+	p.RedirToFormat = true
+	p = h.AddRedirect("/static", hatypes.MatchPrefix, "https://static.local/")
+	p.RedirToCode = 307
+
+	h = f.AcquireHost(hatypes.DefaultHost)
+	h.AddPath(b, "/", hatypes.MatchBegin)
+	p = h.AddRedirect("/old", hatypes.MatchPrefix, "http://%[req.hdr(host),field(1,:)]%[pathq,bytes(4),regsub(^/*,/)]")
+	p.RedirToCode = 302
+	p.RedirToFormat = true
+
+	c.Update()
+	c.checkConfig(`
+<<global>>
+<<defaults>>
+backend d1_app_8080
+    mode http
+    server s1 172.17.0.11:8080 weight 100
+<<backends-default>>
+frontend _front_http
+    mode http
+    bind :80
+    <<set-req-base>>
+    <<http-headers>>
+    http-request set-var(req.redirto) var(req.base),map_dir(/etc/haproxy/maps/_front_http_redir_to__prefix_01.map)
+    http-request set-var(req.redirto) var(req.base),lower,map_beg(/etc/haproxy/maps/_front_http_redir_to__begin.map) if !{ var(req.redirto) -m found }
+    http-request redirect location 'https://static.local/' code 307 if { var(req.redirto) -m str _redirto2 }
+    http-request redirect location 'https://d1.local/new%[pathq,bytes(7)]' code 301 if { var(req.redirto) -m str _redirto3 }
+    http-request redirect location %[var(req.redirto)] code 302 if { var(req.redirto) -m found }
+    http-request set-var(req.defaultbackend) str(<default>\#),concat(,req.path),map_dir(/etc/haproxy/maps/_front_http_defaulthost__prefix_01.map) if !{ var(req.backend) -m found }
+    http-request set-var(req.defaultbackend) str(<default>\#),concat(,req.path),lower,map_beg(/etc/haproxy/maps/_front_http_defaulthost__begin.map) if !{ var(req.backend) -m found } !{ var(req.defaultbackend) -m found }
+    http-request redirect location 'http://%[req.hdr(host),field(1,:)]%[pathq,bytes(4),regsub(^/*,/)]' code 302 if { var(req.defaultbackend) -m str _redirto1 }
+    use_backend %[var(req.backend)] if { var(req.backend) -m found }
+    use_backend %[var(req.defaultbackend)]
+    default_backend _error404
+<<support>>
+`)
+	c.checkMap("_front_http_redir_to__prefix_01.map", `
+d1.local#/static _redirto2
+d1.local#/app.v2 _redirto3
+d1.local#/app.v1 _redirto3
+`)
+	c.checkMap("_front_http_redir_to__begin.map", `
+d1.local#/ https://app.local/login%20
+`)
+	c.checkMap("_front_http_defaulthost__prefix_01.map", `
+<default>#/old _redirto1
+`)
+	c.checkMap("_front_http_defaulthost__begin.map", `
+<default>#/ d1_app_8080
+`)
+	c.logger.CompareLogging(defaultLogging)
+}
+
 func TestInstanceSyslog(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -5734,6 +5979,131 @@ backend d1_app_8080
 	}
 }
 
+func TestInstanceAuthJWT(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	b := c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	h := c.httpFrontend(80).AcquireHost("d1.local")
+	h.AddPath(b, "/", hatypes.MatchBegin)
+	h.AddPath(b, "/api", hatypes.MatchBegin).AuthJWT = hatypes.AuthJWT{
+		Audience: "api",
+		Headers:  []hatypes.AuthJWTHeader{{Claim: "sub", Name: "X-User"}},
+		Issuer:   "https://issuer.local",
+		Verifiers: []hatypes.AuthJWTVerifier{
+			{Algorithm: "RS256", KeyFile: "/var/lib/haproxy/jwtkeys/default_jwks_0.pem", KeyID: "key0"},
+			{Algorithm: "ES256", KeyFile: "/var/lib/haproxy/jwtkeys/default_jwks_1.pem"},
+		},
+	}
+	h.AddPath(b, "/admin", hatypes.MatchBegin).AuthJWT = hatypes.AuthJWT{AlwaysDeny: true}
+
+	c.Update()
+	c.checkConfig(`
+<<global>>
+<<defaults>>
+backend d1_app_8080
+    mode http
+    # path01 = d1.local/
+    # path03 = d1.local/admin
+    # path02 = d1.local/api
+    http-request set-var(txn.pathID) var(req.base),lower,map_beg(/etc/haproxy/maps/_back_d1_app_8080_front_http_req__begin.map)
+    http-request deny deny_status 401 if { var(txn.pathID) -m str path03 }
+    http-request set-var(txn.jwt_valid) bool(false) if { var(txn.pathID) -m str path02 }
+    http-request set-var(txn.jwt_alg) http_auth_bearer,jwt_header_query('$.alg') if { var(txn.pathID) -m str path02 }
+    http-request set-var(txn.jwt_kid) http_auth_bearer,jwt_header_query('$.kid') if { var(txn.pathID) -m str path02 }
+    http-request set-var(txn.jwt_valid) bool(true) if { var(txn.pathID) -m str path02 } { var(txn.jwt_alg) -m str RS256 } { var(txn.jwt_kid) -m str key0 } { http_auth_bearer,jwt_verify(RS256,"/var/lib/haproxy/jwtkeys/default_jwks_0.pem") -m int 1 }
+    http-request set-var(txn.jwt_valid) bool(true) if { var(txn.pathID) -m str path02 } { var(txn.jwt_alg) -m str ES256 } { http_auth_bearer,jwt_verify(ES256,"/var/lib/haproxy/jwtkeys/default_jwks_1.pem") -m int 1 }
+    http-request set-var(txn.jwt_valid) bool(false) if { var(txn.pathID) -m str path02 } !{ http_auth_bearer,jwt_payload_query('$.iss') -m str https://issuer.local }
+    http-request set-var(txn.jwt_valid) bool(false) if { var(txn.pathID) -m str path02 } !{ http_auth_bearer,jwt_payload_query('$.aud') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[0]') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[1]') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[2]') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[3]') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[4]') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[5]') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[6]') -m str api } !{ http_auth_bearer,jwt_payload_query('$.aud[7]') -m str api }
+    http-request set-var(txn.jwt_exp) http_auth_bearer,jwt_payload_query('$.exp','int') if { var(txn.pathID) -m str path02 }
+    http-request set-var(txn.jwt_nbf) http_auth_bearer,jwt_payload_query('$.nbf','int') if { var(txn.pathID) -m str path02 }
+    http-request set-var(txn.jwt_valid) bool(false) if { var(txn.pathID) -m str path02 } !{ var(txn.jwt_exp) -m found }
+    http-request set-var(txn.jwt_valid) bool(false) if { var(txn.pathID) -m str path02 } { date,neg,add(txn.jwt_exp) -m int lt 0 }
+    http-request set-var(txn.jwt_valid) bool(false) if { var(txn.pathID) -m str path02 } { var(txn.jwt_nbf) -m found } { date,neg,add(txn.jwt_nbf) -m int gt 0 }
+    http-request return status 401 hdr WWW-Authenticate Bearer if { var(txn.pathID) -m str path02 } !{ var(txn.jwt_valid) -m bool }
+    http-request set-header X-User %[http_auth_bearer,jwt_payload_query('$.sub')] if { var(txn.pathID) -m str path02 }
+    server s1 172.17.0.11:8080 weight 100
+<<backends-default>>
+<<frontend-http>>
+    default_backend _error404
+<<support>>
+`)
+
+	c.logger.CompareLogging(defaultLogging)
+}
+
+func TestInstanceMirror(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	bshadow := c.config.Backends().AcquireBackend("d1", "shadow", "8080")
+	bshadow.Endpoints = []*hatypes.Endpoint{endpointS21}
+	b := c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	h := c.httpFrontend(80).AcquireHost("d1.local")
+	h.AddPath(b, "/", hatypes.MatchBegin).Mirror = hatypes.Mirror{BackendID: bshadow.ID, Percent: 100}
+	h.AddPath(b, "/api", hatypes.MatchBegin).Mirror = hatypes.Mirror{BackendID: bshadow.ID, Percent: 10}
+	h.AddPath(b, "/static", hatypes.MatchBegin)
+
+	globalMirror := &c.config.Global().Mirror
+	globalMirror.Endpoints = []string{"127.0.0.1:12345"}
+	globalMirror.Port = 10280
+	globalMirror.Timeout.Connect = "5s"
+	globalMirror.Timeout.Server = "5s"
+	globalMirror.Timeout.Processing = "1s"
+
+	c.Update()
+	c.checkConfig(`
+<<global>>
+<<defaults>>
+backend d1_app_8080
+    mode http
+    # path01 = d1.local/
+    # path02 = d1.local/api
+    # path03 = d1.local/static
+    http-request set-var(txn.pathID) var(req.base),lower,map_beg(/etc/haproxy/maps/_back_d1_app_8080_front_http_req__begin.map)
+    option http-buffer-request
+    filter spoe engine mirror config /etc/haproxy/spoe-mirror.conf
+    http-request del-header X-HAProxy-Mirror
+    http-request set-header X-HAProxy-Mirror d1_shadow_8080 if { var(txn.pathID) -m str path01 }
+    http-request set-header X-HAProxy-Mirror d1_shadow_8080 if { var(txn.pathID) -m str path02 } { rand(100) lt 10 }
+    http-request send-spoe-group mirror mirror if { req.hdr(X-HAProxy-Mirror) -m found } !{ var(txn.mirrored) -m bool }
+    http-request del-header X-HAProxy-Mirror
+    server s1 172.17.0.11:8080 weight 100
+backend d1_shadow_8080
+    mode http
+    server s21 172.17.0.121:8080 weight 100
+<<backends-default>>
+<<frontend-http>>
+    default_backend _error404
+<<support>>
+backend spoe-mirror
+    mode tcp
+    timeout connect 5s
+    timeout server  5s
+    server mirror-spoa0 127.0.0.1:12345
+frontend _front_mirror
+    mode http
+    bind 127.0.0.1:10280
+    http-request set-var(txn.mirrored) bool(true)
+    http-request set-var(req.mirror) req.hdr(X-HAProxy-Mirror)
+    http-request del-header X-HAProxy-Mirror
+    use_backend %[var(req.mirror)]
+    default_backend _error404
+    no log
+`)
+	c.containsText("spoe-mirror.conf", c.readConfig(c.tempdir+"/spoe-mirror.conf"), `
+spoe-agent mirror-agent
+    groups       mirror
+    timeout      hello       5s
+    timeout      idle        5s
+    timeout      processing  1s
+    use-backend  spoe-mirror`)
+
+	c.logger.CompareLogging(defaultLogging)
+}
+
 func TestInstanceWildcardHostname(t *testing.T) {
 	c := setup(t)
 	defer c.teardown()
@@ -6047,6 +6417,15 @@ func setupOptions(options testOptions) *testConfig {
 	); err != nil {
 		t.Errorf("error parsing modsecurity.tmpl: %v", err)
 	}
+	if err := instance.mirrorTmpl.NewTemplate(
+		"mirror.tmpl",
+		"../../rootfs/etc/templates/mirror/mirror.tmpl",
+		filepath.Join(tempdir, "spoe-mirror.conf"),
+		0,
+		1024,
+	); err != nil {
+		t.Errorf("error parsing mirror.tmpl: %v", err)
+	}
 	config := instance.Config().(*config)
 	config.frontends.DefaultCrtFile = "/var/haproxy/ssl/certs/default.pem"
 	c := &testConfig{
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+// configErrorRegex matches the file and line number of a configuration error, eg:
+// [ALERT]    (1) : config : parsing [/etc/haproxy/haproxy.cfg:105] : unknown keyword 'x' in 'backend' section
+var configErrorRegex = regexp.MustCompile(`\[([^\[\]:]+):([0-9]+)\]`)
+
+// snippetMarker prefixes the comments that delimit the configuration snippets
+// of resources, see the "snippet" template.
+const snippetMarker = "# config-snippet "
+
+type rejectedSnippet struct {
+	backend *hatypes.Backend
+	source  *hatypes.SnippetSource
+	late    bool
+	reason  string
+}
+
+type unattributedError struct {
+	section string
+	reason  string
+}
+
+// quarantineSnippets removes from the configuration the snippets that made the
+// validation fail, and validates the configuration again. err is the output of
+// the failed validation. Returns true if snippets were removed and the new
+// configuration is valid. Only backend snippets of resources can be removed,
+// errors in any other section, including frontend, defaults and global snippets
+// of the global ConfigMap, are logged and fail the validation as a whole.
+func (i *instance) quarantineSnippets(err error) bool {
+	quarantined := false
+	for err != nil {
+		rejected, unattributed := i.rejectedSnippets(err.Error())
+		for _, cfgerr := range unattributed {
+			i.logger.Warn("configuration error on %s is not part of a resource snippet and cannot be quarantined: %s", cfgerr.section, cfgerr.reason)
+		}
+		if len(rejected) == 0 {
+			// configuration is invalid due to something else
+			return false
+		}
+		for _, snippet := range rejected {
+			backend := snippet.backend
+			// sources are preserved, so the quarantined snippet is not pruned
+			if snippet.late {
+				backend.CustomConfigLate = nil
+			} else {
+				backend.CustomConfigEarly = nil
+			}
+			i.config.Backends().BackendChanged(backend)
+			i.config.Quarantine().Add(snippet.source, snippet.reason)
+			i.logger.Warn("ignoring configuration snippet of %s on backend '%s': %s", snippet.source, backend.ID, snippet.reason)
+			if i.options.SnippetRejected != nil {
+				i.options.SnippetRejected(snippet.source, snippet.reason)
+			}
+		}
+		if err := i.writeConfig(); err != nil {
+			i.logger.Error("error writing configuration: %v", err)
+			return false
+		}
+		quarantined = true
+		err = i.check()
+	}
+	return quarantined
+}
+
+// rejectedSnippets maps the configuration errors of a haproxy validation
+// output to the backend configuration snippets that caused them. Errors that
+// cannot be mapped to a snippet of a resource are returned as unattributed.
+func (i *instance) rejectedSnippets(output string) (rejected []*rejectedSnippet, unattributed []*unattributedError) {
+	files := map[string][]string{}
+	found := map[*hatypes.SnippetSource]bool{}
+	for _, outline := range strings.Split(output, "\n") {
+		match := configErrorRegex.FindStringSubmatch(outline)
+		if match == nil {
+			continue
+		}
+		filename := match[1]
+		lines, read := files[filename]
+		if !read {
+			if content, err := os.ReadFile(filename); err == nil {
+				lines = strings.Split(string(content), "\n")
+			}
+			files[filename] = lines
+		}
+		lineno, _ := strconv.Atoi(match[2])
+		if lineno < 1 || lineno > len(lines) {
+			continue
+		}
+		reason := strings.TrimSpace(outline)
+		section := sectionHeader(lines, lineno-1)
+		var backend *hatypes.Backend
+		if len(section) == 2 && section[0] == "backend" {
+			backend = i.config.Backends().Items()[section[1]]
+		}
+		if backend == nil {
+			unattributed = append(unattributed, &unattributedError{
+				section: sectionName(section),
+				reason:  reason,
+			})
+			continue
+		}
+		snippet := &rejectedSnippet{
+			backend: backend,
+			reason:  reason,
+		}
+		switch snippetKind(lines, lineno-1) {
+		case "early":
+			snippet.source = backend.CustomConfigSources.Early
+		case "late":
+			snippet.source = backend.CustomConfigSources.Late
+			snippet.late = true
+		}
+		if snippet.source == nil {
+			// snippets without a source came from the global config, they are not quarantined
+			unattributed = append(unattributed, &unattributedError{
+				section: sectionName(section),
+				reason:  reason,
+			})
+		} else if !found[snippet.source] {
+			found[snippet.source] = true
+			rejected = append(rejected, snippet)
+		}
+	}
+	return rejected, unattributed
+}
+
+// sectionHeader returns the fields of the header of the section that declares
+// the line at index pos, or nil if the line is not part of a section.
+func sectionHeader(lines []string, pos int) []string {
+	for ; pos >= 0; pos-- {
+		line := lines[pos]
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+			continue
+		}
+		// first non indented line is the section header
+		return strings.Fields(line)
+	}
+	return nil
+}
+
+// sectionName formats the fields of a section header, eg "frontend '_front_http'".
+func sectionName(section []string) string {
+	switch len(section) {
+	case 0:
+		return "unknown section"
+	case 1:
+		return section[0] + " section"
+	}
+	return fmt.Sprintf("%s '%s'", section[0], section[1])
+}
+
+// snippetKind returns the kind of the snippet, early or late, that declares the
+// line at index pos, or an empty string if the line is not part of a snippet.
+func snippetKind(lines []string, pos int) string {
+	for pos--; pos >= 0; pos-- {
+		line := lines[pos]
+		if line != "" && line[0] != ' ' && line[0] != '\t' && line[0] != '#' {
+			// section header
+			return ""
+		}
+		marker, found := strings.CutPrefix(strings.TrimSpace(line), snippetMarker)
+		if !found {
+			continue
+		}
+		if marker == "end" {
+			return ""
+		}
+		kind, _, _ := strings.Cut(marker, ":")
+		return kind
+	}
+	return ""
+}
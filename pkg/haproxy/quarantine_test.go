@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+func TestQuarantineSnippets(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.configGlobal(c.config.Global())
+	b := c.config.Backends().AcquireBackend("default", "app", "8080")
+	b.AcquireEndpoint("172.17.0.11", 8080, "")
+	early := []string{"http-request set-header X-Early 1"}
+	late := []string{"http-request set-header X-Late 1", "http-request invalid-keyword"}
+	b.CustomConfigEarly = early
+	b.CustomConfigLate = late
+	b.CustomConfigSources.Early = hatypes.NewSnippetSource("Ingress", "default", "app", "config-backend-early", early)
+	b.CustomConfigSources.Late = hatypes.NewSnippetSource("Service", "default", "app", "config-backend", late)
+	global := c.config.Backends().AcquireBackend("default", "global", "8080")
+	global.AcquireEndpoint("172.17.0.21", 8080, "")
+	global.CustomConfigLate = []string{"http-request invalid-keyword"}
+	// same content of a snippet line, but rendered outside of the snippet
+	c.config.Global().CustomProxy = map[string][]string{b.ID: {"http-request invalid-keyword"}}
+
+	var rejected []string
+	c.instance.options.SnippetRejected = func(source *hatypes.SnippetSource, reason string) {
+		rejected = append(rejected, source.String())
+	}
+	if err := c.instance.writeConfig(); err != nil {
+		t.Errorf("error writing config: %v", err)
+	}
+
+	// find the lines of the invalid snippets
+	cfgFile := filepath.Join(c.tempdir, "haproxy.cfg")
+	var errors []string
+	for i, line := range strings.Split(c.readRawConfig(cfgFile), "\n") {
+		if strings.TrimSpace(line) == "http-request invalid-keyword" {
+			errors = append(errors, fmt.Sprintf("[ALERT]    (1) : config : parsing [%s:%d] : unknown keyword 'invalid-keyword'", cfgFile, i+1))
+		}
+	}
+	if len(errors) != 3 {
+		t.Fatalf("expected 3 invalid lines, found %d", len(errors))
+	}
+	output := strings.Join(append(errors, "[ALERT]    (1) : config : Fatal errors found in configuration."), "\n")
+
+	// invalid snippets without a source cannot be quarantined
+	if c.instance.quarantineSnippets(fmt.Errorf("%s", errors[2])) {
+		t.Errorf("expected snippet without a source not quarantined")
+	}
+	c.logger.CompareLogging(fmt.Sprintf(`
+WARN configuration error on backend 'default_global_8080' is not part of a resource snippet and cannot be quarantined: %s`, errors[2]))
+
+	// lines outside of the snippet are not attributed to it, even with the same content
+	if c.instance.quarantineSnippets(fmt.Errorf("%s", errors[1])) {
+		t.Errorf("expected line outside of the snippet not quarantined")
+	}
+	c.logger.CompareLogging(fmt.Sprintf(`
+WARN configuration error on backend 'default_app_8080' is not part of a resource snippet and cannot be quarantined: %s`, errors[1]))
+
+	if !c.instance.quarantineSnippets(fmt.Errorf("%s", output)) {
+		t.Errorf("expected snippets quarantined")
+	}
+	c.logger.CompareLogging(fmt.Sprintf(`
+WARN configuration error on backend 'default_app_8080' is not part of a resource snippet and cannot be quarantined: %s
+WARN configuration error on backend 'default_global_8080' is not part of a resource snippet and cannot be quarantined: %s
+WARN ignoring configuration snippet of Service 'default/app' key 'config-backend' on backend 'default_app_8080': %s
+INFO (test) check was skipped`, errors[1], errors[2], errors[0]))
+
+	if !reflect.DeepEqual(b.CustomConfigEarly, early) || b.CustomConfigLate != nil {
+		t.Errorf("expected only the late snippet removed, but early was %v and late was %v", b.CustomConfigEarly, b.CustomConfigLate)
+	}
+	if expected := []string{"Service 'default/app' key 'config-backend'"}; !reflect.DeepEqual(rejected, expected) {
+		t.Errorf("rejected snippets differ, expected %v but was %v", expected, rejected)
+	}
+	if _, found := c.config.Quarantine().Reason(hatypes.NewSnippetSource("Service", "default", "app", "config-backend", late)); !found {
+		t.Errorf("expected snippet in the quarantine")
+	}
+	if _, found := c.config.Quarantine().Reason(hatypes.NewSnippetSource("Service", "default", "app", "config-backend", early)); found {
+		t.Errorf("expected changed snippet not in the quarantine")
+	}
+	c.containsText("haproxy.cfg", c.readRawConfig(cfgFile), "http-request set-header X-Early 1")
+	if strings.Contains(c.readRawConfig(cfgFile), "X-Late") {
+		t.Errorf("expected late snippet removed from haproxy.cfg")
+	}
+
+	// quarantined snippets are pruned when their source is not used anymore
+	c.config.PruneQuarantine()
+	if _, found := c.config.Quarantine().Reason(b.CustomConfigSources.Late); !found {
+		t.Errorf("expected snippet still in the quarantine")
+	}
+	c.config.Backends().RemoveAll([]string{b.ID})
+	c.config.PruneQuarantine()
+	if len(c.config.Quarantine()) > 0 {
+		t.Errorf("expected quarantine pruned, but was %v", c.config.Quarantine())
+	}
+}
+
+func TestQuarantineGlobalSnippets(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.configGlobal(c.config.Global())
+	b := c.config.Backends().AcquireBackend("default", "app", "8080")
+	b.AcquireEndpoint("172.17.0.11", 8080, "")
+	c.httpFrontend(80).AcquireHost("d1.local").AddPath(b, "/", hatypes.MatchBegin)
+	c.config.Global().CustomDefaults = []string{"invalid-defaults"}
+	c.config.Global().CustomFrontendLate = []string{"invalid-frontend"}
+
+	rejected := 0
+	c.instance.options.SnippetRejected = func(source *hatypes.SnippetSource, reason string) {
+		rejected++
+	}
+	c.Update()
+	c.logger.CompareLogging(`
+INFO (test) reload was skipped
+INFO haproxy successfully reloaded (embedded daemon)`)
+
+	cfgFile := filepath.Join(c.tempdir, "haproxy.cfg")
+	var errors []string
+	for i, line := range strings.Split(c.readRawConfig(cfgFile), "\n") {
+		if keyword := strings.TrimSpace(line); strings.HasPrefix(keyword, "invalid-") {
+			errors = append(errors, fmt.Sprintf("[ALERT]    (1) : config : parsing [%s:%d] : unknown keyword '%s'", cfgFile, i+1, keyword))
+		}
+	}
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 invalid lines, found %d", len(errors))
+	}
+
+	// errors on global snippets are reported and fail the validation as a whole
+	if c.instance.quarantineSnippets(fmt.Errorf("%s", strings.Join(errors, "\n"))) {
+		t.Errorf("expected global snippets not quarantined")
+	}
+	c.logger.CompareLogging(fmt.Sprintf(`
+WARN configuration error on defaults section is not part of a resource snippet and cannot be quarantined: %s
+WARN configuration error on frontend '_front_http' is not part of a resource snippet and cannot be quarantined: %s`, errors[0], errors[1]))
+	if rejected > 0 {
+		t.Errorf("expected no rejected snippet, but %d were rejected", rejected)
+	}
+	if len(c.config.Quarantine()) > 0 {
+		t.Errorf("expected empty quarantine, but was %v", c.config.Quarantine())
+	}
+	if !reflect.DeepEqual(c.config.Global().CustomFrontendLate, []string{"invalid-frontend"}) {
+		t.Errorf("expected global snippet preserved, but was %v", c.config.Global().CustomFrontendLate)
+	}
+}
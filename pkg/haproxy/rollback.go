@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// lastKnownGoodDir is the directory, inside the haproxy config dir, that stores a copy
+// of the configuration files currently applied by haproxy. haproxy only reads the
+// .cfg files of the config dir, so the copy is never loaded as part of the configuration.
+const lastKnownGoodDir = "last-known-good"
+
+// previousDir is the directory, inside lastKnownGoodDir, that stores a copy of the
+// configuration files as they were before the current update, see snapshotConfig.
+const previousDir = "previous"
+
+// lastKnownGoodDirs returns the directories whose files are part of the last known
+// good configuration, indexed by the name of the directory in the copy.
+func (i *instance) lastKnownGoodDirs() map[string]string {
+	cfgDir := i.options.HAProxyCfgDir
+	dirs := map[string]string{
+		"cfg":        cfgDir,
+		"errorfiles": filepath.Join(cfgDir, "errorfiles"),
+		"lua":        filepath.Join(cfgDir, "lua"),
+	}
+	if mapsDir := i.options.HAProxyMapsDir; mapsDir != "" && filepath.Clean(mapsDir) != filepath.Clean(cfgDir) {
+		dirs["maps"] = mapsDir
+	}
+	return dirs
+}
+
+// trackChanges adds the hosts and backends changed by the current update to the list
+// of objects whose changes were not applied by haproxy yet.
+func (i *instance) trackChanges() {
+	if i.changedHosts == nil {
+		i.changedHosts = map[string]bool{}
+		i.changedBackends = map[string]bool{}
+	}
+	for _, f := range i.config.Frontends().Items() {
+		for hostname := range f.HostsAdd() {
+			i.changedHosts[hostname] = true
+		}
+		for hostname := range f.HostsDel() {
+			i.changedHosts[hostname] = true
+		}
+	}
+	for backend := range i.config.Backends().ItemsAdd() {
+		i.changedBackends[backend] = true
+	}
+	for backend := range i.config.Backends().ItemsDel() {
+		i.changedBackends[backend] = true
+	}
+}
+
+// saveLastKnownGood copies the configuration files currently applied by haproxy.
+// Only files that changed since the last copy are written.
+func (i *instance) saveLastKnownGood() {
+	lkg := filepath.Join(i.options.HAProxyCfgDir, lastKnownGoodDir)
+	for name, dir := range i.lastKnownGoodDirs() {
+		if err := syncDirFiles(dir, filepath.Join(lkg, name)); err != nil {
+			i.logger.Error("error saving the last known good configuration: %v", err)
+			i.hasLastKnownGood = false
+			return
+		}
+	}
+	i.hasLastKnownGood = true
+	i.changedHosts = nil
+	i.changedBackends = nil
+}
+
+// restoreLastKnownGood overwrites the configuration files with the last known good copy.
+// The rejected files are copied to the rejected dir, so they can be compared.
+func (i *instance) restoreLastKnownGood() error {
+	lkg := filepath.Join(i.options.HAProxyCfgDir, lastKnownGoodDir)
+	dirs := i.lastKnownGoodDirs()
+	for name, dir := range dirs {
+		if err := syncDirFiles(dir, filepath.Join(lkg, "rejected", name)); err != nil {
+			return err
+		}
+	}
+	for name, dir := range dirs {
+		if err := syncDirFiles(filepath.Join(lkg, name), dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotConfig copies the configuration files before the current update writes
+// them, so rollbackUpdate can restore only the files changed by the update.
+func (i *instance) snapshotConfig() {
+	previous := filepath.Join(i.options.HAProxyCfgDir, lastKnownGoodDir, previousDir)
+	for name, dir := range i.lastKnownGoodDirs() {
+		if err := syncDirFiles(dir, filepath.Join(previous, name)); err != nil {
+			i.logger.Error("error saving a copy of the configuration: %v", err)
+			i.hasSnapshot = false
+			return
+		}
+	}
+	i.hasSnapshot = true
+}
+
+// rollback restores the last known good configuration after haproxy failed to
+// reload, and reloads haproxy with the restored files. The rejected files are only
+// kept in the rejected dir, so a restart of haproxy doesn't load them. The model
+// still has the rejected changes, so the next update writes all the configuration
+// files again, and reloads haproxy. Reload failures don't replace the running
+// configuration, so the reload is skipped if haproxy is already running the last
+// known good configuration.
+func (i *instance) rollback() {
+	if !i.hasLastKnownGood {
+		// eg haproxy was never successfully started
+		i.logger.Warn("cannot roll back the configuration: last known good configuration is missing")
+		return
+	}
+	if err := i.restoreLastKnownGood(); err != nil {
+		i.logger.Error("cannot roll back the configuration: %v", err)
+		return
+	}
+	i.rewritePending = true
+	if i.rolledBack {
+		return
+	}
+	if err := i.reloadHAProxy(); err != nil {
+		i.logger.Error("error reloading haproxy with the last known good configuration: %v", err)
+		return
+	}
+	// haproxy is running the files of the config dir, nothing left to reload
+	i.reloadPending = false
+	i.rolledBack = true
+	i.notifyRollback()
+}
+
+// rollbackUpdate restores the configuration files changed by the current update,
+// after haproxy rejected them on validation. haproxy wasn't reloaded, so only the
+// files are restored: the ones not changed by the update might still be waiting a
+// pending reload. The model still has the rejected changes, so the next update
+// writes all the configuration files again, and reloads haproxy.
+func (i *instance) rollbackUpdate() {
+	if !i.hasSnapshot {
+		i.logger.Warn("cannot roll back the configuration: copy of the former configuration is missing")
+		return
+	}
+	previous := filepath.Join(i.options.HAProxyCfgDir, lastKnownGoodDir, previousDir)
+	for name, dir := range i.lastKnownGoodDirs() {
+		if err := syncDirFiles(filepath.Join(previous, name), dir); err != nil {
+			i.logger.Error("cannot roll back the configuration: %v", err)
+			return
+		}
+	}
+	i.rewritePending = true
+	i.notifyRollback()
+}
+
+func (i *instance) notifyRollback() {
+	i.metrics.IncUpdateRollback()
+	hosts := sortedKeys(i.changedHosts)
+	backends := sortedKeys(i.changedBackends)
+	i.logger.Warn("configuration rolled back to the last known good state, rejected changes of hosts %v and backends %v", hosts, backends)
+	if i.options.ConfigRolledBack != nil {
+		i.options.ConfigRolledBack(hosts, backends)
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// syncDirFiles copies the regular files of the src dir to the dst dir, subdirs are
+// not copied. Files whose size and modification time didn't change are skipped,
+// and .cfg files of dst that don't exist in src are removed, since haproxy would
+// load them as well. A missing src dir is handled as an empty one.
+func syncDirFiles(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	files := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || isRotatedConfig(name) {
+			continue
+		}
+		files[name] = true
+		if err := syncFile(filepath.Join(src, name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	dstEntries, err := os.ReadDir(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range dstEntries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasSuffix(name, ".cfg") && !files[name] {
+			if err := os.Remove(filepath.Join(dst, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isRotatedConfig checks if name is an old configuration file, rotated due to MaxOldConfigFiles
+func isRotatedConfig(name string) bool {
+	return strings.Contains(name, ".cfg.")
+}
+
+func syncFile(src, dst string) error {
+	srcStat, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if dstStat, err := os.Stat(dst); err == nil && dstStat.Size() == srcStat.Size() && dstStat.ModTime().Equal(srcStat.ModTime()) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, srcStat.ModTime(), srcStat.ModTime())
+}
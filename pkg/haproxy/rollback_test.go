@@ -0,0 +1,276 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/utils"
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/utils/workqueue"
+)
+
+func TestRollback(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var rolledBack []string
+	c.instance.options.ConfigRolledBack = func(hosts, backends []string) {
+		rolledBack = append(rolledBack, fmt.Sprintf("hosts=%v backends=%v", hosts, backends))
+	}
+
+	// no copy of the configuration yet
+	c.instance.rollback()
+	c.logger.CompareLogging(`WARN cannot roll back the configuration: last known good configuration is missing`)
+
+	t1 := time.Now().Add(-time.Minute)
+	c.writeFile("haproxy.cfg", "good", t1)
+	c.writeFile("haproxy.cfg.20240101-000000.000", "old", t1)
+	c.writeFile("lua/responses.lua", "good", t1)
+	c.writeFile("errorfiles/404.http", "good", t1)
+	c.instance.saveLastKnownGood()
+
+	c.config.Backends().AcquireBackend("default", "app", "8080")
+	c.config.Frontends().AcquireFrontend(80, false).AcquireHost("domain.local")
+	c.instance.trackChanges()
+
+	t2 := time.Now()
+	c.writeFile("haproxy.cfg", "bad", t2)
+	c.writeFile("haproxy5-backend001.cfg", "bad", t2)
+	c.writeFile("lua/responses.lua", "bad", t2)
+	c.writeFile("_front_http_host.map", "new", t2)
+	c.instance.rollback()
+	c.logger.CompareLogging(`
+INFO (test) reload was skipped
+WARN configuration rolled back to the last known good state, rejected changes of hosts [domain.local] and backends [default_app_8080]`)
+
+	// the rejected files are only kept in the rejected dir, a new update writes them again
+	c.compareFiles("rollback", map[string]string{
+		"haproxy.cfg":                                          "good",
+		"haproxy.cfg.20240101-000000.000":                      "old",
+		"haproxy5-backend001.cfg":                              "<missing>",
+		"lua/responses.lua":                                    "good",
+		"errorfiles/404.http":                                  "good",
+		"last-known-good/cfg/haproxy.cfg":                      "good",
+		"last-known-good/cfg/haproxy5-backend001.cfg":          "<missing>",
+		"last-known-good/lua/responses.lua":                    "good",
+		"last-known-good/rejected/cfg/haproxy.cfg":             "bad",
+		"last-known-good/rejected/cfg/haproxy5-backend001.cfg": "bad",
+		"last-known-good/rejected/lua/responses.lua":           "bad",
+		"last-known-good/rejected/cfg/_front_http_host.map":    "new",
+	})
+	if !c.instance.rewritePending {
+		t.Errorf("expected rewritePending")
+	}
+	if !c.instance.rolledBack {
+		t.Errorf("expected rolledBack")
+	}
+	c.compareText("rolled back", fmt.Sprintf("%v", rolledBack), "[hosts=[domain.local] backends=[default_app_8080]]")
+}
+
+func TestRollbackReloadRetry(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	c.instance.options.ReloadQueue = workqueue.New(func(context.Context, any) error { return nil }, nil)
+
+	t1 := time.Now().Add(-time.Minute)
+	c.writeFile("haproxy.cfg", "good", t1)
+	c.instance.saveLastKnownGood()
+
+	// haproxy rejects the new configuration, the last known good one is
+	// reloaded and remains in the config dir
+	t2 := time.Now().Add(-30 * time.Second)
+	c.writeFile("haproxy.cfg", "bad", t2)
+	c.writeFile("haproxy5-backend001.cfg", "bad", t2)
+	c.instance.reloadPending = true
+	c.instance.options.fakeReloadErrs = []error{fmt.Errorf("invalid config")}
+	if err := c.instance.Reload(&utils.Timer{}); err == nil {
+		t.Errorf("expected reload error")
+	}
+	c.logger.CompareLogging(`
+INFO (test) reload was skipped
+INFO (test) check was skipped
+INFO (test) reload was skipped
+WARN configuration rolled back to the last known good state, rejected changes of hosts [] and backends []`)
+	c.compareFiles("failed reload", map[string]string{
+		"haproxy.cfg":                                          "good",
+		"haproxy5-backend001.cfg":                              "<missing>",
+		"last-known-good/cfg/haproxy.cfg":                      "good",
+		"last-known-good/cfg/haproxy5-backend001.cfg":          "<missing>",
+		"last-known-good/rejected/cfg/haproxy.cfg":             "bad",
+		"last-known-good/rejected/cfg/haproxy5-backend001.cfg": "bad",
+	})
+	if c.instance.reloadPending || !c.instance.rolledBack || !c.instance.rewritePending {
+		t.Errorf("failed reload: expected rolledBack and rewritePending but not reloadPending, but was %t, %t and %t", c.instance.rolledBack, c.instance.rewritePending, c.instance.reloadPending)
+	}
+
+	// the enqueued retry has nothing to apply, haproxy is running the files of the config dir
+	if err := c.instance.Reload(&utils.Timer{}); err != nil {
+		t.Errorf("unexpected reload error: %v", err)
+	}
+	c.logger.CompareLogging(`INFO-V(2) haproxy reload skipped, no pending changes`)
+
+	// a new update writes the rejected configuration again, haproxy rejects it and
+	// is still running the last known good configuration
+	t3 := time.Now().Add(-15 * time.Second)
+	c.writeFile("haproxy.cfg", "bad", t3)
+	c.writeFile("haproxy5-backend001.cfg", "bad", t3)
+	c.instance.rewritePending = false
+	c.instance.reloadPending = true
+	c.instance.options.fakeReloadErrs = []error{fmt.Errorf("invalid config")}
+	if err := c.instance.Reload(&utils.Timer{}); err == nil {
+		t.Errorf("expected reload error")
+	}
+	c.logger.CompareLogging(`
+INFO (test) reload was skipped
+INFO (test) check was skipped`)
+	c.compareFiles("failed update", map[string]string{
+		"haproxy.cfg":                     "good",
+		"haproxy5-backend001.cfg":         "<missing>",
+		"last-known-good/cfg/haproxy.cfg": "good",
+	})
+	if !c.instance.rolledBack || !c.instance.rewritePending {
+		t.Errorf("failed update: expected rolledBack and rewritePending, but was %t and %t", c.instance.rolledBack, c.instance.rewritePending)
+	}
+
+	// a fixed configuration is written and applied
+	t4 := time.Now()
+	c.writeFile("haproxy.cfg", "fixed", t4)
+	c.writeFile("haproxy5-backend001.cfg", "fixed", t4)
+	c.instance.rewritePending = false
+	c.instance.reloadPending = true
+	if err := c.instance.Reload(&utils.Timer{}); err != nil {
+		t.Errorf("unexpected reload error: %v", err)
+	}
+	c.logger.CompareLogging(`
+INFO (test) reload was skipped
+INFO haproxy successfully reloaded (embedded daemon)`)
+	c.compareFiles("successful update", map[string]string{
+		"haproxy.cfg":                                 "fixed",
+		"last-known-good/cfg/haproxy.cfg":             "fixed",
+		"last-known-good/cfg/haproxy5-backend001.cfg": "fixed",
+	})
+	if c.instance.reloadPending || c.instance.rolledBack {
+		t.Errorf("successful update: expected neither reloadPending nor rolledBack, but was %t and %t", c.instance.reloadPending, c.instance.rolledBack)
+	}
+}
+
+func TestRollbackUpdate(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	var rolledBack []string
+	c.instance.options.ConfigRolledBack = func(hosts, backends []string) {
+		rolledBack = append(rolledBack, fmt.Sprintf("hosts=%v backends=%v", hosts, backends))
+	}
+
+	// no copy of the configuration yet
+	c.instance.rollbackUpdate()
+	c.logger.CompareLogging(`WARN cannot roll back the configuration: copy of the former configuration is missing`)
+
+	// the former configuration is newer than the last known good one, eg waiting a reload
+	t1 := time.Now().Add(-2 * time.Minute)
+	c.writeFile("haproxy.cfg", "good", t1)
+	c.writeFile("haproxy5-backend001.cfg", "good", t1)
+	c.instance.saveLastKnownGood()
+	t2 := time.Now().Add(-time.Minute)
+	c.writeFile("haproxy5-backend001.cfg", "pending", t2)
+	c.writeFile("_back_default_app_8080_req.map", "pending", t2)
+	c.instance.snapshotConfig()
+
+	c.config.Backends().AcquireBackend("default", "app", "8080")
+	c.instance.trackChanges()
+
+	t3 := time.Now()
+	c.writeFile("haproxy.cfg", "bad", t3)
+	c.writeFile("haproxy5-backend002.cfg", "bad", t3)
+	c.writeFile("_back_default_app_8080_req.map", "bad", t3)
+	c.instance.rollbackUpdate()
+	c.logger.CompareLogging(`
+WARN configuration rolled back to the last known good state, rejected changes of hosts [] and backends [default_app_8080]`)
+
+	// only the files changed by the update are restored
+	c.compareFiles("rollback update", map[string]string{
+		"haproxy.cfg":                    "good",
+		"haproxy5-backend001.cfg":        "pending",
+		"haproxy5-backend002.cfg":        "<missing>",
+		"_back_default_app_8080_req.map": "pending",
+	})
+	if c.instance.rolledBack || !c.instance.rewritePending {
+		t.Errorf("expected rewritePending and not rolledBack, but was %t and %t", c.instance.rewritePending, c.instance.rolledBack)
+	}
+	c.compareText("rolled back", fmt.Sprintf("%v", rolledBack), "[hosts=[] backends=[default_app_8080]]")
+}
+
+func TestRollbackUpdateRewrite(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+
+	b := c.config.Backends().AcquireBackend("d1", "app", "8080")
+	b.Endpoints = []*hatypes.Endpoint{endpointS1}
+	h := c.httpFrontend(80).AcquireHost("d1.local")
+	h.AddPath(b, "/", hatypes.MatchBegin)
+	h.AddPath(b, "/app", hatypes.MatchBegin)
+	h.FindPath("/app")[0].HSTS = hatypes.HSTS{Enabled: true, MaxAge: 15768000}
+	c.Update()
+	c.logger.CompareLogging(defaultLogging)
+
+	// the map of an unchanged backend was restored by a former rollback
+	mapName := "_back_d1_app_8080_front_http_req__begin.map"
+	expected := c.readRawConfig(filepath.Join(c.tempdir, mapName))
+	c.writeFile(mapName, "restored", time.Now())
+	c.instance.rewritePending = true
+
+	c.Update()
+	c.logger.CompareLogging(`
+INFO-V(2) need to reload due to a former rollback` + defaultLogging)
+	c.compareFiles("rewrite", map[string]string{mapName: expected})
+	if c.instance.rewritePending {
+		t.Errorf("expected rewrite not pending")
+	}
+}
+
+func (c *testConfig) writeFile(name, content string, modTime time.Time) {
+	filename := filepath.Join(c.tempdir, name)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		c.t.Errorf("error creating dir of %s: %v", name, err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		c.t.Errorf("error writing %s: %v", name, err)
+	}
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		c.t.Errorf("error changing times of %s: %v", name, err)
+	}
+}
+
+func (c *testConfig) compareFiles(step string, files map[string]string) {
+	for name, content := range files {
+		actual := "<missing>"
+		if data, err := os.ReadFile(filepath.Join(c.tempdir, name)); err == nil {
+			actual = string(data)
+		}
+		if actual != content {
+			c.t.Errorf("%s: content of %s differ, expected '%s' but was '%s'", step, name, content, actual)
+		}
+	}
+}
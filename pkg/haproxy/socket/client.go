@@ -0,0 +1,737 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package socket
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/utils"
+)
+
+// Errors returned by the runtime API, wrapped into a CommandError. Use
+// errors.Is() to check for one of them.
+var (
+	ErrUnknownCommand     = errors.New("unknown command")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrUnexpectedResponse = errors.New("unexpected response")
+)
+
+// CommandError is a command that haproxy failed to execute, along with its response.
+type CommandError struct {
+	Command  string
+	Response string
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("%v on '%s': %s", e.Err, e.Command, e.Response)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Client is a typed client of the haproxy runtime API. Commands are sent and
+// responses are parsed into structs.
+type Client struct {
+	sock     HAProxySocket
+	observer func(duration time.Duration)
+}
+
+// NewClient creates a runtime API client that sends commands via sock. observer,
+// if not nil, is called with the response time of every command.
+func NewClient(sock HAProxySocket, observer func(duration time.Duration)) *Client {
+	return &Client{
+		sock:     sock,
+		observer: observer,
+	}
+}
+
+// Cmd is a runtime API command that can be pipelined with other commands.
+// Err has the outcome of the command, and Response its raw response, after
+// the pipeline is sent.
+type Cmd struct {
+	Err      error
+	Response string
+	cmd      string
+	parse    func(response string) error
+}
+
+func (c *Cmd) String() string {
+	return c.cmd
+}
+
+// Pipeline sends all the commands using the same connection, and parses the
+// responses into the destination of each command. An error is returned if the
+// commands cannot be sent, or if at least one of them failed.
+func (c *Client) Pipeline(cmds ...*Cmd) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	command := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		command[i] = cmd.cmd
+	}
+	out, err := c.sock.Send(c.observer, command...)
+	var errs []error
+	for i, cmd := range cmds {
+		if i < len(out) {
+			cmd.Response = out[i]
+			cmd.Err = cmd.parse(out[i])
+		} else if err != nil {
+			cmd.Err = err
+		} else {
+			cmd.Err = fmt.Errorf("missing response of '%s'", cmd.cmd)
+		}
+		if cmd.Err != nil && cmd.Err != err {
+			errs = append(errs, cmd.Err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Client) exec(cmd *Cmd) error {
+	_ = c.Pipeline(cmd)
+	return cmd.Err
+}
+
+// responseError classifies the error responses of haproxy. Errors are
+// described in the first line of the response.
+func responseError(cmd, response string) error {
+	first, _, _ := strings.Cut(response, "\n")
+	first = strings.ToLower(first)
+	var err error
+	switch {
+	case strings.HasPrefix(first, "unknown command"):
+		err = ErrUnknownCommand
+	case strings.Contains(first, "permission denied"):
+		err = ErrPermissionDenied
+	case strings.Contains(first, "no such ") || strings.Contains(first, "not found") ||
+		strings.Contains(first, "unknown map") || strings.Contains(first, "can't find"):
+		err = ErrNotFound
+	case strings.Contains(first, "already exists"):
+		err = ErrAlreadyExists
+	case strings.HasPrefix(first, "require ") || strings.HasPrefix(first, "missing ") || strings.Contains(first, "invalid "):
+		err = ErrInvalidArgument
+	default:
+		return nil
+	}
+	return &CommandError{Command: cmd, Response: response, Err: err}
+}
+
+func unexpectedResponse(cmd, response string) error {
+	return &CommandError{Command: cmd, Response: response, Err: ErrUnexpectedResponse}
+}
+
+// newCmd creates a command whose successful response is recognized by ok. name
+// identifies the command in the errors, so payloads like private keys aren't logged.
+func newCmd(cmd, name string, ok func(response string) bool) *Cmd {
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(name, response); err != nil {
+			return err
+		}
+		if !ok(response) {
+			return unexpectedResponse(name, response)
+		}
+		return nil
+	}}
+}
+
+func emptyResponse(response string) bool {
+	return response == ""
+}
+
+func responseContains(substr ...string) func(response string) bool {
+	return func(response string) bool {
+		response = strings.ToLower(response)
+		for _, s := range substr {
+			if strings.Contains(response, strings.ToLower(s)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Info is the output of the `show info` command.
+type Info struct {
+	Name      string
+	Version   string
+	Major     int
+	Minor     int
+	PID       int
+	Nbthread  int
+	Uptime    time.Duration
+	MaxConn   int
+	CurrConns int
+	IdlePct   int
+	// Fields has all the fields of the response, indexed by their names.
+	Fields map[string]string
+}
+
+// ShowInfoCmd creates a `show info` command whose response is parsed into info.
+func ShowInfoCmd(info *Info) *Cmd {
+	cmd := "show info"
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		fields := parseKeyValue(response)
+		if len(fields) == 0 {
+			return unexpectedResponse(cmd, response)
+		}
+		*info = Info{
+			Name:      fields["Name"],
+			Version:   fields["Version"],
+			PID:       atoi(fields["Pid"]),
+			Nbthread:  atoi(fields["Nbthread"]),
+			Uptime:    time.Duration(atoi(fields["Uptime_sec"])) * time.Second,
+			MaxConn:   atoi(fields["Maxconn"]),
+			CurrConns: atoi(fields["CurrConns"]),
+			IdlePct:   atoi(fields["Idle_pct"]),
+			Fields:    fields,
+		}
+		if major, minor, err := ParseVersion(info.Version); err == nil {
+			info.Major = major
+			info.Minor = minor
+		}
+		return nil
+	}}
+}
+
+// ShowInfo reads general information of the running haproxy.
+func (c *Client) ShowInfo() (*Info, error) {
+	info := &Info{}
+	if err := c.exec(ShowInfoCmd(info)); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// StatType ...
+type StatType int
+
+// ...
+const (
+	StatFrontend StatType = 0
+	StatBackend  StatType = 1
+	StatServer   StatType = 2
+	StatListener StatType = 3
+)
+
+// Stat is a line of the `show stat` command, describing a proxy or a server.
+type Stat struct {
+	Proxy         string
+	Name          string
+	Type          StatType
+	Status        string
+	Weight        int
+	CurQueue      int
+	CurSessions   int
+	TotalSessions int
+	// Fields has all the fields of the line, indexed by the column names.
+	Fields map[string]string
+}
+
+// ShowStatCmd creates a `show stat` command whose response is parsed into stats.
+func ShowStatCmd(stats *[]Stat) *Cmd {
+	cmd := "show stat"
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		header, found := strings.CutPrefix(response, "# ")
+		if !found {
+			return unexpectedResponse(cmd, response)
+		}
+		r := csv.NewReader(strings.NewReader(header))
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		records, err := r.ReadAll()
+		if err != nil || len(records) == 0 {
+			return unexpectedResponse(cmd, response)
+		}
+		columns := records[0]
+		result := make([]Stat, 0, len(records)-1)
+		for _, record := range records[1:] {
+			fields := make(map[string]string, len(columns))
+			for i, value := range record {
+				if i < len(columns) && columns[i] != "" {
+					fields[columns[i]] = value
+				}
+			}
+			result = append(result, Stat{
+				Proxy:         fields["pxname"],
+				Name:          fields["svname"],
+				Type:          StatType(atoi(fields["type"])),
+				Status:        fields["status"],
+				Weight:        atoi(fields["weight"]),
+				CurQueue:      atoi(fields["qcur"]),
+				CurSessions:   atoi(fields["scur"]),
+				TotalSessions: atoi(fields["stot"]),
+				Fields:        fields,
+			})
+		}
+		*stats = result
+		return nil
+	}}
+}
+
+// ShowStat reads the statistics of all proxies and servers.
+func (c *Client) ShowStat() ([]Stat, error) {
+	var stats []Stat
+	if err := c.exec(ShowStatCmd(&stats)); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ServerState is a line of the `show servers state` command.
+type ServerState struct {
+	BackendID     int
+	Backend       string
+	ServerID      int
+	Server        string
+	Addr          string
+	Port          int
+	OpState       int
+	AdminState    int
+	UserWeight    int
+	InitialWeight int
+	CheckPort     int
+	CheckAddr     string
+	AgentPort     int
+	AgentAddr     string
+	// Fields has all the fields of the line, indexed by the column names.
+	// Empty fields are represented by a dash.
+	Fields map[string]string
+}
+
+// ShowServersStateCmd creates a `show servers state` command whose response is parsed
+// into states. The state of all the backends is read if backend is empty.
+func ShowServersStateCmd(backend string, states *[]ServerState) *Cmd {
+	cmd := "show servers state"
+	if backend != "" {
+		cmd += " " + backend
+	}
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		lines := utils.LineToSlice(response)
+		// first line is the version of the format, followed by the header
+		if len(lines) < 2 || !strings.HasPrefix(lines[1], "# ") {
+			return unexpectedResponse(cmd, response)
+		}
+		columns := strings.Fields(strings.TrimPrefix(lines[1], "# "))
+		result := make([]ServerState, 0, len(lines)-2)
+		for _, line := range lines[2:] {
+			values := strings.Fields(line)
+			if len(values) == 0 {
+				continue
+			}
+			fields := make(map[string]string, len(columns))
+			for i, value := range values {
+				if i < len(columns) {
+					fields[columns[i]] = value
+				}
+			}
+			value := func(name string) string {
+				if v := fields[name]; v != "-" {
+					return v
+				}
+				return ""
+			}
+			result = append(result, ServerState{
+				BackendID:     atoi(value("be_id")),
+				Backend:       value("be_name"),
+				ServerID:      atoi(value("srv_id")),
+				Server:        value("srv_name"),
+				Addr:          value("srv_addr"),
+				Port:          atoi(value("srv_port")),
+				OpState:       atoi(value("srv_op_state")),
+				AdminState:    atoi(value("srv_admin_state")),
+				UserWeight:    atoi(value("srv_uweight")),
+				InitialWeight: atoi(value("srv_iweight")),
+				CheckPort:     atoi(value("srv_check_port")),
+				CheckAddr:     value("srv_check_addr"),
+				AgentPort:     atoi(value("srv_agent_port")),
+				AgentAddr:     value("srv_agent_addr"),
+				Fields:        fields,
+			})
+		}
+		*states = result
+		return nil
+	}}
+}
+
+// ShowServersState reads the state of the servers of a backend, or of all the backends if backend is empty.
+func (c *Client) ShowServersState(backend string) ([]ServerState, error) {
+	var states []ServerState
+	if err := c.exec(ShowServersStateCmd(backend, &states)); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// ShowSessCmd creates a `show sess` command whose response is parsed into the
+// list of the IDs of the sessions.
+func ShowSessCmd(sessions *[]string) *Cmd {
+	cmd := "show sess"
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		// 0x7f9440810000: proto=unix_stream src=...
+		// 0x7f943f87f200: proto=unix_stream src=...
+		var result []string
+		for _, line := range utils.LineToSlice(response) {
+			if i := strings.Index(line, ":"); i > 0 {
+				result = append(result, line[:i])
+			}
+		}
+		*sessions = result
+		return nil
+	}}
+}
+
+// ShowSess reads the IDs of the active sessions.
+func (c *Client) ShowSess() ([]string, error) {
+	var sessions []string
+	if err := c.exec(ShowSessCmd(&sessions)); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ShutdownSessionCmd creates a `shutdown session <id>` command.
+func ShutdownSessionCmd(id string) *Cmd {
+	cmd := "shutdown session " + id
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// ShutdownSession closes the session id, see ShowSess().
+func (c *Client) ShutdownSession(id string) error {
+	return c.exec(ShutdownSessionCmd(id))
+}
+
+// SSLCert is the output of the `show ssl cert <name>` command.
+type SSLCert struct {
+	Filename        string
+	Status          string
+	Serial          string
+	NotBefore       time.Time
+	NotAfter        time.Time
+	SAN             []string
+	Algorithm       string
+	SHA1Fingerprint string
+	Subject         string
+	Issuer          string
+	// Fields has all the fields of the response, indexed by their names.
+	Fields map[string]string
+}
+
+// sslCertTimeLayout is the date format of notBefore and notAfter
+const sslCertTimeLayout = "Jan _2 15:04:05 2006 MST"
+
+// ShowSSLCertCmd creates a `show ssl cert <name>` command whose response is parsed into cert.
+func ShowSSLCertCmd(name string, cert *SSLCert) *Cmd {
+	cmd := "show ssl cert " + name
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		fields := parseKeyValue(response)
+		if fields["Filename"] == "" {
+			return unexpectedResponse(cmd, response)
+		}
+		*cert = SSLCert{
+			Filename:        fields["Filename"],
+			Status:          fields["Status"],
+			Serial:          fields["Serial"],
+			Algorithm:       fields["Algorithm"],
+			SHA1Fingerprint: fields["SHA1 FingerPrint"],
+			Subject:         fields["Subject"],
+			Issuer:          fields["Issuer"],
+			Fields:          fields,
+		}
+		cert.NotBefore = parseSSLCertTime(fields["notBefore"])
+		cert.NotAfter = parseSSLCertTime(fields["notAfter"])
+		if san := fields["Subject Alternative Name"]; san != "" {
+			cert.SAN = strings.Split(san, ", ")
+		}
+		return nil
+	}}
+}
+
+func parseSSLCertTime(value string) time.Time {
+	t, err := time.Parse(sslCertTimeLayout, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// ShowSSLCert reads the details of a certificate stored in haproxy.
+func (c *Client) ShowSSLCert(name string) (*SSLCert, error) {
+	cert := &SSLCert{}
+	if err := c.exec(ShowSSLCertCmd(name, cert)); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// SetServerCmd creates a `set server <backend>/<server> <args>` command, eg args
+// `state`, `maint` changes the state of the server to maintenance.
+func SetServerCmd(backend, server string, args ...string) *Cmd {
+	cmd := fmt.Sprintf("set server %s/%s %s", backend, server, strings.Join(args, " "))
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		// the response is empty on most of the parameters, addr and ports
+		// respond with the changes made, or that nothing needed to be changed.
+		if response == "" ||
+			strings.Contains(response, " changed ") ||
+			strings.HasPrefix(response, "no need to change ") ||
+			strings.Contains(response, " updated") {
+			return nil
+		}
+		return unexpectedResponse(cmd, response)
+	}}
+}
+
+// SetServer changes a parameter of a server, see SetServerCmd().
+func (c *Client) SetServer(backend, server string, args ...string) error {
+	return c.exec(SetServerCmd(backend, server, args...))
+}
+
+// AddServerCmd creates an `add server <backend>/<server> <addr> <options>` command.
+// Servers are added in maintenance mode, and should be enabled via SetServerCmd().
+func AddServerCmd(backend, server, addr string, options ...string) *Cmd {
+	cmd := strings.Join(append([]string{fmt.Sprintf("add server %s/%s %s", backend, server, addr)}, options...), " ")
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		if !strings.Contains(response, "New server registered") {
+			return unexpectedResponse(cmd, response)
+		}
+		return nil
+	}}
+}
+
+// AddServer adds a new server to a backend, see AddServerCmd().
+func (c *Client) AddServer(backend, server, addr string, options ...string) error {
+	return c.exec(AddServerCmd(backend, server, addr, options...))
+}
+
+// DelServerCmd creates a `del server <backend>/<server>` command. haproxy refuses
+// to remove servers that are not in maintenance mode or have active connections.
+func DelServerCmd(backend, server string) *Cmd {
+	cmd := fmt.Sprintf("del server %s/%s", backend, server)
+	return newCmd(cmd, cmd, responseContains("Server deleted"))
+}
+
+// DelServer removes a server from a backend, see DelServerCmd().
+func (c *Client) DelServer(backend, server string) error {
+	return c.exec(DelServerCmd(backend, server))
+}
+
+// SetMaxConnServerCmd creates a `set maxconn server <backend>/<server> <maxconn>` command.
+func SetMaxConnServerCmd(backend, server string, maxconn int) *Cmd {
+	cmd := fmt.Sprintf("set maxconn server %s/%s %d", backend, server, maxconn)
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// EnableHealthCmd creates an `enable health <backend>/<server>` command.
+func EnableHealthCmd(backend, server string) *Cmd {
+	cmd := fmt.Sprintf("enable health %s/%s", backend, server)
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// EnableAgentCmd creates an `enable agent <backend>/<server>` command.
+func EnableAgentCmd(backend, server string) *Cmd {
+	cmd := fmt.Sprintf("enable agent %s/%s", backend, server)
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// MapEntry is a line of the `show map <map>` command.
+type MapEntry struct {
+	ID    string
+	Key   string
+	Value string
+}
+
+// ShowMapCmd creates a `show map <map>` command whose response is parsed into entries.
+// mapName is either the map filename or its ID prefixed by `#`.
+func ShowMapCmd(mapName string, entries *[]MapEntry) *Cmd {
+	cmd := "show map " + mapName
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		var result []MapEntry
+		for _, line := range utils.LineToSlice(response) {
+			if line == "" {
+				continue
+			}
+			// value might have spaces, id and key don't
+			values := strings.SplitN(line, " ", 3)
+			if len(values) < 3 || !strings.HasPrefix(values[0], "0x") {
+				return unexpectedResponse(cmd, response)
+			}
+			result = append(result, MapEntry{ID: values[0], Key: values[1], Value: values[2]})
+		}
+		*entries = result
+		return nil
+	}}
+}
+
+// ShowMap reads the entries of a map.
+func (c *Client) ShowMap(mapName string) ([]MapEntry, error) {
+	var entries []MapEntry
+	if err := c.exec(ShowMapCmd(mapName, &entries)); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddMapCmd creates an `add map <map> <key> <value>` command.
+func AddMapCmd(mapName, key, value string) *Cmd {
+	cmd := fmt.Sprintf("add map %s %s %s", mapName, key, value)
+	return &Cmd{cmd: cmd, parse: func(response string) error {
+		if err := responseError(cmd, response); err != nil {
+			return err
+		}
+		if response != "" {
+			return unexpectedResponse(cmd, response)
+		}
+		return nil
+	}}
+}
+
+// AddMap adds a new entry to a map. Entries are not deduplicated, a key is added
+// again if it already exists, use `set map` to change its value.
+func (c *Client) AddMap(mapName, key, value string) error {
+	return c.exec(AddMapCmd(mapName, key, value))
+}
+
+// DelMapCmd creates a `del map <map> <key>` command.
+func DelMapCmd(mapName, key string) *Cmd {
+	cmd := fmt.Sprintf("del map %s %s", mapName, key)
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// SetMapCmd creates a `set map <map> <key> <value>` command.
+func SetMapCmd(mapName, key, value string) *Cmd {
+	cmd := fmt.Sprintf("set map %s %s %s", mapName, key, value)
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// AddACLCmd creates an `add acl <acl> <pattern>` command.
+func AddACLCmd(aclName, pattern string) *Cmd {
+	cmd := fmt.Sprintf("add acl %s %s", aclName, pattern)
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// DelACLCmd creates a `del acl <acl> <pattern>` command.
+func DelACLCmd(aclName, pattern string) *Cmd {
+	cmd := fmt.Sprintf("del acl %s %s", aclName, pattern)
+	return newCmd(cmd, cmd, emptyResponse)
+}
+
+// NewSSLCertCmd creates a `new ssl cert <name>` command, which creates an empty
+// certificate store, filled via SetSSLCertCmd() and CommitSSLCertCmd().
+func NewSSLCertCmd(name string) *Cmd {
+	cmd := "new ssl cert " + name
+	return newCmd(cmd, cmd, responseContains("New empty certificate store"))
+}
+
+// SetSSLCertCmd creates a `set ssl cert <name>` command, which starts a transaction
+// that updates the certificate with payload, applied by CommitSSLCertCmd().
+func SetSSLCertCmd(name, payload string) *Cmd {
+	name = "set ssl cert " + name
+	return newCmd(fmt.Sprintf("%s <<\n%s\n", name, payload), name, responseContains("Transaction created", "Transaction updated"))
+}
+
+// CommitSSLCertCmd creates a `commit ssl cert <name>` command.
+func CommitSSLCertCmd(name string) *Cmd {
+	cmd := "commit ssl cert " + name
+	return newCmd(cmd, cmd, responseContains("Success"))
+}
+
+// DelSSLCertCmd creates a `del ssl cert <name>` command. Certificates
+// referenced by a crt-list cannot be removed.
+func DelSSLCertCmd(name string) *Cmd {
+	cmd := "del ssl cert " + name
+	return newCmd(cmd, cmd, responseContains("deleted"))
+}
+
+// AddSSLCrtListCmd creates an `add ssl crt-list <crtlist>` command, line is a
+// crt-list entry: the certificate filename followed by its options.
+func AddSSLCrtListCmd(crtList, line string) *Cmd {
+	name := "add ssl crt-list " + crtList
+	return newCmd(fmt.Sprintf("%s <<\n%s\n", name, line), name, responseContains("Success"))
+}
+
+// DelSSLCrtListCmd creates a `del ssl crt-list <crtlist> <crtfile>` command.
+func DelSSLCrtListCmd(crtList, crtFile string) *Cmd {
+	cmd := fmt.Sprintf("del ssl crt-list %s %s", crtList, crtFile)
+	return newCmd(cmd, cmd, responseContains("deleted in crtlist"))
+}
+
+// SetSSLFileCmd creates a `set ssl <kind> <filename>` command, kind is either
+// `ca-file` or `crl-file`. It starts a transaction that updates the file with
+// payload, applied by CommitSSLFileCmd().
+func SetSSLFileCmd(kind, filename, payload string) *Cmd {
+	name := fmt.Sprintf("set ssl %s %s", kind, filename)
+	return newCmd(fmt.Sprintf("%s <<\n%s\n", name, payload), name, responseContains("Transaction created", "Transaction updated"))
+}
+
+// CommitSSLFileCmd creates a `commit ssl <kind> <filename>` command, see SetSSLFileCmd().
+func CommitSSLFileCmd(kind, filename string) *Cmd {
+	cmd := fmt.Sprintf("commit ssl %s %s", kind, filename)
+	return newCmd(cmd, cmd, responseContains("Success"))
+}
+
+// parseKeyValue parses responses in the `<key>: <value>` format, one per line.
+func parseKeyValue(response string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range utils.LineToSlice(response) {
+		if key, value, found := strings.Cut(line, ":"); found && key != "" {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
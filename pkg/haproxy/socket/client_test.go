@@ -0,0 +1,547 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package socket
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShowInfo(t *testing.T) {
+	testCases := []struct {
+		response string
+		expInfo  *Info
+		expError error
+	}{
+		// 0 - haproxy 2.4
+		{
+			response: `Name: HAProxy
+Version: 2.4.22-f8e3218
+Release_date: 2023/02/14
+Nbthread: 4
+Nbproc: 1
+Process_num: 1
+Pid: 7
+Uptime: 0d 0h02m11s
+Uptime_sec: 131
+Memmax_MB: 0
+Ulimit-n: 4031
+Maxsock: 4031
+Maxconn: 2000
+Hard_maxconn: 2000
+CurrConns: 1
+CumConns: 12
+Idle_pct: 98
+node: ingress-7d9f8
+`,
+			expInfo: &Info{
+				Name:      "HAProxy",
+				Version:   "2.4.22-f8e3218",
+				Major:     2,
+				Minor:     4,
+				PID:       7,
+				Nbthread:  4,
+				Uptime:    131 * time.Second,
+				MaxConn:   2000,
+				CurrConns: 1,
+				IdlePct:   98,
+			},
+		},
+		// 1 - haproxy 3.0
+		{
+			response: `Name: HAProxy
+Version: 3.0.5-8e879a5
+Release_date: 2024/09/19
+Nbthread: 8
+Nbproc: 1
+Process_num: 1
+Pid: 21
+Uptime: 1d 2h00m00s
+Uptime_sec: 93600
+Memmax_MB: 0
+Maxconn: 4000
+Hard_maxconn: 4000
+CurrConns: 35
+Idle_pct: 71
+`,
+			expInfo: &Info{
+				Name:      "HAProxy",
+				Version:   "3.0.5-8e879a5",
+				Major:     3,
+				Minor:     0,
+				PID:       21,
+				Nbthread:  8,
+				Uptime:    26 * time.Hour,
+				MaxConn:   4000,
+				CurrConns: 35,
+				IdlePct:   71,
+			},
+		},
+		// 2 - dev version
+		{
+			response: "Name: HAProxy\nVersion: 3.1-dev5-7d2ba89\n",
+			expInfo: &Info{
+				Name:    "HAProxy",
+				Version: "3.1-dev5-7d2ba89",
+				Major:   3,
+				Minor:   1,
+			},
+		},
+		// 3
+		{
+			response: "Permission denied\n",
+			expError: ErrPermissionDenied,
+		},
+		// 4
+		{
+			response: "",
+			expError: ErrUnexpectedResponse,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		cli := &clientMock{responses: map[string]string{"show info": test.response}}
+		info, err := NewClient(cli, nil).ShowInfo()
+		if info != nil {
+			if info.Fields["Name"] != "HAProxy" {
+				t.Errorf("field Name differs on %d: %s", i, info.Fields["Name"])
+			}
+			info.Fields = nil
+		}
+		c.compareObjects("info", i, info, test.expInfo)
+		c.compareError(i, err, test.expError)
+		c.tearDown()
+	}
+}
+
+func TestShowStat(t *testing.T) {
+	// columns after check_duration were removed
+	response := `# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,dreq,dresp,ereq,econ,eresp,wretr,wredis,status,weight,act,bck,chkfail,chkdown,lastchg,downtime,qlimit,pid,iid,sid,throttle,lbtot,tracked,type,rate,rate_lim,rate_max,check_status,check_code,check_duration,
+_front_http,FRONTEND,,,1,3,2000,12,1234,5678,0,0,0,,,,,OPEN,,,,,,,,,1,2,0,,,,0,0,0,2,,,,
+default_app_8080,srv001,0,0,2,4,,10,900,4500,,0,,0,0,0,0,UP,1,1,0,0,0,131,0,,1,3,1,,10,,2,0,,2,L4OK,,0,
+default_app_8080,srv002,0,0,0,0,,0,0,0,,0,,0,0,0,0,MAINT,1,1,0,0,0,131,131,,1,3,2,,0,,2,0,,0,* L4CON,,0,
+default_app_8080,BACKEND,0,0,2,4,200,10,900,4500,0,0,,0,0,0,0,UP,1,1,0,,0,131,0,,1,3,0,,10,,1,0,,2,,,,
+`
+	c := setup(t)
+	cli := &clientMock{responses: map[string]string{"show stat": response}}
+	stats, err := NewClient(cli, nil).ShowStat()
+	c.compareError(0, err, nil)
+	if len(stats) == 4 && stats[2].Fields["check_status"] != "* L4CON" {
+		t.Errorf("field check_status differs: %s", stats[2].Fields["check_status"])
+	}
+	for i := range stats {
+		stats[i].Fields = nil
+	}
+	c.compareObjects("stats", 0, stats, []Stat{
+		{Proxy: "_front_http", Name: "FRONTEND", Type: StatFrontend, Status: "OPEN", CurSessions: 1, TotalSessions: 12},
+		{Proxy: "default_app_8080", Name: "srv001", Type: StatServer, Status: "UP", Weight: 1, CurSessions: 2, TotalSessions: 10},
+		{Proxy: "default_app_8080", Name: "srv002", Type: StatServer, Status: "MAINT", Weight: 1},
+		{Proxy: "default_app_8080", Name: "BACKEND", Type: StatBackend, Status: "UP", Weight: 1, CurSessions: 2, TotalSessions: 10},
+	})
+	c.tearDown()
+}
+
+func TestShowServersState(t *testing.T) {
+	testCases := []struct {
+		backend   string
+		response  string
+		expCmd    string
+		expStates []ServerState
+		expError  error
+	}{
+		// 0 - haproxy 2.4+
+		{
+			response: `1
+# be_id be_name srv_id srv_name srv_addr srv_op_state srv_admin_state srv_uweight srv_iweight srv_time_since_last_change srv_check_status srv_check_result srv_check_health srv_check_state srv_agent_state bk_f_forced_id srv_f_forced_id srv_fqdn srv_port srvrecord srv_use_ssl srv_check_port srv_check_addr srv_agent_addr srv_agent_port
+3 default_app_8080 1 srv001 172.17.0.11 2 0 1 1 131 6 3 4 6 0 0 0 - 8080 - 0 8081 - 172.17.0.100 5555
+3 default_app_8080 2 srv002 127.0.0.1 0 5 1 1 131 1 0 0 14 0 0 0 - 8080 - 0 0 - - 0
+`,
+			expCmd: "show servers state",
+			expStates: []ServerState{
+				{BackendID: 3, Backend: "default_app_8080", ServerID: 1, Server: "srv001", Addr: "172.17.0.11", Port: 8080, OpState: 2, UserWeight: 1, InitialWeight: 1, CheckPort: 8081, AgentAddr: "172.17.0.100", AgentPort: 5555},
+				{BackendID: 3, Backend: "default_app_8080", ServerID: 2, Server: "srv002", Addr: "127.0.0.1", Port: 8080, AdminState: 5, UserWeight: 1, InitialWeight: 1},
+			},
+		},
+		// 1 - haproxy 2.0, without ssl, check and agent columns
+		{
+			backend: "default_app_8080",
+			response: `1
+# be_id be_name srv_id srv_name srv_addr srv_op_state srv_admin_state srv_uweight srv_iweight srv_time_since_last_change srv_check_status srv_check_result srv_check_health srv_check_state srv_agent_state bk_f_forced_id srv_f_forced_id srv_fqdn srv_port srvrecord
+3 default_app_8080 1 srv001 172.17.0.11 2 0 1 1 131 6 3 4 6 0 0 0 - 8080 -
+`,
+			expCmd: "show servers state default_app_8080",
+			expStates: []ServerState{
+				{BackendID: 3, Backend: "default_app_8080", ServerID: 1, Server: "srv001", Addr: "172.17.0.11", Port: 8080, OpState: 2, UserWeight: 1, InitialWeight: 1},
+			},
+		},
+		// 2
+		{
+			backend:  "default_none_8080",
+			response: "Can't find backend.\n",
+			expCmd:   "show servers state default_none_8080",
+			expError: ErrNotFound,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		cli := &clientMock{responses: map[string]string{test.expCmd: test.response}}
+		states, err := NewClient(cli, nil).ShowServersState(test.backend)
+		for j := range states {
+			states[j].Fields = nil
+		}
+		c.compareObjects("states", i, states, test.expStates)
+		c.compareError(i, err, test.expError)
+		c.tearDown()
+	}
+}
+
+func TestShowSSLCert(t *testing.T) {
+	testCases := []struct {
+		response string
+		expCert  *SSLCert
+		expError error
+	}{
+		// 0 - haproxy 2.4
+		{
+			response: `Filename: /var/lib/haproxy/crt/default_domain.pem
+Status: Used
+Serial: 1F5202E02083861B302FFA09045721F07C865EFD
+notBefore: Aug  2 17:05:34 2024 GMT
+notAfter: Aug  2 17:05:34 2025 GMT
+Subject Alternative Name: DNS:domain.local, DNS:www.domain.local
+Algorithm: RSA2048
+SHA1 FingerPrint: C1BD5BD9D0D6E2FB8D4FC0CB5E7B4D8DB8A1DE2A
+Subject: /CN=domain.local
+Issuer: /CN=Fake CA
+`,
+			expCert: &SSLCert{
+				Filename:        "/var/lib/haproxy/crt/default_domain.pem",
+				Status:          "Used",
+				Serial:          "1F5202E02083861B302FFA09045721F07C865EFD",
+				NotBefore:       time.Date(2024, 8, 2, 17, 5, 34, 0, time.UTC),
+				NotAfter:        time.Date(2025, 8, 2, 17, 5, 34, 0, time.UTC),
+				SAN:             []string{"DNS:domain.local", "DNS:www.domain.local"},
+				Algorithm:       "RSA2048",
+				SHA1Fingerprint: "C1BD5BD9D0D6E2FB8D4FC0CB5E7B4D8DB8A1DE2A",
+				Subject:         "/CN=domain.local",
+				Issuer:          "/CN=Fake CA",
+			},
+		},
+		// 1 - haproxy 2.8, uncommitted transaction
+		{
+			response: `Filename: */var/lib/haproxy/crt/default_domain.pem
+Status: Unused
+Serial: 0A
+notBefore: Dec 10 09:00:00 2024 GMT
+notAfter: Mar 10 09:00:00 2025 GMT
+Algorithm: EC256
+SHA1 FingerPrint: 0AE2E6C2A0E0F8F4B1D0A4B1E2F6A8D4C0B2E1F3
+Subject: /CN=domain.local
+Issuer: /CN=Fake CA
+OCSP Response Key: 303b300906052b0e03021a05000414
+`,
+			expCert: &SSLCert{
+				Filename:        "*/var/lib/haproxy/crt/default_domain.pem",
+				Status:          "Unused",
+				Serial:          "0A",
+				NotBefore:       time.Date(2024, 12, 10, 9, 0, 0, 0, time.UTC),
+				NotAfter:        time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+				Algorithm:       "EC256",
+				SHA1Fingerprint: "0AE2E6C2A0E0F8F4B1D0A4B1E2F6A8D4C0B2E1F3",
+				Subject:         "/CN=domain.local",
+				Issuer:          "/CN=Fake CA",
+			},
+		},
+		// 2
+		{
+			response: "Can't display the certificate: Not found or the certificate is a bundle!\n",
+			expError: ErrNotFound,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		cli := &clientMock{responses: map[string]string{"show ssl cert /var/lib/haproxy/crt/default_domain.pem": test.response}}
+		cert, err := NewClient(cli, nil).ShowSSLCert("/var/lib/haproxy/crt/default_domain.pem")
+		if cert != nil {
+			cert.Fields = nil
+		}
+		c.compareObjects("cert", i, cert, test.expCert)
+		c.compareError(i, err, test.expError)
+		c.tearDown()
+	}
+}
+
+func TestShowMap(t *testing.T) {
+	testCases := []struct {
+		response   string
+		expEntries []MapEntry
+		expError   error
+	}{
+		// 0
+		{
+			response: `0x55d8e2d10f30 domain.local#/ default_app_8080
+0x55d8e2d10fb0 domain.local#/api default_api_8080
+`,
+			expEntries: []MapEntry{
+				{ID: "0x55d8e2d10f30", Key: "domain.local#/", Value: "default_app_8080"},
+				{ID: "0x55d8e2d10fb0", Key: "domain.local#/api", Value: "default_api_8080"},
+			},
+		},
+		// 1
+		{
+			response: "",
+		},
+		// 2
+		{
+			response: "Unknown map identifier. Please use #<id> or <file>.\n",
+			expError: ErrNotFound,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		cli := &clientMock{responses: map[string]string{"show map /etc/haproxy/maps/_front_http_host__begin.map": test.response}}
+		entries, err := NewClient(cli, nil).ShowMap("/etc/haproxy/maps/_front_http_host__begin.map")
+		c.compareObjects("entries", i, entries, test.expEntries)
+		c.compareError(i, err, test.expError)
+		c.tearDown()
+	}
+}
+
+func TestShowSess(t *testing.T) {
+	testCases := []struct {
+		response    string
+		expSessions []string
+		expError    error
+	}{
+		// 0
+		{
+			response: `0x7f9440810000: proto=unix_stream src=unix:1 fe=GLOBAL be=<NONE> srv=<none> ts=00 epoch=0x3
+0x7f943f87f200: proto=tcpv4 src=172.17.0.1:52018 fe=_front_http be=default_app_8080 srv=srv001 ts=00 epoch=0x2
+`,
+			expSessions: []string{"0x7f9440810000", "0x7f943f87f200"},
+		},
+		// 1
+		{
+			response: "Permission denied\n",
+			expError: ErrPermissionDenied,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		cli := &clientMock{responses: map[string]string{"show sess": test.response}}
+		sessions, err := NewClient(cli, nil).ShowSess()
+		c.compareObjects("sessions", i, sessions, test.expSessions)
+		c.compareError(i, err, test.expError)
+		c.tearDown()
+	}
+}
+
+func TestChangeCommands(t *testing.T) {
+	testCases := []struct {
+		exec     func(cli *Client) error
+		expCmd   string
+		response string
+		expError error
+	}{
+		// 0
+		{
+			exec:     func(cli *Client) error { return cli.SetServer("default_app_8080", "srv001", "state", "ready") },
+			expCmd:   "set server default_app_8080/srv001 state ready",
+			response: "",
+		},
+		// 1
+		{
+			exec: func(cli *Client) error {
+				return cli.SetServer("default_app_8080", "srv001", "addr", "172.17.0.12", "port", "8080")
+			},
+			expCmd:   "set server default_app_8080/srv001 addr 172.17.0.12 port 8080",
+			response: "IP changed from '172.17.0.11' to '172.17.0.12', no need to change the port by 'stats socket command'\n",
+		},
+		// 2
+		{
+			exec:     func(cli *Client) error { return cli.SetServer("default_app_8080", "srv001", "check-port", "8081") },
+			expCmd:   "set server default_app_8080/srv001 check-port 8081",
+			response: "health check port updated.\n",
+		},
+		// 3
+		{
+			exec:     func(cli *Client) error { return cli.SetServer("default_app_8080", "srv009", "state", "ready") },
+			expCmd:   "set server default_app_8080/srv009 state ready",
+			response: "No such server.\n",
+			expError: ErrNotFound,
+		},
+		// 4
+		{
+			exec:     func(cli *Client) error { return cli.SetServer("default_app_8080", "srv001", "weight", "-1") },
+			expCmd:   "set server default_app_8080/srv001 weight -1",
+			response: "Relative weight must be positive.\n",
+			expError: ErrUnexpectedResponse,
+		},
+		// 5
+		{
+			exec: func(cli *Client) error {
+				return cli.AddServer("default_app_8080", "srv003", "172.17.0.13:8080", "weight", "1")
+			},
+			expCmd:   "add server default_app_8080/srv003 172.17.0.13:8080 weight 1",
+			response: "New server registered.\n",
+		},
+		// 6
+		{
+			exec:     func(cli *Client) error { return cli.AddServer("default_app_8080", "srv001", "172.17.0.13:8080") },
+			expCmd:   "add server default_app_8080/srv001 172.17.0.13:8080",
+			response: "Already exists a server with the same name in backend.\n",
+			expError: ErrAlreadyExists,
+		},
+		// 7 - haproxy 2.4
+		{
+			exec:     func(cli *Client) error { return cli.AddServer("default_app_8080", "srv003", "172.17.0.13:8080") },
+			expCmd:   "add server default_app_8080/srv003 172.17.0.13:8080",
+			response: "Unknown command. Please enter one of the following commands only :\n  help           : this message\n  prompt         : toggle interactive mode with prompt\n",
+			expError: ErrUnknownCommand,
+		},
+		// 8 - haproxy 2.6+
+		{
+			exec:     func(cli *Client) error { return cli.AddServer("default_app_8080", "srv003", "172.17.0.13:8080") },
+			expCmd:   "add server default_app_8080/srv003 172.17.0.13:8080",
+			response: "Unknown command: 'add server'. Please enter one of the following commands only :\n  help [<command>]                        : list matching or all commands\n",
+			expError: ErrUnknownCommand,
+		},
+		// 9
+		{
+			exec:     func(cli *Client) error { return cli.AddMap("#3", "domain.local#/", "default_app_8080") },
+			expCmd:   "add map #3 domain.local#/ default_app_8080",
+			response: "",
+		},
+		// 10
+		{
+			exec:     func(cli *Client) error { return cli.AddMap("#3", "domain.local#/", "default_app_8080") },
+			expCmd:   "add map #3 domain.local#/ default_app_8080",
+			response: "Permission denied\n",
+			expError: ErrPermissionDenied,
+		},
+		// 11
+		{
+			exec:     func(cli *Client) error { return cli.DelServer("default_app_8080", "srv003") },
+			expCmd:   "del server default_app_8080/srv003",
+			response: "Server deleted.\n",
+		},
+		// 12
+		{
+			exec:     func(cli *Client) error { return cli.DelServer("default_app_8080", "srv003") },
+			expCmd:   "del server default_app_8080/srv003",
+			response: "Only servers in maintenance mode can be deleted.\n",
+			expError: ErrUnexpectedResponse,
+		},
+		// 13
+		{
+			exec:     func(cli *Client) error { return cli.ShutdownSession("0x7f943f87f200") },
+			expCmd:   "shutdown session 0x7f943f87f200",
+			response: "",
+		},
+		// 14
+		{
+			exec: func(cli *Client) error {
+				return cli.exec(SetSSLCertCmd("/var/lib/crt.pem", "-----BEGIN CERTIFICATE-----"))
+			},
+			expCmd:   "set ssl cert /var/lib/crt.pem <<\n-----BEGIN CERTIFICATE-----\n",
+			response: "Transaction created for certificate /var/lib/crt.pem!\n",
+		},
+		// 15
+		{
+			exec:     func(cli *Client) error { return cli.exec(CommitSSLFileCmd("ca-file", "/var/lib/ca.pem")) },
+			expCmd:   "commit ssl ca-file /var/lib/ca.pem",
+			response: "Committing /var/lib/ca.pem\nunable to commit: invalid CA\n",
+			expError: ErrUnexpectedResponse,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		cli := &clientMock{responses: map[string]string{test.expCmd: test.response}}
+		err := test.exec(NewClient(cli, nil))
+		c.compareObjects("commands", i, cli.commands, [][]string{{test.expCmd}})
+		c.compareError(i, err, test.expError)
+		c.tearDown()
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	c := setup(t)
+	defer c.tearDown()
+
+	cli := &clientMock{responses: map[string]string{
+		"show info":   "Name: HAProxy\nVersion: 2.8.10-ec17bb0\n",
+		"show map #3": "0x55d8e2d10f30 domain.local#/ default_app_8080\n",
+		"set server default_app_8080/srv009 state ready": "No such server.\n",
+		"add map #3 domain.local#/api default_api_8080":  "",
+	}}
+	client := NewClient(cli, nil)
+	var info Info
+	var entries []MapEntry
+	cmds := []*Cmd{
+		ShowInfoCmd(&info),
+		ShowMapCmd("#3", &entries),
+		SetServerCmd("default_app_8080", "srv009", "state", "ready"),
+		AddMapCmd("#3", "domain.local#/api", "default_api_8080"),
+	}
+	err := client.Pipeline(cmds...)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, but was: %v", err)
+	}
+	c.compareObjects("commands", 0, cli.commands, [][]string{{
+		"show info",
+		"show map #3",
+		"set server default_app_8080/srv009 state ready",
+		"add map #3 domain.local#/api default_api_8080",
+	}})
+	c.compareObjects("version", 0, fmt.Sprintf("%d.%d", info.Major, info.Minor), "2.8")
+	c.compareObjects("entries", 0, entries, []MapEntry{{ID: "0x55d8e2d10f30", Key: "domain.local#/", Value: "default_app_8080"}})
+	c.compareObjects("errors", 0, []bool{cmds[0].Err == nil, cmds[1].Err == nil, cmds[2].Err == nil, cmds[3].Err == nil}, []bool{true, true, false, true})
+	c.compareObjects("response", 0, cmds[2].Response, "No such server.\n")
+
+	// payloads are not logged in the errors
+	cli = &clientMock{responses: map[string]string{"set ssl cert /var/lib/crt.pem <<\nprivate\n": "Permission denied\n"}}
+	cmds = []*Cmd{SetSSLCertCmd("/var/lib/crt.pem", "private")}
+	err = NewClient(cli, nil).Pipeline(cmds...)
+	if !errors.Is(err, ErrPermissionDenied) || strings.Contains(err.Error(), "private") {
+		t.Errorf("expected permission denied without the payload, but was: %v", err)
+	}
+
+	// commands not sent due to a connection failure
+	cli = &clientMock{cmdError: syscall.ECONNREFUSED}
+	cmds = []*Cmd{ShowInfoCmd(&info)}
+	err = NewClient(cli, nil).Pipeline(cmds...)
+	if !errors.Is(err, syscall.ECONNREFUSED) || !errors.Is(cmds[0].Err, syscall.ECONNREFUSED) {
+		t.Errorf("expected connection refused, but was: %v / %v", err, cmds[0].Err)
+	}
+}
+
+func (c *testConfig) compareObjects(name string, index int, actual, expected any) {
+	if !reflect.DeepEqual(actual, expected) {
+		c.t.Errorf("%s differs on %d - expected: %+v, actual: %+v", name, index, expected, actual)
+	}
+}
+
+func (c *testConfig) compareError(index int, actual, expected error) {
+	if expected == nil && actual != nil || !errors.Is(actual, expected) {
+		c.t.Errorf("error differs on %d - expected: %v, actual: %v", index, expected, actual)
+	}
+}
@@ -226,6 +226,36 @@ func (s *sock) newConn() (net.Conn, error) {
 	return s.acquireConn()
 }
 
+// HAProxyVersion reads the major and minor version numbers of a running HAProxy
+// instance from the `show info` command.
+func HAProxyVersion(sock HAProxySocket) (major, minor int, err error) {
+	info, err := NewClient(sock, nil).ShowInfo()
+	if err != nil {
+		return 0, 0, err
+	}
+	if info.Version == "" {
+		return 0, 0, fmt.Errorf("version not found in the show info response")
+	}
+	return ParseVersion(info.Version)
+}
+
+// ParseVersion reads the major and minor numbers of a haproxy version, eg 2.8.10-ec17bb0
+func ParseVersion(version string) (major, minor int, err error) {
+	v := strings.SplitN(version, ".", 3)
+	if len(v) < 2 {
+		return 0, 0, fmt.Errorf("invalid version: %s", version)
+	}
+	if major, err = strconv.Atoi(v[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid version: %s", version)
+	}
+	// minor might have a suffix in dev versions, eg 3.1-dev5
+	m := strings.SplitN(v[1], "-", 2)
+	if minor, err = strconv.Atoi(m[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid version: %s", version)
+	}
+	return major, minor, nil
+}
+
 // ProcTable ...
 type ProcTable struct {
 	Master     Proc
@@ -427,6 +427,65 @@ func TestHAProxyProcs(t *testing.T) {
 	}
 }
 
+func TestHAProxyVersion(t *testing.T) {
+	testCases := []struct {
+		cmdOutput []string
+		cmdError  error
+		expMajor  int
+		expMinor  int
+		expError  bool
+	}{
+		// 0
+		{
+			cmdError: fmt.Errorf("fail"),
+			expError: true,
+		},
+		// 1
+		{
+			cmdOutput: []string{"Name: HAProxy\nVersion: 3.0.5-8e879a5\nRelease_date: 2024/09/19\n"},
+			expMajor:  3,
+			expMinor:  0,
+		},
+		// 2
+		{
+			cmdOutput: []string{"Name: HAProxy\nVersion: 2.4.22-f8e3218\n"},
+			expMajor:  2,
+			expMinor:  4,
+		},
+		// 3
+		{
+			cmdOutput: []string{"Name: HAProxy\nVersion: 3.1-dev5-7d2ba89\n"},
+			expMajor:  3,
+			expMinor:  1,
+		},
+		// 4
+		{
+			cmdOutput: []string{"Name: HAProxy\n"},
+			expError:  true,
+		},
+		// 5
+		{
+			cmdOutput: []string{"Name: HAProxy\nVersion: dev\n"},
+			expError:  true,
+		},
+	}
+	for i, test := range testCases {
+		c := setup(t)
+		cli := &clientMock{
+			cmdOutput: test.cmdOutput,
+			cmdError:  test.cmdError,
+		}
+		major, minor, err := HAProxyVersion(cli)
+		if major != test.expMajor || minor != test.expMinor {
+			t.Errorf("version differs on %d - expected: %d.%d, actual: %d.%d", i, test.expMajor, test.expMinor, major, minor)
+		}
+		if (err != nil) != test.expError {
+			t.Errorf("error differs on %d - expected: %v, actual: %v", i, test.expError, err)
+		}
+		c.tearDown()
+	}
+}
+
 func TestHAProxyProcsLoop(t *testing.T) {
 	testCases := []struct {
 		reload   time.Duration
@@ -493,6 +552,8 @@ type clientMock struct {
 	cmdError  error
 	callCnt   int
 	hasSock   bool
+	responses map[string]string
+	commands  [][]string
 }
 
 func (c *clientMock) Address() string {
@@ -508,6 +569,14 @@ func (c *clientMock) HasConn() bool {
 
 func (c *clientMock) Send(observer func(duration time.Duration), command ...string) ([]string, error) {
 	c.callCnt++
+	c.commands = append(c.commands, command)
+	if c.responses != nil {
+		out := make([]string, len(command))
+		for i, cmd := range command {
+			out[i] = c.responses[cmd]
+		}
+		return out, c.cmdError
+	}
 	return c.cmdOutput, c.cmdError
 }
 
@@ -74,6 +74,21 @@ func (c *Config) WriteOutput(data interface{}, output string) error {
 	return nil
 }
 
+// ExecuteTemplate executes a template defined by name in the template files,
+// and returns its output instead of writing it to disk.
+func (c *Config) ExecuteTemplate(name string, data interface{}) (string, error) {
+	for _, t := range c.templates {
+		if tmpl := t.tmpl.Lookup(name); tmpl != nil {
+			var out bytes.Buffer
+			if err := tmpl.Execute(&out, data); err != nil {
+				return "", err
+			}
+			return out.String(), nil
+		}
+	}
+	return "", fmt.Errorf("template not found: %s", name)
+}
+
 type template struct {
 	tmpl        *gotemplate.Template
 	output      string
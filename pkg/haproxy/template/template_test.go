@@ -274,6 +274,25 @@ func TestWrite(t *testing.T) {
 	}
 }
 
+func TestExecuteTemplate(t *testing.T) {
+	c := setup(t)
+	defer c.teardown()
+	c.newTemplate(`{{ define "name" }}name={{ .Name }}{{ end }}{{ template "name" . }}`, 0)
+	out, err := c.templateConfig.ExecuteTemplate("name", struct{ Name string }{Name: "jack1"})
+	if err != nil {
+		t.Errorf("error executing template: %v", err)
+	}
+	if out != "name=jack1" {
+		t.Errorf("expected 'name=jack1' but was '%s'", out)
+	}
+	if _, err := c.templateConfig.ExecuteTemplate("other", nil); err == nil || err.Error() != "template not found: other" {
+		t.Errorf("expected template not found error, but was: %v", err)
+	}
+	if outputs := c.outputs(0); len(outputs) != 1 || outputs[0] != "" {
+		t.Errorf("expected no output file, but found: %v", outputs)
+	}
+}
+
 func (c *testConfig) newTemplate(content string, rotate int) {
 	cnt := len(c.templateConfig.templates) + 1
 	templateFileName := fmt.Sprintf("h%d.tmpl", cnt)
@@ -208,6 +208,16 @@ func (b *Backend) HasHSTS() bool {
 	return false
 }
 
+// HasMirror ...
+func (b *Backend) HasMirror() bool {
+	for _, path := range b.Paths {
+		if path.Mirror.BackendID != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // HasModsec is a method to verify if a Backend has ModSecurity Enabled
 func (b *Backend) HasModsec() bool {
 	for _, path := range b.Paths {
@@ -318,6 +328,40 @@ func (b *Backend) NeedFrontendACL() bool {
 	return len(b.PathsMaps()) > 1
 }
 
+// HasSamePathsConfig returns true if the configuration built from the paths
+// of both backends is the same, even if the paths themselves differ. This is
+// never the case if a backend needs ACLs, since its configuration refers to
+// every single path.
+func (b *Backend) HasSamePathsConfig(other *Backend) bool {
+	if b.NeedACL() || other.NeedACL() {
+		return false
+	}
+	if b.HasHTTPRequests() != other.HasHTTPRequests() ||
+		b.HasTLSAuth() != other.HasTLSAuth() ||
+		b.HasFrontingProxy() != other.HasFrontingProxy() ||
+		b.HasFrontingUseProto() != other.HasFrontingUseProto() {
+		return false
+	}
+	if b.Cookie.Shared && !slices.Equal(b.Hostnames(), other.Hostnames()) {
+		return false
+	}
+	otherConfigs := other.PathConfigs()
+	for attr, config := range b.PathConfigs() {
+		if !reflect.DeepEqual(config.Items(), otherConfigs[attr].Items()) {
+			return false
+		}
+	}
+	return true
+}
+
+// SharePaths makes b to use the paths of other, as well as the
+// configurations built from them.
+func (b *Backend) SharePaths(other *Backend) {
+	b.Paths = other.Paths
+	b.pathsMaps = other.pathsMaps
+	b.pathsConfigs = other.pathsConfigs
+}
+
 func (b *Backend) PathsMaps() []*BackendPathsMaps {
 	if b.pathsMaps == nil {
 		b.pathsMaps = b.createPathsMaps()
@@ -489,6 +533,31 @@ func (p *Path) Match() MatchType {
 	return p.Link.match
 }
 
+// HasKeyID ...
+func (j AuthJWT) HasKeyID() bool {
+	for _, verifier := range j.Verifiers {
+		if verifier.KeyID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// jwtMaxAudiences is the number of members of an array `aud` claim that are
+// compared with the expected audience, haproxy cannot iterate over json arrays
+const jwtMaxAudiences = 8
+
+// AudienceQueries returns the json paths used to find the expected audience,
+// either as the `aud` string claim or as one of the members of an `aud` array.
+func (j AuthJWT) AudienceQueries() []string {
+	queries := make([]string, 0, jwtMaxAudiences+1)
+	queries = append(queries, "$.aud")
+	for i := range jwtMaxAudiences {
+		queries = append(queries, fmt.Sprintf("$.aud[%d]", i))
+	}
+	return queries
+}
+
 // String ...
 func (b *TCPBackend) String() string {
 	return fmt.Sprintf("%+v", *b)
@@ -163,6 +163,13 @@ func (b *Backends) backendShardChanged(shard int) {
 	b.changedShards[shard] = true
 }
 
+// ChangeAllShards flags all the shards as changed, so all of them are written again
+func (b *Backends) ChangeAllShards() {
+	for i := range b.shards {
+		b.backendShardChanged(i)
+	}
+}
+
 // ChangedShards ...
 func (b *Backends) ChangedShards() []int {
 	changed := []int{}
@@ -26,7 +26,7 @@ import (
 // FindPath ...
 func (h *Host) FindPath(path string, match ...MatchType) (paths []*Path) {
 	for _, p := range h.Paths {
-		if p.Link.path == path && p.Link.headers == nil && p.hasMatch(match) {
+		if p.Link.path == path && p.Link.filter.isEmpty() && p.hasMatch(match) {
 			paths = append(paths, p)
 		}
 	}
@@ -111,7 +111,17 @@ func (h *Host) HasTLSAuth() bool {
 
 // Headers ...
 func (h *Path) Headers() HTTPHeaderMatch {
-	return h.Link.headers
+	return h.Link.filter.headers
+}
+
+// HTTPMethod ...
+func (h *Path) HTTPMethod() string {
+	return h.Link.filter.method
+}
+
+// QueryParams ...
+func (h *Path) QueryParams() HTTPQueryMatch {
+	return h.Link.filter.query
 }
 
 func (h *Path) hasMatch(match []MatchType) bool {
@@ -19,8 +19,8 @@ package types
 import (
 	"container/list"
 	"fmt"
-	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -46,27 +46,51 @@ func (hm *HostsMaps) AddMap(basename string) *HostsMap {
 
 // AddHostnameMapping ...
 func (hm *HostsMap) AddHostnameMapping(hostname, target string) {
-	hm.addHostnameMappingMatch(hostname, target, MatchExact)
+	hm.addHostnameMappingMatch(hostname, false, target, MatchExact)
+}
+
+// AddHostMapping adds the hostname of host, honoring how its wildcard should match.
+func (hm *HostsMap) AddHostMapping(host *Host, target string) {
+	hm.addHostnameMappingMatch(host.Hostname, host.WildcardSuffix, target, MatchExact)
+}
+
+// AddTCPHostMapping adds the hostname of a TCP service, honoring how its wildcard should match.
+func (hm *HostsMap) AddTCPHostMapping(tcpHost *TCPServiceHost, target string) {
+	hm.addHostnameMappingMatch(tcpHost.hostname, tcpHost.WildcardSuffix, target, MatchExact)
 }
 
 // AddHostnameMappingRegex ...
 func (hm *HostsMap) AddHostnameMappingRegex(hostname, target string) {
-	hm.addHostnameMappingMatch(hostname, target, MatchRegex)
+	hm.addHostnameMappingMatch(hostname, false, target, MatchRegex)
 }
 
-func (hm *HostsMap) addHostnameMappingMatch(hostname, target string, match MatchType) {
+func (hm *HostsMap) addHostnameMappingMatch(hostname string, wildcardSuffix bool, target string, match MatchType) {
 	if match != MatchRegex {
 		var hasWildcard bool
-		if hostname, hasWildcard = convertWildcardToRegex(hostname); hasWildcard {
+		if hostname, hasWildcard = convertWildcardToRegex(hostname, wildcardSuffix); hasWildcard {
 			match = MatchRegex
 		}
 	}
-	hm.addTarget(hostname, "", nil, 0, target, match)
+	hm.addTarget(hostname, "", httpFilter{}, 0, target, match)
 }
 
 // AddHostnamePathMapping ...
 func (hm *HostsMap) AddHostnamePathMapping(hostname string, path *Path, target string) {
-	hostname, hasWildcard := convertWildcardToRegex(hostname)
+	hm.addHostnamePathMapping(hostname, false, path, target)
+}
+
+// AddHostPathMapping adds a path of host, honoring how its wildcard hostname should match.
+func (hm *HostsMap) AddHostPathMapping(host *Host, path *Path, target string) {
+	hm.addHostnamePathMapping(host.Hostname, host.WildcardSuffix, path, target)
+}
+
+// AddPathMapping adds path using its own hostname, honoring how the wildcard hostname of its host should match.
+func (hm *HostsMap) AddPathMapping(path *Path, target string) {
+	hm.addHostnamePathMapping(path.Hostname(), path.Host != nil && path.Host.WildcardSuffix, path, target)
+}
+
+func (hm *HostsMap) addHostnamePathMapping(hostname string, wildcardSuffix bool, path *Path, target string) {
+	hostname, hasWildcard := convertWildcardToRegex(hostname, wildcardSuffix)
 	strpath := path.Path()
 	match := path.Match()
 	// TODO paths of a wildcard hostname will always have less precedence
@@ -78,7 +102,7 @@ func (hm *HostsMap) AddHostnamePathMapping(hostname string, path *Path, target s
 	} else if match == MatchRegex {
 		hostname = "^" + regexp.QuoteMeta(hostname) + "$"
 	}
-	hm.addTarget(hostname, strpath, path.Link.headers, path.order, target, match)
+	hm.addTarget(hostname, strpath, path.Link.filter, path.order, target, match)
 }
 
 // AddAliasPathMapping ...
@@ -88,14 +112,19 @@ func (hm *HostsMap) AddAliasPathMapping(alias HostAliasConfig, path *Path, targe
 	}
 	if alias.AliasRegex != "" {
 		pathstr := convertPathToRegex(path)
-		hm.addTarget(alias.AliasRegex, pathstr, path.Link.headers, path.order, target, MatchRegex)
+		hm.addTarget(alias.AliasRegex, pathstr, path.Link.filter, path.order, target, MatchRegex)
 	}
 }
 
-func convertWildcardToRegex(hostname string) (h string, hasWildcard bool) {
+// convertWildcardToRegex converts a `*.domain` hostname to a regex. The wildcard
+// matches a single DNS label, or one or more labels if wildcardSuffix is true.
+func convertWildcardToRegex(hostname string, wildcardSuffix bool) (h string, hasWildcard bool) {
 	if !strings.HasPrefix(hostname, "*.") {
 		return hostname, false
 	}
+	if wildcardSuffix {
+		return "^[^.]+(\\.[^.]+)*" + regexp.QuoteMeta(hostname[1:]) + "$", true
+	}
 	return "^[^.]+" + regexp.QuoteMeta(hostname[1:]) + "$", true
 }
 
@@ -125,7 +154,7 @@ func convertPathToRegex(path *Path) string {
 	panic("unsupported match type")
 }
 
-func (hm *HostsMap) addTarget(hostname, path string, headers []HTTPMatch, order int, target string, match MatchType) {
+func (hm *HostsMap) addTarget(hostname, path string, filter httpFilter, order int, target string, match MatchType) {
 	hostname = strings.ToLower(hostname)
 	if match == MatchBegin {
 		// this is the only match that uses case-insensitive path
@@ -135,7 +164,7 @@ func (hm *HostsMap) addTarget(hostname, path string, headers []HTTPMatch, order
 		hostname: hostname,
 		path:     path,
 		match:    match,
-		headers:  headers,
+		filter:   filter,
 		order:    order,
 		Key:      buildMapKey(match, hostname, path),
 		Value:    target,
@@ -208,12 +237,16 @@ func (hm *HostsMap) rebuildMatchFiles() (matchFiles []*MatchFile) {
 		// priorities should be processed first:
 		// - /sub/dir need to be processed before /sub
 		// - with-filters need to be processed before without-filters
+		// - more specific filters need to be processed before less specific ones
 		sort.Slice(entryList, func(i, j int) bool {
 			e1 := entryList[i]
 			e2 := entryList[j]
-			if e1.headers.equals(e2.headers) {
+			if e1.filter.equals(e2.filter) {
 				return e1.path > e2.path
 			}
+			if e1.filter.precedes(e2.filter) || e2.filter.precedes(e1.filter) {
+				return e1.filter.precedes(e2.filter)
+			}
 			return e1.hasFilter()
 		})
 		if len(entryList) == 1 {
@@ -269,7 +302,7 @@ func (hm *HostsMap) rebuildMatchFiles() (matchFiles []*MatchFile) {
 
 	// Add entries with filters as high priority and create
 	// the final []matchFiles in the correct order.
-	order.PushFrontList(listWithFilters)
+	order.PushFrontList(sortFilterList(listWithFilters))
 	matchFiles = make([]*MatchFile, 0, order.Len())
 	var i int
 	for e := order.Front(); e != nil; e = e.Next() {
@@ -324,7 +357,7 @@ func findOrCreateMatchFile(order *list.List, e1 *HostsMapEntry) *list.Element {
 	if element == nil {
 		matchFile = &hostsMapMatchFile{
 			match:    e1.match,
-			headers:  e1.headers,
+			filter:   e1.filter,
 			priority: true,
 		}
 		element = order.PushBack(matchFile)
@@ -341,13 +374,30 @@ func findMatchFile(order *list.List, e1 *HostsMapEntry) (matchFile *hostsMapMatc
 	}
 	for element = starting; element != nil; element = element.Next() {
 		matchFile = element.Value.(*hostsMapMatchFile)
-		if matchFile.match == e1.match && matchFile.headers.equals(e1.headers) {
+		if matchFile.match == e1.match && matchFile.filter.equals(e1.filter) {
 			return matchFile, element
 		}
 	}
 	return nil, nil
 }
 
+// sortFilterList sorts match files with filters, so more specific
+// filters are processed first despite the hostname they came from.
+func sortFilterList(filterList *list.List) *list.List {
+	matchFiles := make([]*hostsMapMatchFile, 0, filterList.Len())
+	for e := filterList.Front(); e != nil; e = e.Next() {
+		matchFiles = append(matchFiles, e.Value.(*hostsMapMatchFile))
+	}
+	sort.SliceStable(matchFiles, func(i, j int) bool {
+		return matchFiles[i].filter.precedes(matchFiles[j].filter)
+	})
+	sorted := list.New()
+	for _, matchFile := range matchFiles {
+		sorted.PushBack(matchFile)
+	}
+	return sorted
+}
+
 // HasHost ...
 func (hm *HostsMap) HasHost() bool {
 	for _, matchFile := range hm.rawfiles {
@@ -458,9 +508,24 @@ func (m MatchFile) Method() string {
 	return m.matchFile.method()
 }
 
+// HasFilter ...
+func (m MatchFile) HasFilter() bool {
+	return !m.matchFile.filter.isEmpty()
+}
+
+// HTTPMethod ...
+func (m MatchFile) HTTPMethod() string {
+	return m.matchFile.filter.method
+}
+
 // Headers ...
 func (m MatchFile) Headers() HTTPHeaderMatch {
-	return m.matchFile.headers
+	return m.matchFile.filter.headers
+}
+
+// QueryParams ...
+func (m MatchFile) QueryParams() HTTPQueryMatch {
+	return m.matchFile.filter.query
 }
 
 // Values ...
@@ -468,16 +533,43 @@ func (m MatchFile) Values() []*HostsMapEntry {
 	return m.matchFile.entries
 }
 
+// HasSameLayout returns true if both match files are written in the same file
+// and are referenced in the same way by the configuration, despite its entries.
+func (m MatchFile) HasSameLayout(other *MatchFile) bool {
+	return m.filename == other.filename &&
+		m.first == other.first &&
+		m.last == other.last &&
+		m.matchFile.match == other.matchFile.match &&
+		m.matchFile.filter.equals(other.matchFile.filter)
+}
+
 func (he *HostsMapEntry) hasFilter() bool {
-	return he.headers != nil
+	return !he.filter.isEmpty()
 }
 
 func (he *HostsMapEntry) hasSameFilter(other *HostsMapEntry) bool {
-	return he.headers.equals(other.headers)
+	return he.filter.equals(other.filter)
+}
+
+func (f httpFilter) isEmpty() bool {
+	return f.method == "" && len(f.headers) == 0 && len(f.query) == 0
 }
 
-func (h HTTPHeaderMatch) equals(other HTTPHeaderMatch) bool {
-	return reflect.DeepEqual(h, other)
+func (f httpFilter) equals(other httpFilter) bool {
+	return f.method == other.method && slices.Equal(f.headers, other.headers) && slices.Equal(f.query, other.query)
+}
+
+// precedes reports whether f should be evaluated before other. Following Gateway API
+// precedence rules, a method match comes first, then the number of header matches,
+// and finally the number of query param matches.
+func (f httpFilter) precedes(other httpFilter) bool {
+	if (f.method != "") != (other.method != "") {
+		return f.method != ""
+	}
+	if len(f.headers) != len(other.headers) {
+		return len(f.headers) > len(other.headers)
+	}
+	return len(f.query) > len(other.query)
 }
 
 func (he *HostsMapEntry) String() string {
@@ -29,10 +29,11 @@ var matchOrder = []MatchType{MatchExact, MatchPrefix, MatchBegin, MatchRegex}
 
 func TestAddHostnameMapping(t *testing.T) {
 	testCases := []struct {
-		filename string
-		hostname string
-		expmatch MatchType
-		expected string
+		filename       string
+		hostname       string
+		wildcardSuffix bool
+		expmatch       MatchType
+		expected       string
 	}{
 		// 0
 		{
@@ -58,10 +59,28 @@ func TestAddHostnameMapping(t *testing.T) {
 			expmatch: MatchRegex,
 			expected: "^[^.]+\\.example\\.local$",
 		},
+		// 4
+		{
+			hostname:       "*.example.local",
+			wildcardSuffix: true,
+			expmatch:       MatchRegex,
+			expected:       "^[^.]+(\\.[^.]+)*\\.example\\.local$",
+		},
+		// 5
+		{
+			hostname:       "example.local",
+			wildcardSuffix: true,
+			expmatch:       MatchExact,
+			expected:       "example.local",
+		},
 	}
 	for i, test := range testCases {
 		hm := CreateMaps(matchOrder).AddMap(test.filename)
-		hm.AddHostnameMapping(test.hostname, "backend")
+		if test.wildcardSuffix {
+			hm.AddHostMapping(&Host{Hostname: test.hostname, WildcardSuffix: true}, "backend")
+		} else {
+			hm.AddHostnameMapping(test.hostname, "backend")
+		}
 		entries := hm.rawfiles[test.expmatch].entries
 		if len(entries) != 1 {
 			t.Errorf("item %d, invalid match or value: %v", i, hm.rawfiles)
@@ -76,12 +95,13 @@ func TestAddHostnameMapping(t *testing.T) {
 
 func TestAddHostnamePathMapping(t *testing.T) {
 	testCases := []struct {
-		filename string
-		hostname string
-		path     string
-		match    MatchType
-		expmatch MatchType
-		expected string
+		filename       string
+		hostname       string
+		wildcardSuffix bool
+		path           string
+		match          MatchType
+		expmatch       MatchType
+		expected       string
 	}{
 		// 0
 		{
@@ -179,13 +199,26 @@ func TestAddHostnamePathMapping(t *testing.T) {
 			expmatch: MatchRegex,
 			expected: "^[^.]+\\.example\\.local#/path$",
 		},
+		// 12
+		{
+			hostname:       "*.example.local",
+			wildcardSuffix: true,
+			path:           "/path",
+			match:          MatchExact,
+			expmatch:       MatchRegex,
+			expected:       "^[^.]+(\\.[^.]+)*\\.example\\.local#/path$",
+		},
 	}
 	for i, test := range testCases {
 		hm := CreateMaps(matchOrder).AddMap(test.filename)
 		path := &Path{
 			Link: CreatePathLink(test.path, test.match),
 		}
-		hm.AddHostnamePathMapping(test.hostname, path, "backend")
+		if test.wildcardSuffix {
+			hm.AddHostPathMapping(&Host{Hostname: test.hostname, WildcardSuffix: true}, path, "backend")
+		} else {
+			hm.AddHostnamePathMapping(test.hostname, path, "backend")
+		}
 		entries := hm.rawfiles[test.expmatch].entries
 		if len(entries) != 1 {
 			t.Errorf("item %d, invalid match or value: %v", i, hm.rawfiles)
@@ -310,7 +343,9 @@ func TestOverlap(t *testing.T) {
 		hostname string
 		path     string
 		match    MatchType
+		method   string
 		headers  HTTPHeaderMatch
+		query    HTTPQueryMatch
 		target   string
 	}
 	testCases := []struct {
@@ -665,6 +700,44 @@ local1.tld /a0 prefix
 
 hosts__prefix_02.map first:false,lower:false,method:dir headers=['x-user':'myname2',regex:false]
 local1.tld /a2 prefix
+`,
+		},
+		// 18
+		{
+			data: []data{
+				{hostname: "local1.tld", path: "/a0", match: MatchPrefix, query: HTTPQueryMatch{{Name: "version", Value: "2"}}},
+				{hostname: "local1.tld", path: "/a0", match: MatchPrefix, headers: HTTPHeaderMatch{{Name: "x-user", Value: "myname1"}}},
+				{hostname: "local1.tld", path: "/a0", match: MatchPrefix, method: "GET"},
+				{hostname: "local1.tld", path: "/a0", match: MatchPrefix},
+			},
+			expected: `
+hosts__prefix_01.map first:true,lower:false,method:dir httpmethod=GET
+local1.tld /a0 prefix
+
+hosts__prefix_02.map first:false,lower:false,method:dir headers=['x-user':'myname1',regex:false]
+local1.tld /a0 prefix
+
+hosts__prefix_03.map first:false,lower:false,method:dir query=['version':'2',regex:false]
+local1.tld /a0 prefix
+
+hosts__prefix.map first:false,lower:false,method:dir
+local1.tld /a0 prefix
+`,
+		},
+		// 19
+		{
+			data: []data{
+				{hostname: "local1.tld", path: "/a0", match: MatchPrefix, headers: HTTPHeaderMatch{{Name: "x-user", Value: "myname1"}}},
+				{hostname: "local2.tld", path: "/a0", match: MatchPrefix, method: "POST", query: HTTPQueryMatch{{Name: "version", Value: "^v[12]$", Regex: true}}},
+				{hostname: "local2.tld", path: "/a0", match: MatchPrefix, headers: HTTPHeaderMatch{{Name: "x-user", Value: "myname1"}}},
+			},
+			expected: `
+hosts__prefix_01.map first:true,lower:false,method:dir httpmethod=POST query=['version':'^v[12]$',regex:true]
+local2.tld /a0 prefix
+
+hosts__prefix_02.map first:false,lower:false,method:dir headers=['x-user':'myname1',regex:false]
+local1.tld /a0 prefix
+local2.tld /a0 prefix
 `,
 		},
 	}
@@ -674,12 +747,16 @@ local1.tld /a2 prefix
 			if item.path == "" {
 				hm.AddHostnameMapping(item.hostname, item.target)
 			} else {
-				hm.AddHostnamePathMapping(item.hostname, &Path{Link: CreatePathLink(item.path, item.match).WithHeadersMatch(item.headers)}, item.target)
+				link := CreatePathLink(item.path, item.match).WithMethodMatch(item.method).WithHeadersMatch(item.headers).WithQueryMatch(item.query)
+				hm.AddHostnamePathMapping(item.hostname, &Path{Link: link}, item.target)
 			}
 		}
 		var output string
 		for _, m := range hm.MatchFiles() {
 			output += fmt.Sprintf("\n%s first:%t,lower:%t,method:%s", m.Filename(), m.First(), m.Lower(), m.Method())
+			if m.HTTPMethod() != "" {
+				output += " httpmethod=" + m.HTTPMethod()
+			}
 			if m.Headers() != nil {
 				output += " headers=["
 				for _, v := range m.Headers() {
@@ -687,6 +764,13 @@ local1.tld /a2 prefix
 				}
 				output = strings.TrimRight(output, ";") + "]"
 			}
+			if m.QueryParams() != nil {
+				output += " query=["
+				for _, v := range m.QueryParams() {
+					output += fmt.Sprintf("'%s':'%s',regex:%t;", v.Name, v.Value, v.Regex)
+				}
+				output = strings.TrimRight(output, ";") + "]"
+			}
 			for _, v := range m.Values() {
 				output += fmt.Sprintf("\n%s %s %s", v.hostname, v.path, v.match)
 			}
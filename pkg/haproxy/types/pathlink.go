@@ -32,12 +32,21 @@ func CreatePathLink(path string, match MatchType) *PathLink {
 
 func (l *PathLink) updatehash() {
 	hash := l.frontend + "\n" + l.hostname + "\n" + l.path + "\n" + string(l.match)
-	for _, h := range l.headers {
+	if l.filter.method != "" {
+		hash += "\n" + "m:" + l.filter.method
+	}
+	for _, h := range l.filter.headers {
 		hash += "\n" + "h:" + h.Name + ":" + h.Value
 		if h.Regex {
 			hash += "(regex)"
 		}
 	}
+	for _, q := range l.filter.query {
+		hash += "\n" + "q:" + q.Name + ":" + q.Value
+		if q.Regex {
+			hash += "(regex)"
+		}
+	}
 	l.hash = PathLinkHash(hash)
 }
 
@@ -57,7 +66,7 @@ func (l *PathLink) Equals(other *PathLink) bool {
 // IsComposeMatch returns true if the pathLink has composing match,
 // by adding method, header or cookie match.
 func (l *PathLink) IsComposeMatch() bool {
-	return len(l.headers) > 0
+	return !l.filter.isEmpty()
 }
 
 // WithHTTPFront ...
@@ -85,14 +94,35 @@ func (l *PathLink) WithTCPHost(tcphost *TCPServiceHost) *PathLink {
 
 // WithHeadersMatch ...
 func (l *PathLink) WithHeadersMatch(headers HTTPHeaderMatch) *PathLink {
-	l.headers = headers
+	l.filter.headers = headers
 	l.updatehash()
 	return l
 }
 
 // AddHeadersMatch ...
 func (l *PathLink) AddHeadersMatch(headers HTTPHeaderMatch) *PathLink {
-	l.headers = append(l.headers, headers...)
+	l.filter.headers = append(l.filter.headers, headers...)
+	l.updatehash()
+	return l
+}
+
+// WithMethodMatch ...
+func (l *PathLink) WithMethodMatch(method string) *PathLink {
+	l.filter.method = method
+	l.updatehash()
+	return l
+}
+
+// WithQueryMatch ...
+func (l *PathLink) WithQueryMatch(query HTTPQueryMatch) *PathLink {
+	l.filter.query = query
+	l.updatehash()
+	return l
+}
+
+// AddQueryMatch ...
+func (l *PathLink) AddQueryMatch(query HTTPQueryMatch) *PathLink {
+	l.filter.query = append(l.filter.query, query...)
 	l.updatehash()
 	return l
 }
@@ -102,6 +132,16 @@ func (l *PathLink) Hostname() string {
 	return l.hostname
 }
 
+// Path ...
+func (l *PathLink) Path() string {
+	return l.path
+}
+
+// Match ...
+func (l *PathLink) Match() MatchType {
+	return l.match
+}
+
 // IsEmpty ...
 func (l *PathLink) IsEmpty() bool {
 	return l.hostname == "" && l.path == ""
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+)
+
+// NewSnippetSource ...
+func NewSnippetSource(kind, namespace, name, key string, lines []string) *SnippetSource {
+	return &SnippetSource{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Key:       key,
+		Hash:      fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(lines, "\n")))),
+	}
+}
+
+// ID identifies the snippet of a resource. A changed snippet has another
+// ID, so it is not affected by the quarantine of the former one.
+func (s *SnippetSource) ID() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", s.Kind, s.Namespace, s.Name, s.Key, s.Hash)
+}
+
+// String ...
+func (s *SnippetSource) String() string {
+	return fmt.Sprintf("%s '%s/%s' key '%s'", s.Kind, s.Namespace, s.Name, s.Key)
+}
+
+// Add ...
+func (q SnippetQuarantine) Add(source *SnippetSource, reason string) {
+	q[source.ID()] = reason
+}
+
+// Retain removes from the quarantine the snippets whose ID is not in ids.
+func (q SnippetQuarantine) Retain(ids map[string]bool) {
+	for id := range q {
+		if !ids[id] {
+			delete(q, id)
+		}
+	}
+}
+
+// Reason returns the reason the snippet was rejected, and if it is in the quarantine.
+func (q SnippetQuarantine) Reason(source *SnippetSource) (reason string, found bool) {
+	reason, found = q[source.ID()]
+	return reason, found
+}
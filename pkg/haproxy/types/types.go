@@ -59,6 +59,7 @@ type Global struct {
 	SSL                     SSLConfig
 	DNS                     DNSConfig
 	ModSecurity             ModSecurityConfig
+	Mirror                  MirrorConfig
 	Cookie                  CookieConfig
 	DrainSupport            DrainConfig
 	Acme                    Acme
@@ -68,6 +69,7 @@ type Global struct {
 	LoadServerState         bool
 	AdminSocket             string
 	LocalFSPrefix           string
+	HAProxy                 HAProxyVersion
 	External                ExternalConfig
 	Healthz                 HealthzConfig
 	Master                  MasterConfig
@@ -96,6 +98,33 @@ type Global struct {
 	CustomTCP               []string
 }
 
+// HAProxyVersion is the version and the build options of the running haproxy.
+// Features and Services are nil if the build options are unknown.
+type HAProxyVersion struct {
+	Version  string
+	Major    int
+	Minor    int
+	Features []string
+	Services []string
+}
+
+// Feature is a capability of haproxy that depends on its version or build options.
+type Feature string
+
+// ...
+const (
+	FeatureCacheVary          Feature = "cache-vary"
+	FeatureCompressionMinSize Feature = "compression-minsize"
+	FeatureCompressionReq     Feature = "compression-request"
+	FeatureDynServers         Feature = "dynamic-servers"
+	FeatureJWT                Feature = "jwt"
+	FeatureLegacyHTTP         Feature = "legacy-http"
+	FeaturePromex             Feature = "prometheus-exporter"
+	FeatureQUIC               Feature = "quic"
+	FeatureSystemCA           Feature = "system-ca"
+	FeatureUpdateSSLFile      Feature = "update-ssl-file"
+)
+
 // ProcsConfig ...
 type ProcsConfig struct {
 	Nbproc          int
@@ -181,6 +210,22 @@ type ModSecurityConfig struct {
 	UseCoraza bool
 }
 
+// MirrorConfig ...
+type MirrorConfig struct {
+	Endpoints []string
+	Port      int
+	Timeout   MirrorTimeoutConfig
+}
+
+// MirrorTimeoutConfig ...
+type MirrorTimeoutConfig struct {
+	// Backend
+	Connect string
+	Server  string
+	// SPOE
+	Processing string
+}
+
 // CookieConfig ...
 type CookieConfig struct {
 	Key string
@@ -272,6 +317,26 @@ type ModSecurityTimeoutConfig struct {
 
 const HTTPResponseGlobalID = "global"
 
+// SnippetSource is the resource and the configuration key that
+// provided a configuration snippet.
+type SnippetSource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Key       string
+	Hash      string
+}
+
+// SnippetSources ...
+type SnippetSources struct {
+	Early *SnippetSource
+	Late  *SnippetSource
+}
+
+// SnippetQuarantine has the configuration snippets that were rejected by
+// haproxy, and the rejection reason, indexed by the snippet source ID.
+type SnippetQuarantine map[string]string
+
 // HTTPResponses ...
 type HTTPResponses struct {
 	ID      string
@@ -319,6 +384,8 @@ type TCPServiceHost struct {
 	tcpport  *TCPServicePort
 	hostname string
 	Backend  BackendID
+	// WildcardSuffix has the same meaning of Host.WildcardSuffix
+	WildcardSuffix bool
 }
 
 type TCPServiceTLSConfig struct {
@@ -394,7 +461,7 @@ type HostsMapEntry struct {
 	hostname string
 	path     string
 	match    MatchType
-	headers  HTTPHeaderMatch
+	filter   httpFilter
 	order    int
 	_upper   *list.Element
 	_elem    *list.Element
@@ -405,7 +472,7 @@ type HostsMapEntry struct {
 type hostsMapMatchFile struct {
 	entries  []*HostsMapEntry
 	match    MatchType
-	headers  HTTPHeaderMatch
+	filter   httpFilter
 	priority bool
 }
 
@@ -508,6 +575,7 @@ type Frontend struct {
 	IsFrontingUseProto bool
 	RedirectFromCode   int
 	RedirectToCode     int
+	RedirToRules       []*RedirToRule
 	//
 	// Hosts related
 	hosts,
@@ -538,6 +606,9 @@ type Host struct {
 	SSLPassthrough      bool
 	TLS                 HostTLSConfig
 	VarNamespace        bool
+	// WildcardSuffix defines that a `*.domain` Hostname matches one or more
+	// DNS labels before domain, like Gateway API does, instead of just one.
+	WildcardSuffix bool
 }
 
 // MatchType ...
@@ -557,6 +628,9 @@ var DefaultMatchOrder = []MatchType{MatchExact, MatchPrefix, MatchBegin, MatchRe
 // HTTPHeaderMatch ...
 type HTTPHeaderMatch []HTTPMatch
 
+// HTTPQueryMatch ...
+type HTTPQueryMatch []HTTPMatch
+
 // HTTPMatch ...
 type HTTPMatch struct {
 	// Each HTTPMatch uses 16 + len(Name) + len(Value) + 7 (if Regex) bytes.
@@ -584,7 +658,15 @@ type PathLink struct {
 	hostname string
 	path     string
 	match    MatchType
-	headers  HTTPHeaderMatch
+	filter   httpFilter
+}
+
+// httpFilter holds the request conditions, other than hostname
+// and path, that a PathLink needs to match.
+type httpFilter struct {
+	method  string
+	headers HTTPHeaderMatch
+	query   HTTPQueryMatch
 }
 
 // HostAliasConfig ...
@@ -674,9 +756,11 @@ type Backend struct {
 	AllowedIPTCP        AccessConfig
 	BalanceAlgorithm    string
 	BlueGreen           BlueGreenConfig
+	Compression         Compression
 	Cookie              Cookie
 	CustomConfigEarly   []string
 	CustomConfigLate    []string
+	CustomConfigSources SnippetSources
 	CustomHTTPResponses HTTPResponses
 	DeniedIPTCP         AccessConfig
 	Dynamic             DynBackendConfig
@@ -687,6 +771,7 @@ type Backend struct {
 	ModeTCP             bool
 	PeersTable          string
 	Resolver            string
+	Retry               BackendRetry
 	Server              ServerConfig
 	Timeout             BackendTimeoutConfig
 	TLS                 BackendTLSConfig
@@ -749,16 +834,41 @@ type Path struct {
 	AuthHTTP      AuthHTTP
 	AuthExtFront  AuthExternal
 	AuthExtBack   AuthExternal
+	AuthJWT       AuthJWT
+	Cache         Cache
 	Cors          Cors
 	DeniedIPHTTP  AccessConfig
 	HSTS          HSTS
 	MaxBodySize   int64
+	Mirror        Mirror
 	RedirTo       string
+	RedirToCode   int
+	RedirToFormat bool
+	ReqHeaders    HTTPHeaderModifier
+	ResHeaders    HTTPHeaderModifier
+	RewriteFull   bool
 	RewriteURL    string
 	SSLRedirect   bool
 	WAF           WAF
 }
 
+// RedirToRule is the redirect of paths whose RedirTo cannot be used as the value of
+// the redirect-to map: RedirTo is a log-format string, RedirToCode is declared, or the
+// path is declared in the default host.
+type RedirToRule struct {
+	ID          string
+	Location    string
+	Code        int
+	DefaultHost bool
+}
+
+// HTTPHeaderModifier ...
+type HTTPHeaderModifier struct {
+	Add    []HTTPHeader
+	Set    []HTTPHeader
+	Remove []string
+}
+
 // BackendHeader ...
 type BackendHeader struct {
 	Name  string
@@ -790,6 +900,14 @@ type HealthCheck struct {
 	URI       string
 }
 
+// Compression ...
+type Compression struct {
+	Algos     []string
+	Direction string
+	MinSize   int64
+	Types     []string
+}
+
 // BackendLimit ...
 type BackendLimit struct {
 	Connections int
@@ -826,6 +944,13 @@ type ServerConfig struct {
 	VerifyHost    string
 }
 
+// BackendRetry ...
+type BackendRetry struct {
+	Redispatch string // empty, "true" or "false"
+	Retries    string
+	RetryOn    []string
+}
+
 // BackendTimeoutConfig ...
 type BackendTimeoutConfig struct {
 	Connect     string
@@ -876,6 +1001,30 @@ type AuthExternal struct {
 	RedirectOnFail  string
 }
 
+// AuthJWT ...
+type AuthJWT struct {
+	AlwaysDeny bool
+	Audience   string
+	Headers    []AuthJWTHeader
+	Issuer     string
+	Verifiers  []AuthJWTVerifier
+}
+
+// AuthJWTHeader is a request header whose value is copied from a claim of the token
+type AuthJWTHeader struct {
+	Claim string
+	Name  string
+}
+
+// AuthJWTVerifier is a signature algorithm and the public key used to verify it,
+// optionally restricted to tokens whose `kid` header matches KeyID
+type AuthJWTVerifier struct {
+	Algorithm string
+	KeyFile   string
+	KeyHash   string
+	KeyID     string
+}
+
 // AuthHTTP ...
 type AuthHTTP struct {
 	UserlistName string
@@ -895,6 +1044,15 @@ type Cors struct {
 	MaxAge           int
 }
 
+// Cache ...
+type Cache struct {
+	Enabled       bool
+	MaxAge        int
+	MaxObjectSize int64
+	ProcessVary   bool
+	TotalMaxSize  int // megabytes
+}
+
 // HSTS ...
 type HSTS struct {
 	Enabled    bool
@@ -903,6 +1061,12 @@ type HSTS struct {
 	Preload    bool
 }
 
+// Mirror ...
+type Mirror struct {
+	BackendID string
+	Percent   int
+}
+
 // WAF Defines the WAF Config structure for the Backend
 type WAF struct {
 	// Mode defines On or DetectionOnly
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"slices"
+)
+
+type capability struct {
+	// min and max are the major and minor haproxy versions that support the feature,
+	// an empty one means no limit.
+	min, max [2]int
+	// buildFeature and service are the name of an enabled feature, or an available
+	// service, that haproxy should be built with.
+	buildFeature string
+	service      string
+}
+
+// capabilities is the table of features supported by haproxy, by version and build options
+var capabilities = map[Feature]capability{
+	// process-vary keyword of the cache section
+	FeatureCacheVary: {min: [2]int{2, 4}},
+	// minsize-req and minsize-res keywords
+	FeatureCompressionMinSize: {min: [2]int{3, 2}},
+	// direction, algo-req and type-req keywords
+	FeatureCompressionReq: {min: [2]int{2, 8}},
+	// add and del server are experimental on 2.4, stable since 2.5
+	FeatureDynServers: {min: [2]int{2, 5}},
+	// jwt_verify, jwt_header_query and jwt_payload_query converters, and http_auth_bearer fetch
+	FeatureJWT: {min: [2]int{2, 5}},
+	// htx is the only supported http mode since 2.1
+	FeatureLegacyHTTP: {max: [2]int{2, 0}},
+	FeaturePromex:     {service: "prometheus-exporter"},
+	FeatureQUIC:       {min: [2]int{2, 6}, buildFeature: "QUIC"},
+	// @system-ca as the ca-file of a server, to load the CA certificates of the system
+	FeatureSystemCA: {min: [2]int{2, 6}},
+	// set and commit ssl ca-file and crl-file commands of the runtime API
+	FeatureUpdateSSLFile: {min: [2]int{2, 5}},
+}
+
+// Known ...
+func (v HAProxyVersion) Known() bool {
+	return v.Major > 0
+}
+
+func (v HAProxyVersion) compare(version [2]int) int {
+	return slices.Compare([]int{v.Major, v.Minor}, version[:])
+}
+
+// Supports checks if the running haproxy supports a feature. An unknown version
+// is handled as the oldest one: features that need a minimum version are not
+// supported, and features removed on newer versions are still supported. Build
+// options are only checked if known.
+func (v HAProxyVersion) Supports(feature Feature) bool {
+	c, found := capabilities[feature]
+	if !found {
+		return false
+	}
+	if !v.Known() {
+		return c.min == [2]int{}
+	}
+	if c.min != [2]int{} && v.compare(c.min) < 0 {
+		return false
+	}
+	if c.max != [2]int{} && v.compare(c.max) > 0 {
+		return false
+	}
+	if c.buildFeature != "" && v.Features != nil && !slices.Contains(v.Features, c.buildFeature) {
+		return false
+	}
+	if c.service != "" && v.Services != nil && !slices.Contains(v.Services, c.service) {
+		return false
+	}
+	return true
+}
+
+// Capabilities lists all the features, and if they are supported.
+func (v HAProxyVersion) Capabilities() map[Feature]bool {
+	features := make(map[Feature]bool, len(capabilities))
+	for feature := range capabilities {
+		features[feature] = v.Supports(feature)
+	}
+	return features
+}
+
+// String ...
+func (v HAProxyVersion) String() string {
+	if v.Version == "" {
+		return "<unknown>"
+	}
+	return v.Version
+}
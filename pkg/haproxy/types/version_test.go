@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	testCases := []struct {
+		version  HAProxyVersion
+		expected map[Feature]bool
+	}{
+		// 0 - unknown version
+		{
+			version: HAProxyVersion{},
+			expected: map[Feature]bool{
+				FeatureCacheVary:          false,
+				FeatureCompressionMinSize: false,
+				FeatureCompressionReq:     false,
+				FeatureDynServers:         false,
+				FeatureJWT:                false,
+				FeatureLegacyHTTP:         true,
+				FeaturePromex:             true,
+				FeatureQUIC:               false,
+				FeatureSystemCA:           false,
+				FeatureUpdateSSLFile:      false,
+			},
+		},
+		// 1
+		{
+			version: HAProxyVersion{Major: 2, Minor: 0, Features: []string{"OPENSSL"}, Services: []string{"prometheus-exporter"}},
+			expected: map[Feature]bool{
+				FeatureCacheVary:          false,
+				FeatureCompressionMinSize: false,
+				FeatureCompressionReq:     false,
+				FeatureDynServers:         false,
+				FeatureJWT:                false,
+				FeatureLegacyHTTP:         true,
+				FeaturePromex:             true,
+				FeatureQUIC:               false,
+				FeatureSystemCA:           false,
+				FeatureUpdateSSLFile:      false,
+			},
+		},
+		// 2
+		{
+			version: HAProxyVersion{Major: 2, Minor: 4, Features: []string{"OPENSSL"}, Services: []string{}},
+			expected: map[Feature]bool{
+				FeatureCacheVary:          true,
+				FeatureCompressionMinSize: false,
+				FeatureCompressionReq:     false,
+				FeatureDynServers:         false,
+				FeatureJWT:                false,
+				FeatureLegacyHTTP:         false,
+				FeaturePromex:             false,
+				FeatureQUIC:               false,
+				FeatureSystemCA:           false,
+				FeatureUpdateSSLFile:      false,
+			},
+		},
+		// 3 - build options are unknown
+		{
+			version: HAProxyVersion{Major: 2, Minor: 6},
+			expected: map[Feature]bool{
+				FeatureCacheVary:          true,
+				FeatureCompressionMinSize: false,
+				FeatureCompressionReq:     false,
+				FeatureDynServers:         true,
+				FeatureJWT:                true,
+				FeatureLegacyHTTP:         false,
+				FeaturePromex:             true,
+				FeatureQUIC:               true,
+				FeatureSystemCA:           true,
+				FeatureUpdateSSLFile:      true,
+			},
+		},
+		// 4
+		{
+			version: HAProxyVersion{Major: 2, Minor: 8, Features: []string{"OPENSSL", "PROMEX"}, Services: []string{"prometheus-exporter"}},
+			expected: map[Feature]bool{
+				FeatureCacheVary:          true,
+				FeatureCompressionMinSize: false,
+				FeatureCompressionReq:     true,
+				FeatureDynServers:         true,
+				FeatureJWT:                true,
+				FeatureLegacyHTTP:         false,
+				FeaturePromex:             true,
+				FeatureQUIC:               false,
+				FeatureSystemCA:           true,
+				FeatureUpdateSSLFile:      true,
+			},
+		},
+		// 5
+		{
+			version: HAProxyVersion{Major: 3, Minor: 0, Features: []string{"OPENSSL", "QUIC"}, Services: []string{"prometheus-exporter"}},
+			expected: map[Feature]bool{
+				FeatureCacheVary:          true,
+				FeatureCompressionMinSize: false,
+				FeatureCompressionReq:     true,
+				FeatureDynServers:         true,
+				FeatureJWT:                true,
+				FeatureLegacyHTTP:         false,
+				FeaturePromex:             true,
+				FeatureQUIC:               true,
+				FeatureSystemCA:           true,
+				FeatureUpdateSSLFile:      true,
+			},
+		},
+		// 6
+		{
+			version: HAProxyVersion{Major: 3, Minor: 2, Features: []string{"OPENSSL"}, Services: []string{}},
+			expected: map[Feature]bool{
+				FeatureCacheVary:          true,
+				FeatureCompressionMinSize: true,
+				FeatureCompressionReq:     true,
+				FeatureDynServers:         true,
+				FeatureJWT:                true,
+				FeatureLegacyHTTP:         false,
+				FeaturePromex:             false,
+				FeatureQUIC:               false,
+				FeatureSystemCA:           true,
+				FeatureUpdateSSLFile:      true,
+			},
+		},
+	}
+	for i, test := range testCases {
+		actual := test.version.Capabilities()
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("capabilities differ on %d - expected: %v, actual: %v", i, test.expected, actual)
+		}
+	}
+	if (HAProxyVersion{Major: 3}).Supports("unknown-feature") {
+		t.Errorf("unknown feature should not be supported")
+	}
+}
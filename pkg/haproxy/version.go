@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/socket"
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+// HAProxyVersion returns the version and build options of haproxy. The version is
+// read on the first successful attempt and reused on the next calls. An empty
+// version is returned if it cannot be read, eg an external haproxy that is not
+// running yet.
+func (i *instance) HAProxyVersion() hatypes.HAProxyVersion {
+	if i.options.fake {
+		return hatypes.HAProxyVersion{}
+	}
+	i.versionMutex.Lock()
+	defer i.versionMutex.Unlock()
+	if i.version == nil {
+		version, err := i.detectVersion()
+		if err != nil {
+			i.logger.InfoV(2, "cannot detect haproxy version: %v", err)
+			return hatypes.HAProxyVersion{}
+		}
+		i.logger.Info("detected haproxy version %s, capabilities: %v", version, version.Capabilities())
+		i.version = &version
+	}
+	return *i.version
+}
+
+func (i *instance) detectVersion() (hatypes.HAProxyVersion, error) {
+	if i.options.IsExternal {
+		// build options are not available via the runtime API. A dedicated socket
+		// is used because the version can be read out of the model lock.
+		sock := socket.NewSocket(i.options.StopCtx, i.options.AdminSocket, false)
+		info, err := socket.NewClient(sock, nil).ShowInfo()
+		if err != nil {
+			return hatypes.HAProxyVersion{}, err
+		}
+		return newHAProxyVersion(info.Version)
+	}
+	// TODO Move all magic strings to a single place
+	out, err := exec.Command("haproxy", "-vv").Output()
+	if err != nil {
+		return hatypes.HAProxyVersion{}, fmt.Errorf("error running haproxy -vv: %w", err)
+	}
+	return parseVersionOutput(string(out))
+}
+
+func newHAProxyVersion(version string) (hatypes.HAProxyVersion, error) {
+	major, minor, err := socket.ParseVersion(version)
+	if err != nil {
+		return hatypes.HAProxyVersion{}, err
+	}
+	return hatypes.HAProxyVersion{
+		Version: version,
+		Major:   major,
+		Minor:   minor,
+	}, nil
+}
+
+// versionOutputRegex matches the first line of `haproxy -vv`, eg:
+// HAProxy version 2.8.10-ec17bb0 2024/06/14 - https://haproxy.org/
+var versionOutputRegex = regexp.MustCompile(`^HA-?Proxy version ([^ ]+)`)
+
+// parseVersionOutput reads the version, the enabled features and the available
+// services from the output of `haproxy -vv`.
+func parseVersionOutput(out string) (hatypes.HAProxyVersion, error) {
+	lines := strings.Split(out, "\n")
+	match := versionOutputRegex.FindStringSubmatch(lines[0])
+	if match == nil {
+		return hatypes.HAProxyVersion{}, fmt.Errorf("version not found in the haproxy -vv output")
+	}
+	version, err := newHAProxyVersion(match[1])
+	if err != nil {
+		return version, err
+	}
+	for n, line := range lines {
+		if features, found := strings.CutPrefix(line, "Feature list :"); found {
+			version.Features = []string{}
+			for _, feature := range strings.Fields(features) {
+				if name, enabled := strings.CutPrefix(feature, "+"); enabled {
+					version.Features = append(version.Features, name)
+				}
+			}
+		} else if services, found := strings.CutPrefix(line, "Available services :"); found {
+			version.Services = []string{}
+			if services = strings.TrimSpace(services); services == "" {
+				// up to 2.3, one service per line, eg `\tprometheus-exporter`
+				for _, next := range lines[n+1:] {
+					if next == "" || (next[0] != ' ' && next[0] != '\t') {
+						break
+					}
+					version.Services = append(version.Services, strings.TrimSpace(next))
+				}
+			} else if services != "none" {
+				version.Services = strings.Fields(services)
+			}
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The HAProxy Ingress Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"reflect"
+	"testing"
+
+	hatypes "github.com/jcmoraisjr/haproxy-ingress/pkg/haproxy/types"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	testCases := []struct {
+		output   string
+		expected hatypes.HAProxyVersion
+		expError bool
+	}{
+		// 0 - haproxy 2.0
+		{
+			output: `HA-Proxy version 2.0.33-3bd4a7b 2023/06/15 - https://haproxy.org/
+Build options :
+  TARGET  = linux-glibc
+  CPU     = generic
+  CC      = gcc
+  OPTIONS = USE_PCRE2=1 USE_PCRE2_JIT=1 USE_GETADDRINFO=1 USE_OPENSSL=1 USE_LUA=1 USE_ZLIB=1
+
+Feature list : +EPOLL -KQUEUE -MY_EPOLL -MY_SPLICE +NETFILTER -PCRE -PCRE_JIT +PCRE2 +PCRE2_JIT +POLL -PRIVATE_CACHE +THREAD -PTHREAD_PSHARED +REGPARM -STATIC_PCRE -STATIC_PCRE2 +TPROXY +LINUX_TPROXY +LINUX_SPLICE +LIBCRYPT +CRYPT_H -VSYSCALL +GETADDRINFO +OPENSSL +LUA +FUTEX +ACCEPT4 -MY_ACCEPT4 +ZLIB -SLZ +CPU_AFFINITY +TFO +NS +DL +RT -DEVICEATLAS -51DEGREES -WURFL -SYSTEMD -OBSOLETE_LINKER +PRCTL +THREAD_DUMP -EVPORTS
+
+Default settings :
+  bufsize = 16384, maxrewrite = 1024, maxpollevents = 200
+
+Available polling systems :
+      epoll : pref=300,  test result OK
+       poll : pref=200,  test result OK
+     select : pref=150,  test result OK
+Total: 3 (3 usable), will use epoll.
+
+Available services :
+	prometheus-exporter
+
+Available filters :
+	[SPOE] spoe
+	[COMP] compression
+	[CACHE] cache
+	[TRACE] trace
+`,
+			expected: hatypes.HAProxyVersion{
+				Version:  "2.0.33-3bd4a7b",
+				Major:    2,
+				Minor:    0,
+				Features: []string{"EPOLL", "NETFILTER", "PCRE2", "PCRE2_JIT", "POLL", "THREAD", "REGPARM", "TPROXY", "LINUX_TPROXY", "LINUX_SPLICE", "LIBCRYPT", "CRYPT_H", "GETADDRINFO", "OPENSSL", "LUA", "FUTEX", "ACCEPT4", "ZLIB", "CPU_AFFINITY", "TFO", "NS", "DL", "RT", "PRCTL", "THREAD_DUMP"},
+				Services: []string{"prometheus-exporter"},
+			},
+		},
+		// 1 - haproxy 2.8
+		{
+			output: `HAProxy version 2.8.10-ec17bb0 2024/06/14 - https://haproxy.org/
+Status: long-term supported branch - will stop receiving fixes around Q2 2028.
+Known bugs: http://www.haproxy.org/bugs/bugs-2.8.10.html
+Running on: Linux 6.6.32 #1 SMP x86_64
+Build options :
+  TARGET  = linux-musl
+  CPU     = generic
+  CC      = cc
+
+Feature list : -51DEGREES +ACCEPT4 -BACKTRACE -CLOSEFROM +CPU_AFFINITY +CRYPT_H -DEVICEATLAS +DL -ENGINE +EPOLL -EVPORTS +GETADDRINFO -KQUEUE -LIBATOMIC +LIBCRYPT +LINUX_SPLICE +LINUX_TPROXY +LUA +MATH -MEMORY_PROFILING +NETFILTER +NS -OBSOLETE_LINKER +OPENSSL -OPENSSL_WOLFSSL -OT -PCRE +PCRE2 +PCRE2_JIT -PCRE_JIT +POLL +PRCTL -PROCCTL +PROMEX -PTHREAD_EMULATION -QUIC -QUIC_OPENSSL_COMPAT +RT +SHM_OPEN -SLZ +SSL -STATIC_PCRE -STATIC_PCRE2 -SYSTEMD +TFO +THREAD +THREAD_DUMP +TPROXY -WURFL +ZLIB
+
+Default settings :
+  bufsize = 16384, maxrewrite = 1024, maxpollevents = 200
+
+Built with multi-threading support (MAX_TGROUPS=16, MAX_THREADS=256, default=8).
+
+Available services : prometheus-exporter
+Available filters :
+	[BWLIM] bwlim-in
+	[BWLIM] bwlim-out
+	[CACHE] cache
+	[COMP] compression
+	[FCGI] fcgi-app
+	[SPOE] spoe
+	[TRACE] trace
+`,
+			expected: hatypes.HAProxyVersion{
+				Version:  "2.8.10-ec17bb0",
+				Major:    2,
+				Minor:    8,
+				Features: []string{"ACCEPT4", "CPU_AFFINITY", "CRYPT_H", "DL", "EPOLL", "GETADDRINFO", "LIBCRYPT", "LINUX_SPLICE", "LINUX_TPROXY", "LUA", "MATH", "NETFILTER", "NS", "OPENSSL", "PCRE2", "PCRE2_JIT", "POLL", "PRCTL", "PROMEX", "RT", "SHM_OPEN", "SSL", "TFO", "THREAD", "THREAD_DUMP", "TPROXY", "ZLIB"},
+				Services: []string{"prometheus-exporter"},
+			},
+		},
+		// 2 - haproxy 3.0 without services
+		{
+			output: `HAProxy version 3.0.5-8e879a5 2024/09/19 - https://haproxy.org/
+Feature list : +EPOLL +OPENSSL +QUIC
+Available services : none
+`,
+			expected: hatypes.HAProxyVersion{
+				Version:  "3.0.5-8e879a5",
+				Major:    3,
+				Minor:    0,
+				Features: []string{"EPOLL", "OPENSSL", "QUIC"},
+				Services: []string{},
+			},
+		},
+		// 3
+		{
+			output:   "haproxy: command not found\n",
+			expError: true,
+		},
+	}
+	for i, test := range testCases {
+		actual, err := parseVersionOutput(test.output)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("version differs on %d - expected: %+v, actual: %+v", i, test.expected, actual)
+		}
+		if (err != nil) != test.expError {
+			t.Errorf("error differs on %d - expected: %v, actual: %v", i, test.expError, err)
+		}
+	}
+}
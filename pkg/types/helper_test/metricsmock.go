@@ -44,6 +44,10 @@ func (m *MetricsMock) HAProxySetServerResponseTime(duration time.Duration) {
 func (m *MetricsMock) HAProxySetSSLCertResponseTime(duration time.Duration) {
 }
 
+// HAProxySetMapResponseTime ...
+func (m *MetricsMock) HAProxySetMapResponseTime(duration time.Duration) {
+}
+
 // ControllerProcTime ...
 func (m *MetricsMock) ControllerProcTime(task string, duration time.Duration) {
 
@@ -61,10 +65,18 @@ func (m *MetricsMock) IncUpdateNoop() {
 func (m *MetricsMock) IncUpdateDynamic() {
 }
 
+// IncUpdatePartial ...
+func (m *MetricsMock) IncUpdatePartial() {
+}
+
 // IncUpdateFull ...
 func (m *MetricsMock) IncUpdateFull() {
 }
 
+// IncUpdateRollback ...
+func (m *MetricsMock) IncUpdateRollback() {
+}
+
 // UpdateSuccessful ...
 func (m *MetricsMock) UpdateSuccessful(success bool) {
 }
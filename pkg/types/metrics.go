@@ -25,11 +25,14 @@ type Metrics interface {
 	HAProxyShowInfoResponseTime(duration time.Duration)
 	HAProxySetServerResponseTime(duration time.Duration)
 	HAProxySetSSLCertResponseTime(duration time.Duration)
+	HAProxySetMapResponseTime(duration time.Duration)
 	ControllerProcTime(task string, duration time.Duration)
 	AddIdleFactor(idle int)
 	IncUpdateNoop()
 	IncUpdateDynamic()
+	IncUpdatePartial()
 	IncUpdateFull()
+	IncUpdateRollback()
 	UpdateSuccessful(success bool)
 	SetCertExpireDate(domain, cn string, notAfter *time.Time)
 	ClearCertExpire()